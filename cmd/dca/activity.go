@@ -1,11 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/ulfschnabel/dca/internal/config"
 	"github.com/ulfschnabel/dca/internal/discord"
 	"github.com/ulfschnabel/dca/internal/output"
-	"fmt"
 )
 
 var activityCmd = &cobra.Command{
@@ -27,12 +27,18 @@ func init() {
 
 	activityRecentCmd.Flags().Int("limit", 15, "Total messages to show")
 	activityRecentCmd.Flags().String("type", "all", "Filter by type: all, dm, server")
+	addTimeWindowFlags(activityRecentCmd)
+	addRenderFlag(activityRecentCmd)
 }
 
 func runActivityRecent(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
 	limit, _ := cmd.Flags().GetInt("limit")
 	filterType, _ := cmd.Flags().GetString("type")
+	since, until, err := timeWindow(cmd)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -51,18 +57,30 @@ func runActivityRecent(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
 	// Get recent activity
-	activity, err := client.GetRecentActivity(limit, filterType)
+	activity, err := client.GetRecentActivity(discord.ActivityOptions{
+		Limit: limit,
+		Type:  filterType,
+		Since: since,
+		Until: until,
+	})
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 
+	msgs := make([]*discord.Message, len(activity))
+	for i, m := range activity {
+		msgs[i] = &m.Message
+	}
+	renderMessages(cmd, client, msgs...)
+	compactMessages(cmd, msgs...)
+
 	return output.PrintSuccess(map[string]interface{}{
 		"activity": activity,
 		"count":    len(activity),
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+func TestActivityRecent(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "activity", "recent", "--limit", "3")
+
+	var data struct {
+		Activity []*discord.ActivityMessage `json:"activity"`
+		Count    int                        `json:"count"`
+	}
+	resp.decode(t, &data)
+	if data.Count != 3 {
+		t.Fatalf("expected 3 messages, got %d", data.Count)
+	}
+	for i := 1; i < len(data.Activity); i++ {
+		if data.Activity[i-1].Timestamp < data.Activity[i].Timestamp {
+			t.Errorf("activity not sorted newest first: %s before %s", data.Activity[i-1].Timestamp, data.Activity[i].Timestamp)
+		}
+	}
+
+	newest := data.Activity[0]
+	if newest.Source != "dm" || newest.DMUser == nil || newest.DMUser.Username != "alice" {
+		t.Errorf("expected newest activity to be the DM with alice, got %+v", newest)
+	}
+	if second := data.Activity[1]; second.Source != "server" || second.ServerName != "Test Guild" || second.ChannelName != "general" {
+		t.Errorf("expected a server message from #general, got %+v", second)
+	}
+	for _, m := range data.Activity {
+		if m.URL == "" || (m.Source == "server" && m.GuildID != m.ServerID) {
+			t.Errorf("expected URL and guild ID on %+v", m)
+		}
+	}
+}
+
+func TestActivityRecentKeepsMessageType(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+
+	var reply discord.Message
+	env.mustRun(t, "message", "reply", fake.ChannelGeneral, target.ID, "on it").decode(t, &reply)
+
+	var data struct {
+		Activity []*discord.ActivityMessage `json:"activity"`
+	}
+	env.mustRun(t, "activity", "recent", "--type", "server").decode(t, &data)
+	for _, m := range data.Activity {
+		if m.ID == reply.ID {
+			if m.Type != "reply" || m.Source != "server" {
+				t.Errorf("expected a server reply, got type %q and source %q", m.Type, m.Source)
+			}
+			return
+		}
+	}
+	t.Fatalf("reply %s missing from activity: %+v", reply.ID, data.Activity)
+}
+
+func TestActivityRecentTypeFilter(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, typ := range []string{"dm", "server"} {
+		resp := env.mustRun(t, "activity", "recent", "--type", typ)
+
+		var data struct {
+			Activity []*discord.ActivityMessage `json:"activity"`
+		}
+		resp.decode(t, &data)
+		if len(data.Activity) == 0 {
+			t.Fatalf("--type %s: expected activity", typ)
+		}
+		for _, m := range data.Activity {
+			if m.Source != typ {
+				t.Errorf("--type %s: got a %s message", typ, m.Source)
+			}
+		}
+	}
+
+	resp := env.mustRun(t, "activity", "recent", "--type", "server")
+	var data struct {
+		Activity []*discord.ActivityMessage `json:"activity"`
+	}
+	resp.decode(t, &data)
+	for _, m := range data.Activity {
+		if m.ServerID != fake.GuildID {
+			t.Errorf("expected server %s, got %s", fake.GuildID, m.ServerID)
+		}
+	}
+}
+
+func TestActivityRecentSince(t *testing.T) {
+	env := newTestEnv(t)
+	env.srv.AddMessage(&discordgo.Message{
+		ChannelID: fake.ChannelGeneral,
+		Author:    env.srv.Me(),
+		Content:   "fresh news",
+		Timestamp: time.Now().Add(-10 * time.Minute),
+	})
+
+	resp := env.mustRun(t, "activity", "recent", "--since", "1h")
+
+	var data struct {
+		Activity []*discord.ActivityMessage `json:"activity"`
+	}
+	resp.decode(t, &data)
+	if len(data.Activity) != 1 || data.Activity[0].Content != "fresh news" {
+		t.Fatalf("expected only the message from the last hour, got %+v", data.Activity)
+	}
+
+	resp = env.mustRun(t, "activity", "recent", "--type", "dm", "--until", fake.SeedTime.Add(6*time.Minute).Format(time.RFC3339))
+	data.Activity = nil
+	resp.decode(t, &data)
+	if len(data.Activity) != 1 || data.Activity[0].Content != "hey, are you around?" {
+		t.Fatalf("expected only the DM before the cutoff, got %+v", data.Activity)
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/output"
+)
+
+var attachmentsCmd = &cobra.Command{
+	Use:   "attachments",
+	Short: "Attachment operations",
+	Long:  "List and download the files attached to a message",
+}
+
+var attachmentsListCmd = &cobra.Command{
+	Use:   "list (<message-link> | <channel> <message-id>)",
+	Short: "List a message's attachments",
+	Long:  "List the files attached to a message, optionally inlining small text files with --inline-text",
+	Args:  messageArgs(0, 0),
+	RunE:  runAttachmentsList,
+}
+
+var attachmentsDownloadCmd = &cobra.Command{
+	Use:   "download (<message-link> | <channel> <message-id>)",
+	Short: "Download a message's attachments",
+	Long:  "Save the files attached to a message to a directory, verifying each file's size",
+	Args:  messageArgs(0, 0),
+	RunE:  runAttachmentsDownload,
+}
+
+func init() {
+	rootCmd.AddCommand(attachmentsCmd)
+	attachmentsCmd.AddCommand(attachmentsListCmd)
+	attachmentsCmd.AddCommand(attachmentsDownloadCmd)
+
+	for _, cmd := range []*cobra.Command{attachmentsListCmd, attachmentsDownloadCmd} {
+		cmd.Flags().Bool("inline-text", false, "Include the content of small text attachments (txt, log, json, source code) in the output")
+		cmd.Flags().Int("max-inline-bytes", 64*1024, "Total size budget for --inline-text")
+	}
+	attachmentsDownloadCmd.Flags().String("dir", ".", "Directory to save files to (created if missing)")
+	attachmentsDownloadCmd.Flags().Bool("overwrite", false, "Replace files that already exist")
+}
+
+// attachmentResult is an attachment in list and download output. Text holds
+// the inlined content; TextOmitted explains why a text file was not inlined.
+type attachmentResult struct {
+	*discord.Attachment
+	Path        string `json:"path,omitempty"`
+	Text        string `json:"text,omitempty"`
+	TextOmitted string `json:"text_omitted,omitempty"`
+}
+
+// textExtensions are file types inlined by --inline-text regardless of the
+// content type Discord reports
+var textExtensions = map[string]bool{
+	".txt": true, ".log": true, ".md": true, ".json": true, ".jsonl": true,
+	".yaml": true, ".yml": true, ".toml": true, ".ini": true, ".cfg": true,
+	".conf": true, ".env": true, ".xml": true, ".csv": true, ".tsv": true,
+	".html": true, ".css": true, ".sql": true, ".diff": true, ".patch": true,
+	".go": true, ".py": true, ".js": true, ".ts": true, ".jsx": true,
+	".tsx": true, ".rs": true, ".java": true, ".kt": true, ".c": true,
+	".h": true, ".cpp": true, ".hpp": true, ".cs": true, ".rb": true,
+	".php": true, ".swift": true, ".sh": true, ".bash": true, ".ps1": true,
+	".lua": true,
+}
+
+// isTextAttachment reports whether an attachment looks like text worth
+// inlining
+func isTextAttachment(a *discord.Attachment) bool {
+	if textExtensions[strings.ToLower(filepath.Ext(a.Filename))] {
+		return true
+	}
+	ct, _, _ := strings.Cut(a.ContentType, ";")
+	switch {
+	case strings.HasPrefix(ct, "text/"):
+		return true
+	case ct == "application/json", ct == "application/xml", ct == "application/javascript",
+		ct == "application/x-yaml", ct == "application/x-sh":
+		return true
+	}
+	return false
+}
+
+// inliner tracks the --inline-text budget across a message's attachments
+type inliner struct {
+	enabled bool
+	budget  int
+}
+
+func newInliner(cmd *cobra.Command) *inliner {
+	enabled, _ := cmd.Flags().GetBool("inline-text")
+	budget, _ := cmd.Flags().GetInt("max-inline-bytes")
+	return &inliner{enabled: enabled, budget: budget}
+}
+
+// wants reports whether r should be inlined, and records why not if it is
+// a text file that does not fit the remaining budget
+func (in *inliner) wants(r *attachmentResult) bool {
+	if !in.enabled || !isTextAttachment(r.Attachment) {
+		return false
+	}
+	if r.Size > in.budget {
+		r.TextOmitted = "exceeds inline budget"
+		return false
+	}
+	return true
+}
+
+// inline stores data as r's text and charges it to the budget
+func (in *inliner) inline(r *attachmentResult, data []byte) {
+	if !utf8.Valid(data) {
+		r.TextOmitted = "not valid UTF-8"
+		return
+	}
+	in.budget -= len(data)
+	r.Text = string(data)
+}
+
+// loadMessage resolves the message named by args and fetches it
+func loadMessage(client discord.API, args []string) (*discord.Message, error) {
+	channelRef, messageRef, _ := splitMessageArgs(args)
+	channelID, messageID, err := newResolver(client).message(channelRef, messageRef)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetMessage(channelID, messageID)
+}
+
+func runAttachmentsList(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	msg, err := loadMessage(client, args)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	in := newInliner(cmd)
+	results := make([]*attachmentResult, 0, len(msg.Attachments))
+	for _, a := range msg.Attachments {
+		r := &attachmentResult{Attachment: a}
+		if in.wants(r) {
+			var buf bytes.Buffer
+			if _, err := client.DownloadAttachment(a.URL, &buf); err != nil {
+				return output.PrintError(fmt.Errorf("%s: %w", a.Filename, err), pretty)
+			}
+			in.inline(r, buf.Bytes())
+		}
+		results = append(results, r)
+	}
+
+	return output.PrintSuccess(map[string]interface{}{
+		"channel_id":  msg.ChannelID,
+		"message_id":  msg.ID,
+		"attachments": results,
+		"count":       len(results),
+	}, pretty)
+}
+
+func runAttachmentsDownload(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	dir, _ := cmd.Flags().GetString("dir")
+	overwrite, _ := cmd.Flags().GetBool("overwrite")
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	msg, err := loadMessage(client, args)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return output.PrintError(fmt.Errorf("failed to create directory: %w", err), pretty)
+	}
+
+	in := newInliner(cmd)
+	results := make([]*attachmentResult, 0, len(msg.Attachments))
+	used := make(map[string]bool)
+	for _, a := range msg.Attachments {
+		name := localFileName(a, used)
+		path := filepath.Join(dir, name)
+		if !overwrite {
+			if _, err := os.Stat(path); err == nil {
+				return output.PrintError(fmt.Errorf("%s already exists, use --overwrite to replace it", path), pretty)
+			}
+		}
+
+		if err := downloadFile(client, a, path); err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		r := &attachmentResult{Attachment: a, Path: path}
+		if in.wants(r) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return output.PrintError(fmt.Errorf("failed to read %s: %w", path, err), pretty)
+			}
+			in.inline(r, data)
+		}
+		results = append(results, r)
+	}
+
+	return output.PrintSuccess(map[string]interface{}{
+		"channel_id":  msg.ChannelID,
+		"message_id":  msg.ID,
+		"dir":         dir,
+		"attachments": results,
+		"count":       len(results),
+	}, pretty)
+}
+
+// localFileName picks a safe file name for an attachment, prefixing the
+// attachment ID when the message has several files with the same name
+func localFileName(a *discord.Attachment, used map[string]bool) string {
+	name := filepath.Base(filepath.Clean("/" + a.Filename))
+	if name == "/" || name == "." {
+		name = a.ID
+	}
+	if used[name] {
+		name = a.ID + "-" + name
+	}
+	used[name] = true
+	return name
+}
+
+// downloadFile saves an attachment to path. The file is written under a
+// temporary name and only moved into place once its size matches what
+// Discord reported.
+func downloadFile(client discord.API, a *discord.Attachment, path string) error {
+	tmp := path + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmp, err)
+	}
+
+	n, err := client.DownloadAttachment(a.URL, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n != int64(a.Size) {
+		err = fmt.Errorf("size mismatch: expected %d bytes, got %d", a.Size, n)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: %w", a.Filename, err)
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+type attachmentsData struct {
+	MessageID   string `json:"message_id"`
+	Count       int    `json:"count"`
+	Attachments []struct {
+		Filename    string `json:"filename"`
+		Size        int    `json:"size"`
+		Path        string `json:"path"`
+		Text        string `json:"text"`
+		TextOmitted string `json:"text_omitted"`
+	} `json:"attachments"`
+}
+
+// postFiles sends a message with the given files to #general and returns it
+func postFiles(t *testing.T, env *testEnv, files map[string]string, order ...string) *discord.Message {
+	t.Helper()
+	args := []string{"message", "send", fake.ChannelGeneral, "files"}
+	for _, name := range order {
+		args = append(args, "--file", writeTempFile(t, name, files[name]))
+	}
+	var msg discord.Message
+	env.mustRun(t, args...).decode(t, &msg)
+	return &msg
+}
+
+func TestAttachmentsListInlineText(t *testing.T) {
+	env := newTestEnv(t)
+	files := map[string]string{
+		"crash.log": "panic: boom\n",
+		"main.go":   "package main\n",
+		"big.txt":   strings.Repeat("x", 100),
+		"shot.png":  "\x89PNG\r\n\x1a\n",
+	}
+	msg := postFiles(t, env, files, "crash.log", "main.go", "big.txt", "shot.png")
+
+	resp := env.mustRun(t, "attachments", "list", msg.URL)
+	var data attachmentsData
+	resp.decode(t, &data)
+	if data.MessageID != msg.ID || data.Count != 4 {
+		t.Fatalf("expected 4 attachments on %s, got %+v", msg.ID, data)
+	}
+	if data.Attachments[0].Text != "" {
+		t.Error("text should only be inlined with --inline-text")
+	}
+
+	resp = env.mustRun(t, "attachments", "list", fake.ChannelGeneral, msg.ID, "--inline-text", "--max-inline-bytes", "50")
+	data = attachmentsData{}
+	resp.decode(t, &data)
+	a := data.Attachments
+	if a[0].Text != files["crash.log"] || a[1].Text != files["main.go"] {
+		t.Errorf("expected log and source inlined, got %+v", a[:2])
+	}
+	if a[2].Text != "" || a[2].TextOmitted != "exceeds inline budget" {
+		t.Errorf("expected big.txt to exceed the budget, got %+v", a[2])
+	}
+	if a[3].Text != "" || a[3].TextOmitted != "" {
+		t.Errorf("images should not be inlined, got %+v", a[3])
+	}
+}
+
+func TestAttachmentsDownload(t *testing.T) {
+	env := newTestEnv(t)
+	files := map[string]string{
+		"crash.log": "panic: boom\n",
+		"shot.png":  "\x89PNG\r\n\x1a\n",
+	}
+	msg := postFiles(t, env, files, "crash.log", "shot.png")
+	dir := filepath.Join(t.TempDir(), "out")
+
+	resp := env.mustRun(t, "attachments", "download", msg.URL, "--dir", dir, "--inline-text")
+	var data attachmentsData
+	resp.decode(t, &data)
+	if data.Count != 2 {
+		t.Fatalf("expected 2 downloads, got %+v", data)
+	}
+	for _, a := range data.Attachments {
+		got, err := os.ReadFile(a.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != files[a.Filename] || len(got) != a.Size {
+			t.Errorf("%s: unexpected content %q", a.Filename, got)
+		}
+	}
+	if data.Attachments[0].Text != files["crash.log"] {
+		t.Errorf("expected crash.log inlined, got %+v", data.Attachments[0])
+	}
+
+	// Existing files are kept unless --overwrite is given
+	resp, _ = env.run(t, "attachments", "download", msg.URL, "--dir", dir)
+	if resp.OK || !strings.Contains(resp.Error, "already exists") {
+		t.Errorf("expected an already exists error, got %+v", resp)
+	}
+	env.mustRun(t, "attachments", "download", msg.URL, "--dir", dir, "--overwrite")
+
+	leftovers, _ := filepath.Glob(filepath.Join(dir, "*.part"))
+	if len(leftovers) != 0 {
+		t.Errorf("unexpected partial files: %v", leftovers)
+	}
+}
+
+func TestLocalFileName(t *testing.T) {
+	used := make(map[string]bool)
+	tests := []struct {
+		a    *discord.Attachment
+		want string
+	}{
+		{&discord.Attachment{ID: "1", Filename: "report.txt"}, "report.txt"},
+		{&discord.Attachment{ID: "2", Filename: "report.txt"}, "2-report.txt"},
+		{&discord.Attachment{ID: "3", Filename: "../../etc/passwd"}, "passwd"},
+		{&discord.Attachment{ID: "4", Filename: ""}, "4"},
+	}
+	for _, tt := range tests {
+		if got := localFileName(tt.a, used); got != tt.want {
+			t.Errorf("localFileName(%q) = %q, want %q", tt.a.Filename, got, tt.want)
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/ulfschnabel/dca/internal/config"
-	"github.com/ulfschnabel/dca/internal/discord"
 	"github.com/ulfschnabel/dca/internal/output"
 )
 
@@ -16,19 +15,26 @@ var channelsCmd = &cobra.Command{
 }
 
 var channelsListCmd = &cobra.Command{
-	Use:   "list <server-id>",
+	Use:   "list <server>",
 	Short: "List channels in a server",
-	Long:  "List all channels in a Discord server",
+	Long:  "List all channels in a Discord server, given by ID, name or link",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runChannelsList,
 }
 
 var channelsHistoryCmd = &cobra.Command{
-	Use:   "history <channel-id>",
+	Use:   "history <channel>",
 	Short: "Get message history",
-	Long:  "Get recent messages from a channel",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runChannelsHistory,
+	Long: `Get recent messages from a channel.
+
+The channel may be an ID, a link, "server/#channel", "#channel" or
+@username for a DM.
+
+Limits above 100 are fetched page by page. The output includes next_cursor:
+pass it as --before to continue backwards (or as --after when paging
+forward with --after). It is empty once the history is exhausted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChannelsHistory,
 }
 
 func init() {
@@ -36,12 +42,13 @@ func init() {
 	channelsCmd.AddCommand(channelsListCmd)
 	channelsCmd.AddCommand(channelsHistoryCmd)
 
-	channelsHistoryCmd.Flags().Int("limit", 10, "Number of messages to retrieve (max 100)")
+	addHistoryFlags(channelsHistoryCmd, 10)
+	addRenderFlag(channelsHistoryCmd)
 }
 
 func runChannelsList(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
-	serverID := args[0]
+	serverRef := args[0]
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -60,12 +67,17 @@ func runChannelsList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
+	serverID, err := newResolver(client).guild(serverRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
 	// List channels
 	channels, err := client.ListChannels(serverID)
 	if err != nil {
@@ -80,8 +92,11 @@ func runChannelsList(cmd *cobra.Command, args []string) error {
 
 func runChannelsHistory(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
-	channelID := args[0]
-	limit, _ := cmd.Flags().GetInt("limit")
+	channelRef := args[0]
+	opts, err := historyOptions(cmd)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -100,20 +115,29 @@ func runChannelsHistory(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
+	channelID, err := newResolver(client).channel(channelRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
 	// Get messages
-	messages, err := client.GetMessages(channelID, limit)
+	page, err := client.GetMessages(channelID, opts)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 
+	renderMessages(cmd, client, page.Messages...)
+	compactMessages(cmd, page.Messages...)
+
 	return output.PrintSuccess(map[string]interface{}{
-		"messages": messages,
-		"count":    len(messages),
+		"messages":    page.Messages,
+		"count":       len(page.Messages),
+		"next_cursor": page.NextCursor,
 	}, pretty)
 }
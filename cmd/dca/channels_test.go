@@ -0,0 +1,346 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+type historyData struct {
+	Messages   []*discord.Message `json:"messages"`
+	Count      int                `json:"count"`
+	NextCursor string             `json:"next_cursor"`
+}
+
+// seedHistory adds n messages to a channel, one second apart, after the
+// default fixture
+func seedHistory(env *testEnv, channelID string, n int) {
+	start := fake.SeedTime.Add(time.Hour)
+	for i := 0; i < n; i++ {
+		env.srv.AddMessage(&discordgo.Message{
+			ChannelID: channelID,
+			Author:    env.srv.Me(),
+			Content:   fmt.Sprintf("message %d", i),
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+		})
+	}
+}
+
+// checkNewestFirst fails if messages are not strictly ordered newest first
+func checkNewestFirst(t *testing.T, msgs []*discord.Message) {
+	t.Helper()
+	for i := 1; i < len(msgs); i++ {
+		if msgs[i-1].Timestamp < msgs[i].Timestamp || msgs[i-1].ID == msgs[i].ID {
+			t.Fatalf("messages %d and %d are out of order", i-1, i)
+		}
+	}
+}
+
+func TestChannelsList(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "channels", "list", fake.GuildID)
+
+	var data struct {
+		Channels []*discord.Channel `json:"channels"`
+	}
+	resp.decode(t, &data)
+	types := map[string]string{}
+	for _, ch := range data.Channels {
+		types[ch.Name] = ch.Type
+	}
+	if types["general"] != "text" || types["help"] != "forum" {
+		t.Errorf("unexpected channels: %v", types)
+	}
+}
+
+func TestChannelsHistoryPaginates(t *testing.T) {
+	env := newTestEnv(t)
+	seedHistory(env, fake.ChannelRandom, 250)
+	all := env.srv.Messages(fake.ChannelRandom)
+
+	resp := env.mustRun(t, "channels", "history", fake.ChannelRandom, "--limit", "230")
+	var page historyData
+	resp.decode(t, &page)
+	if page.Count != 230 {
+		t.Fatalf("expected 230 messages, got %d", page.Count)
+	}
+	checkNewestFirst(t, page.Messages)
+	if page.Messages[0].ID != all[len(all)-1].ID {
+		t.Errorf("expected the newest message first")
+	}
+	if page.NextCursor != page.Messages[229].ID {
+		t.Errorf("expected next_cursor %s, got %s", page.Messages[229].ID, page.NextCursor)
+	}
+
+	resp = env.mustRun(t, "channels", "history", fake.ChannelRandom, "--limit", "100", "--before", page.NextCursor)
+	var rest historyData
+	resp.decode(t, &rest)
+	if rest.Count != len(all)-230 {
+		t.Fatalf("expected the remaining %d messages, got %d", len(all)-230, rest.Count)
+	}
+	if rest.Messages[rest.Count-1].ID != all[0].ID {
+		t.Errorf("expected to reach the oldest message")
+	}
+	if rest.NextCursor != "" {
+		t.Errorf("expected an empty next_cursor at the start of the channel, got %s", rest.NextCursor)
+	}
+}
+
+func TestChannelsHistoryAfter(t *testing.T) {
+	env := newTestEnv(t)
+	seedHistory(env, fake.ChannelRandom, 150)
+	all := env.srv.Messages(fake.ChannelRandom)
+
+	resp := env.mustRun(t, "channels", "history", fake.ChannelRandom, "--after", all[9].ID, "--limit", "120")
+	var page historyData
+	resp.decode(t, &page)
+	if page.Count != 120 {
+		t.Fatalf("expected 120 messages, got %d", page.Count)
+	}
+	checkNewestFirst(t, page.Messages)
+	if oldest := page.Messages[page.Count-1]; oldest.ID != all[10].ID {
+		t.Errorf("expected the first message after the cursor, got %s", oldest.Content)
+	}
+	if page.NextCursor != all[129].ID {
+		t.Errorf("expected next_cursor to be the newest message returned")
+	}
+
+	resp = env.mustRun(t, "channels", "history", fake.ChannelRandom, "--after", page.NextCursor, "--limit", "120")
+	var rest historyData
+	resp.decode(t, &rest)
+	if rest.Count != len(all)-130 || rest.NextCursor != "" {
+		t.Errorf("expected the final %d messages and no cursor, got %d and %q", len(all)-130, rest.Count, rest.NextCursor)
+	}
+}
+
+func TestChannelsHistoryAround(t *testing.T) {
+	env := newTestEnv(t)
+	seedHistory(env, fake.ChannelRandom, 50)
+	all := env.srv.Messages(fake.ChannelRandom)
+	center := all[25]
+
+	resp := env.mustRun(t, "channels", "history", fake.ChannelRandom, "--around", center.ID, "--limit", "5")
+	var page historyData
+	resp.decode(t, &page)
+	if page.Count != 5 {
+		t.Fatalf("expected 5 messages, got %d", page.Count)
+	}
+	found := false
+	for _, m := range page.Messages {
+		found = found || m.ID == center.ID
+	}
+	if !found {
+		t.Error("expected the window to include the center message")
+	}
+
+	resp, _ = env.run(t, "channels", "history", fake.ChannelRandom, "--around", center.ID, "--limit", "101")
+	if resp.OK {
+		t.Error("expected --around with more than 100 messages to fail")
+	}
+}
+
+func TestChannelsHistoryConflictingCursors(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp, _ := env.run(t, "channels", "history", fake.ChannelGeneral, "--before", "1", "--after", "2")
+	if resp.OK {
+		t.Fatal("expected --before with --after to fail")
+	}
+}
+
+func TestChannelsHistorySinceUntil(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "channels", "history", fake.ChannelGeneral,
+		"--since", fake.SeedTime.Add(30*time.Second).Format(time.RFC3339),
+		"--until", fake.SeedTime.Add(5*time.Minute).Format(time.RFC3339))
+	var page historyData
+	resp.decode(t, &page)
+	if page.Count != 1 || page.Messages[0].Content != "the deploy is done" {
+		t.Fatalf("expected only the message inside the window, got %+v", page.Messages)
+	}
+
+	resp, _ = env.run(t, "channels", "history", fake.ChannelGeneral, "--since", "1h", "--until", "2h")
+	if resp.OK {
+		t.Error("expected --since after --until to fail")
+	}
+}
+
+func TestChannelsHistorySinceStopsPaging(t *testing.T) {
+	env := newTestEnv(t)
+	seedHistory(env, fake.ChannelRandom, 250)
+	since := fake.SeedTime.Add(time.Hour + 200*time.Second)
+
+	resp := env.mustRun(t, "channels", "history", fake.ChannelRandom, "--since", since.Format(time.RFC3339), "--limit", "1000")
+	var page historyData
+	resp.decode(t, &page)
+	if page.Count != 50 {
+		t.Fatalf("expected the 50 messages since the cutoff, got %d", page.Count)
+	}
+	if page.NextCursor != "" {
+		t.Errorf("expected no next_cursor once the window is covered, got %s", page.NextCursor)
+	}
+
+	resp = env.mustRun(t, "channels", "history", fake.ChannelRandom, "--after", "1", "--since", since.Format(time.RFC3339), "--limit", "20")
+	page = historyData{}
+	resp.decode(t, &page)
+	if page.Count != 20 || page.Messages[19].Content != "message 200" {
+		t.Errorf("expected paging forward to start at the cutoff, got %d messages", page.Count)
+	}
+}
+
+// seedRichMessage adds a reply with an attachment, embed, reactions,
+// mentions and a thread to general
+func seedRichMessage(env *testEnv) *discordgo.Message {
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+	edited := fake.SeedTime.Add(2 * time.Hour)
+	bob := &discordgo.User{ID: fake.UserBob, Username: "bob"}
+	return env.srv.AddMessage(&discordgo.Message{
+		ChannelID:       fake.ChannelGeneral,
+		Author:          bob,
+		Content:         "see the attached log <@" + fake.UserMe + ">",
+		Timestamp:       fake.SeedTime.Add(time.Hour),
+		EditedTimestamp: &edited,
+		Type:            discordgo.MessageTypeReply,
+		Pinned:          true,
+		Attachments: []*discordgo.MessageAttachment{{
+			ID: "900", Filename: "build.log", ContentType: "text/plain", Size: 42,
+			URL: "https://cdn.example.test/build.log",
+		}},
+		Embeds: []*discordgo.MessageEmbed{{
+			Type: discordgo.EmbedTypeLink, Title: "CI run", URL: "https://ci.example.test/1",
+			Fields: []*discordgo.MessageEmbedField{{Name: "status", Value: "failed"}},
+		}},
+		Reactions: []*discordgo.MessageReactions{
+			{Emoji: &discordgo.Emoji{Name: "👀"}, Count: 2, Me: true},
+			{Emoji: &discordgo.Emoji{Name: "party", ID: "777"}, Count: 1},
+		},
+		MessageReference: &discordgo.MessageReference{
+			MessageID: target.ID, ChannelID: fake.ChannelGeneral, GuildID: fake.GuildID,
+		},
+		ReferencedMessage: target,
+		Mentions:          []*discordgo.User{env.srv.Me()},
+		Thread: &discordgo.Channel{
+			ID: "950", Name: "log discussion", MessageCount: 3,
+			ThreadMetadata: &discordgo.ThreadMetadata{Archived: true},
+		},
+	})
+}
+
+func TestChannelsHistoryMessageDetails(t *testing.T) {
+	env := newTestEnv(t)
+	seedRichMessage(env)
+
+	resp := env.mustRun(t, "channels", "history", fake.ChannelGeneral, "--limit", "1")
+	var data historyData
+	resp.decode(t, &data)
+	if len(data.Messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(data.Messages))
+	}
+	msg := data.Messages[0]
+
+	if msg.Type != "reply" || !msg.Pinned || msg.EditedTimestamp == "" {
+		t.Errorf("unexpected type/pinned/edited: %q %v %q", msg.Type, msg.Pinned, msg.EditedTimestamp)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "build.log" || msg.Attachments[0].Size != 42 {
+		t.Errorf("unexpected attachments: %+v", msg.Attachments)
+	}
+	if len(msg.Embeds) != 1 || msg.Embeds[0].Title != "CI run" || len(msg.Embeds[0].Fields) != 1 {
+		t.Errorf("unexpected embeds: %+v", msg.Embeds)
+	}
+	if len(msg.Reactions) != 2 || msg.Reactions[0].Emoji != "👀" || !msg.Reactions[0].Me || msg.Reactions[1].Emoji != "party:777" {
+		t.Errorf("unexpected reactions: %+v", msg.Reactions)
+	}
+	if msg.Reference == nil || msg.Reference.Author == nil || msg.Reference.Author.ID != fake.UserAlice {
+		t.Errorf("unexpected reference: %+v", msg.Reference)
+	}
+	if len(msg.Mentions) != 1 || msg.Mentions[0].ID != fake.UserMe {
+		t.Errorf("unexpected mentions: %+v", msg.Mentions)
+	}
+	if msg.Thread == nil || msg.Thread.ID != "950" || !msg.Thread.Archived {
+		t.Errorf("unexpected thread: %+v", msg.Thread)
+	}
+}
+
+func TestChannelsHistoryCompact(t *testing.T) {
+	env := newTestEnv(t)
+	rich := seedRichMessage(env)
+
+	resp := env.mustRun(t, "channels", "history", fake.ChannelGeneral, "--limit", "1", "--compact")
+	var data struct {
+		Messages []map[string]interface{} `json:"messages"`
+	}
+	resp.decode(t, &data)
+	if len(data.Messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(data.Messages))
+	}
+	msg := data.Messages[0]
+	for _, key := range []string{"type", "attachments", "embeds", "reactions", "message_reference", "mentions", "thread", "edited_timestamp", "pinned"} {
+		if _, ok := msg[key]; ok {
+			t.Errorf("compact output still has %q", key)
+		}
+	}
+	if msg["id"] != rich.ID || msg["content"] != rich.Content {
+		t.Errorf("compact output lost the basics: %v", msg)
+	}
+}
+
+func TestChannelsHistoryRender(t *testing.T) {
+	env := newTestEnv(t)
+	raw := "<@" + fake.UserAlice + "> see <#" + fake.ChannelRandom + ">, cc <@&" + fake.RoleModerators + "> <:party:777>"
+	env.srv.AddMessage(&discordgo.Message{
+		ChannelID: fake.ChannelGeneral,
+		Author:    env.srv.Me(),
+		Content:   raw,
+		Timestamp: fake.SeedTime.Add(time.Hour),
+	})
+
+	resp := env.mustRun(t, "channels", "history", fake.ChannelGeneral, "--limit", "1", "--render")
+	var data historyData
+	resp.decode(t, &data)
+	if len(data.Messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(data.Messages))
+	}
+	msg := data.Messages[0]
+	// Alice goes by her nickname in the guild
+	if want := "@Alice (ops) see #random, cc @moderators :party:"; msg.Content != want {
+		t.Errorf("expected rendered content %q, got %q", want, msg.Content)
+	}
+	if msg.RawContent != raw {
+		t.Errorf("expected raw content %q, got %q", raw, msg.RawContent)
+	}
+
+	resp = env.mustRun(t, "channels", "history", fake.ChannelGeneral, "--limit", "1")
+	var plain historyData
+	resp.decode(t, &plain)
+	if plain.Messages[0].Content != raw || plain.Messages[0].RawContent != "" {
+		t.Errorf("content should be raw without --render, got %+v", plain.Messages[0])
+	}
+}
+
+func TestChannelsHistoryDisplayNames(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "channels", "history", fake.ChannelGeneral)
+	var data historyData
+	resp.decode(t, &data)
+
+	authors := make(map[string]discord.Author)
+	for _, m := range data.Messages {
+		authors[m.Author.ID] = m.Author
+	}
+	if a := authors[fake.UserAlice]; a.Nick != "Alice (ops)" || a.DisplayName != "Alice (ops)" {
+		t.Errorf("expected alice's nickname as display name, got %+v", a)
+	}
+	if b := authors[fake.UserBob]; b.GlobalName != "Bobby" || b.Nick != "" || b.DisplayName != "Bobby" {
+		t.Errorf("expected bob's global name as display name, got %+v", b)
+	}
+	if me := authors[fake.UserMe]; me.DisplayName != "me" {
+		t.Errorf("expected username as fallback display name, got %+v", me)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+)
+
+// newClient creates the Discord client for a command. Network flags
+// override the corresponding config values.
+func newClient(cmd *cobra.Command, cfg *config.Config, token string) (discord.API, error) {
+	opts := discord.Options{
+		BaseURL:  cfg.APIBaseURL,
+		ProxyURL: cfg.HTTPProxy,
+		CABundle: cfg.CABundle,
+		Timeout:  cfg.Timeout(),
+	}
+
+	if v, _ := cmd.Flags().GetString("api-base-url"); v != "" {
+		opts.BaseURL = v
+	}
+	if v, _ := cmd.Flags().GetString("proxy"); v != "" {
+		opts.ProxyURL = v
+	}
+	if v, _ := cmd.Flags().GetString("ca-bundle"); v != "" {
+		opts.CABundle = v
+	}
+	if v, _ := cmd.Flags().GetDuration("timeout"); v > 0 {
+		opts.Timeout = v
+	}
+
+	client, err := discord.NewWithOptions(token, opts)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
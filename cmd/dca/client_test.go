@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+func TestAPIBaseURLFlagOverridesConfig(t *testing.T) {
+	env := newTestEnv(t)
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, APIBaseURL: "http://127.0.0.1:1/api/v9/"})
+
+	resp, _ := env.run(t, "servers", "list")
+	if resp.OK {
+		t.Fatal("expected the unreachable configured base URL to fail")
+	}
+
+	resp = env.mustRun(t, "servers", "list", "--api-base-url", env.srv.APIURL())
+	var data struct {
+		Count int `json:"count"`
+	}
+	resp.decode(t, &data)
+	if data.Count != 1 {
+		t.Errorf("expected 1 server, got %d", data.Count)
+	}
+}
+
+func TestInvalidRequestTimeout(t *testing.T) {
+	env := newTestEnv(t)
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequestTimeout: "soon"})
+
+	resp, _ := env.run(t, "servers", "list")
+	if resp.OK {
+		t.Fatal("expected an invalid request_timeout to fail")
+	}
+}
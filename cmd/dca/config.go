@@ -110,6 +110,31 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Config file: %s\n", cfgPath)
 	fmt.Printf("User Token: %s\n", maskedToken)
 	fmt.Printf("Require Approval: %v\n", cfg.RequireApproval)
+	if cfg.APIBaseURL != "" {
+		fmt.Printf("API Base URL: %s\n", cfg.APIBaseURL)
+	}
+	if cfg.HTTPProxy != "" {
+		fmt.Printf("HTTP Proxy: %s\n", cfg.HTTPProxy)
+	}
+	if cfg.CABundle != "" {
+		fmt.Printf("CA Bundle: %s\n", cfg.CABundle)
+	}
+	if cfg.RequestTimeout != "" {
+		fmt.Printf("Request Timeout: %s\n", cfg.RequestTimeout)
+	}
+	if cfg.DuplicateWindow != "" {
+		fmt.Printf("Duplicate Window: %s\n", cfg.DuplicateWindow)
+	}
+	if cfg.AllowedMentions != "" {
+		fmt.Printf("Allowed Mentions: %s\n", cfg.AllowedMentions)
+	}
+	if cfg.QuietHours != "" {
+		if cfg.QuietHoursTimezone != "" {
+			fmt.Printf("Quiet Hours: %s (%s)\n", cfg.QuietHours, cfg.QuietHoursTimezone)
+		} else {
+			fmt.Printf("Quiet Hours: %s\n", cfg.QuietHours)
+		}
+	}
 
 	return nil
 }
@@ -1,10 +1,7 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/ulfschnabel/dca/internal/config"
@@ -15,30 +12,35 @@ import (
 var dmCmd = &cobra.Command{
 	Use:   "dm",
 	Short: "Direct message operations",
-	Long:  "Send direct messages to users",
+	Long:  "Send direct messages to users and manage group DMs",
 }
 
 var dmSendCmd = &cobra.Command{
-	Use:   "send <username-or-id> <message>",
+	Use:   "send <username-or-id> [message]",
 	Short: "Send a DM to a user",
-	Long:  "Send a direct message to a user by username or ID (requires approval unless --dry-run)",
-	Args:  cobra.ExactArgs(2),
+	Long:  "Send a direct message to a user by username or ID, optionally with --file attachments (requires approval unless --dry-run)",
+	Args:  cobra.RangeArgs(1, 2),
 	RunE:  runDMSend,
 }
 
 var dmHistoryCmd = &cobra.Command{
-	Use:   "history <username-or-id>",
-	Short: "Get DM history with a user",
-	Long:  "Get recent direct messages with a user",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runDMHistory,
+	Use:   "history <username-or-id | group>",
+	Short: "Get DM history with a user or group",
+	Long: `Get recent direct messages with a user, or in a group DM.
+
+A group DM may be given by channel ID, link or name.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDMHistory,
 }
 
 var dmListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List DM conversations",
-	Long:  "List all DM conversations sorted by recent activity",
-	RunE:  runDMList,
+	Long: `List all DM and group DM conversations sorted by recent activity.
+
+Group DMs have type group_dm and list their name, icon and recipients
+under group in place of user.`,
+	RunE: runDMList,
 }
 
 func init() {
@@ -48,16 +50,24 @@ func init() {
 	dmCmd.AddCommand(dmListCmd)
 
 	dmSendCmd.Flags().Bool("dry-run", false, "Show what would be sent without actually sending")
-	dmHistoryCmd.Flags().Int("limit", 10, "Number of messages to retrieve")
+	addFileFlags(dmSendCmd)
+	addContentFlags(dmSendCmd)
+	addIdempotencyFlag(dmSendCmd)
+	addMentionFlags(dmSendCmd, false)
+	addNotificationFlags(dmSendCmd)
+	addHistoryFlags(dmHistoryCmd, 10)
+	addRenderFlag(dmHistoryCmd)
 	dmListCmd.Flags().Int("limit", 20, "Number of DM channels to show")
 	dmListCmd.Flags().Bool("active-only", true, "Only show DMs with recent messages")
 }
 
 func runDMSend(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
-	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	userIdentifier := args[0]
-	content := args[1]
+	content := ""
+	if len(args) > 1 {
+		content = args[1]
+	}
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -65,6 +75,12 @@ func runDMSend(cmd *cobra.Command, args []string) error {
 		return output.PrintError(err, pretty)
 	}
 
+	// Read the message before connecting so missing files fail fast
+	flow, err := newSendFlow(cmd, cfg, content)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
 	// Get token
 	token, _ := cmd.Flags().GetString("token")
 	if token == "" {
@@ -76,73 +92,35 @@ func runDMSend(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
-	// Resolve username to user ID if needed
-	userID := userIdentifier
-	var username string
-
-	// If input doesn't look like a numeric ID, treat it as a username
-	if !isNumeric(userIdentifier) {
-		fmt.Printf("🔍 Looking up user '%s'...\n", userIdentifier)
-		user, err := client.FindUserByUsername(userIdentifier)
-		if err != nil {
-			return output.PrintError(err, pretty)
-		}
-		userID = user.ID
-		username = user.Username
-		fmt.Printf("✓ Found: %s (ID: %s)\n\n", username, userID)
-	} else {
-		username = userID // Use ID as display name if given directly
+	// Resolve username, @username or mention to a user ID
+	user, err := newResolver(client).user(userIdentifier)
+	if err != nil {
+		return output.PrintError(err, pretty)
 	}
-
-	// Dry run - just show what would be sent
-	if dryRun {
-		return output.PrintSuccess(map[string]interface{}{
-			"action":   "send_dm",
-			"user_id":  userID,
-			"username": username,
-			"content":  content,
-			"dry_run":  true,
-		}, pretty)
+	userID := user.ID
+	username := user.Username
+	if username == "" {
+		username = userID // Use ID as display name if given directly
 	}
 
-	// Check approval requirement
-	if cfg.RequireApproval {
-		if username != "" && username != userID {
-			fmt.Printf("📝 Send DM to %s (%s):\n", username, userID)
-		} else {
-			fmt.Printf("📝 Send DM to user %s:\n", userID)
-		}
-		fmt.Printf("   \"%s\"\n\n", content)
-		fmt.Print("Proceed? [y/N]: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
-		}
-
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
-			return output.PrintSuccess(map[string]interface{}{
-				"action":    "send_dm",
-				"cancelled": true,
-			}, pretty)
-		}
+	flow.action = "send_dm"
+	if username != userID {
+		flow.header = fmt.Sprintf("📝 Send DM to %s (%s):", username, userID)
+	} else {
+		flow.header = fmt.Sprintf("📝 Send DM to user %s:", userID)
 	}
-
-	// Send DM
-	msg, err := client.SendDirectMessage(userID, content)
-	if err != nil {
-		return output.PrintError(err, pretty)
+	flow.details = map[string]interface{}{"user_id": userID, "username": username}
+	flow.target = "dm:" + userID
+	flow.send = func(_ int, part *discord.OutgoingMessage) (*discord.Message, error) {
+		return client.SendDirectMessage(userID, part)
 	}
-
-	return output.PrintSuccess(msg, pretty)
+	return flow.run(client, pretty)
 }
 
 // isNumeric checks if a string contains only digits
@@ -157,8 +135,11 @@ func isNumeric(s string) bool {
 
 func runDMHistory(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
-	limit, _ := cmd.Flags().GetInt("limit")
-	userIdentifier := args[0]
+	opts, err := historyOptions(cmd)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	dmRef := args[0]
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -177,31 +158,31 @@ func runDMHistory(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
-	// Resolve username to user ID if needed
-	userID := userIdentifier
-	if !isNumeric(userIdentifier) {
-		user, err := client.FindUserByUsername(userIdentifier)
-		if err != nil {
-			return output.PrintError(err, pretty)
-		}
-		userID = user.ID
+	// Resolve a group DM, or the DM with a user
+	channelID, err := newResolver(client).dm(dmRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
 	}
 
 	// Get DM history
-	messages, err := client.GetDMHistory(userID, limit)
+	page, err := client.GetMessages(channelID, opts)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 
+	renderMessages(cmd, client, page.Messages...)
+	compactMessages(cmd, page.Messages...)
+
 	return output.PrintSuccess(map[string]interface{}{
-		"messages": messages,
-		"count":    len(messages),
+		"messages":    page.Messages,
+		"count":       len(page.Messages),
+		"next_cursor": page.NextCursor,
 	}, pretty)
 }
 
@@ -227,7 +208,7 @@ func runDMList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
@@ -239,6 +220,10 @@ func runDMList(cmd *cobra.Command, args []string) error {
 		return output.PrintError(err, pretty)
 	}
 
+	for _, ch := range dmChannels {
+		compactMessages(cmd, ch.LastMessage)
+	}
+
 	return output.PrintSuccess(map[string]interface{}{
 		"dm_channels": dmChannels,
 		"count":       len(dmChannels),
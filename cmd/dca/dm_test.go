@@ -0,0 +1,139 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+func TestDMList(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "dm", "list")
+
+	var data struct {
+		DMChannels []*discord.DMChannel `json:"dm_channels"`
+		Count      int                  `json:"count"`
+	}
+	resp.decode(t, &data)
+	if data.Count != 1 || data.DMChannels[0].ChannelID != fake.ChannelDMAlice {
+		t.Fatalf("expected the DM with alice, got %+v", data.DMChannels)
+	}
+	if data.DMChannels[0].User.Username != "alice" {
+		t.Errorf("expected recipient alice, got %q", data.DMChannels[0].User.Username)
+	}
+	if data.DMChannels[0].LastMessage == nil || data.DMChannels[0].LastMessage.Content != "yes, what's up?" {
+		t.Errorf("unexpected last message: %+v", data.DMChannels[0].LastMessage)
+	}
+}
+
+func TestDMHistory(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, who := range []string{fake.UserAlice, "alice"} {
+		resp := env.mustRun(t, "dm", "history", who)
+
+		var data struct {
+			Messages []*discord.Message `json:"messages"`
+		}
+		resp.decode(t, &data)
+		if len(data.Messages) != 2 {
+			t.Fatalf("%s: expected 2 messages, got %d", who, len(data.Messages))
+		}
+		if data.Messages[0].Content != "yes, what's up?" {
+			t.Errorf("%s: expected newest message first, got %q", who, data.Messages[0].Content)
+		}
+	}
+}
+
+func TestDMSend(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "dm", "send", "alice", "see you soon")
+
+	var msg discord.Message
+	resp.decode(t, &msg)
+	if msg.ChannelID != fake.ChannelDMAlice {
+		t.Errorf("expected DM channel %s, got %s", fake.ChannelDMAlice, msg.ChannelID)
+	}
+	if want := "https://discord.com/channels/@me/" + fake.ChannelDMAlice + "/" + msg.ID; msg.URL != want || msg.GuildID != "" {
+		t.Errorf("expected DM URL %s without guild, got %q (guild %q)", want, msg.URL, msg.GuildID)
+	}
+	if last := env.srv.LastMessage(fake.ChannelDMAlice); last.Content != "see you soon" {
+		t.Errorf("DM not stored, last is %q", last.Content)
+	}
+}
+
+func TestDMSendDryRun(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "dm", "send", fake.UserBob, "maybe later", "--dry-run")
+
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["dry_run"] != true || data["user_id"] != fake.UserBob {
+		t.Errorf("unexpected dry-run output: %v", data)
+	}
+}
+
+func TestDMSendUnknownUser(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp, _ := env.run(t, "dm", "send", "nobody", "hello?")
+	if resp.OK {
+		t.Fatal("expected an unknown username to fail")
+	}
+}
+
+func TestDMSendByDisplayName(t *testing.T) {
+	env := newTestEnv(t)
+
+	for name, want := range map[string]string{
+		"bobby":       fake.UserBob,
+		"Alice (ops)": fake.UserAlice,
+	} {
+		resp := env.mustRun(t, "dm", "send", name, "hi", "--dry-run")
+
+		var data map[string]interface{}
+		resp.decode(t, &data)
+		if data["user_id"] != want {
+			t.Errorf("%s: expected user %s, got %v", name, want, data["user_id"])
+		}
+	}
+}
+
+func TestDMSendFile(t *testing.T) {
+	env := newTestEnv(t)
+	env.stdin = "notes"
+
+	resp := env.mustRun(t, "dm", "send", "alice", "--file", "-", "--stdin-name", "notes.txt")
+
+	var msg discord.Message
+	resp.decode(t, &msg)
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "notes.txt" || msg.Attachments[0].Size != 5 {
+		t.Errorf("expected notes.txt attachment, got %+v", msg.Attachments)
+	}
+}
+
+func TestDMSendIdempotencyKey(t *testing.T) {
+	env := newTestEnv(t)
+
+	var first, second discord.Message
+	env.mustRun(t, "dm", "send", "alice", "on my way", "--idempotency-key", "k1").decode(t, &first)
+	resp := env.mustRun(t, "dm", "send", "@alice", "on my way", "--idempotency-key", "k1")
+	resp.decode(t, &second)
+	if second.ID != first.ID || len(resp.Warnings) != 1 {
+		t.Errorf("expected the original DM %s with a warning, got %s %v", first.ID, second.ID, resp.Warnings)
+	}
+}
+
+func TestDMSendSilent(t *testing.T) {
+	env := newTestEnv(t)
+
+	var msg discord.Message
+	env.mustRun(t, "dm", "send", "alice", "no rush", "--silent").decode(t, &msg)
+	if len(msg.Flags) != 1 || msg.Flags[0] != "silent" {
+		t.Errorf("expected a silent DM, got %v", msg.Flags)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/discord"
+)
+
+// addEmbedFlags registers the embed flags shared by send commands
+func addEmbedFlags(cmd *cobra.Command) {
+	cmd.Flags().String("embed-json", "", "Read embeds in Discord's JSON format from a file; - reads stdin")
+	cmd.Flags().String("embed-title", "", "Embed title")
+	cmd.Flags().String("embed-description", "", "Embed description")
+	cmd.Flags().String("embed-url", "", "Link for the embed title")
+	cmd.Flags().String("embed-color", "", "Embed color as #RRGGBB, 0xRRGGBB or a decimal number")
+	cmd.Flags().StringArray("embed-field", nil, "Add an embed field as name=value (repeatable)")
+	cmd.Flags().StringArray("embed-inline-field", nil, "Add an inline embed field as name=value (repeatable)")
+	cmd.Flags().String("embed-footer", "", "Embed footer text")
+	cmd.Flags().String("embed-author", "", "Embed author name")
+	cmd.Flags().String("embed-image", "", "Embed image URL")
+	cmd.Flags().String("embed-thumbnail", "", "Embed thumbnail URL")
+	cmd.Flags().String("embed-timestamp", "", "Embed timestamp in RFC 3339, or now")
+}
+
+// readEmbeds loads embeds from --embed-json and builds one more from the
+// shorthand --embed-* flags, if any are set
+func readEmbeds(cmd *cobra.Command) (embeds []*discord.Embed, err error) {
+	if path, _ := cmd.Flags().GetString("embed-json"); path != "" {
+		var data []byte
+		if path == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embed JSON: %w", err)
+		}
+		if embeds, err = discord.ParseEmbedJSON(data); err != nil {
+			return nil, err
+		}
+	}
+
+	embed, err := shorthandEmbed(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if embed != nil {
+		embeds = append(embeds, embed)
+	}
+
+	return embeds, nil
+}
+
+// shorthandEmbed builds an embed from the --embed-* flags, or returns nil
+// when none of them is set
+func shorthandEmbed(cmd *cobra.Command) (*discord.Embed, error) {
+	set := false
+	str := func(name string) string {
+		v, _ := cmd.Flags().GetString(name)
+		if v != "" {
+			set = true
+		}
+		return v
+	}
+
+	embed := &discord.Embed{
+		Title:        str("embed-title"),
+		Description:  str("embed-description"),
+		URL:          str("embed-url"),
+		Footer:       str("embed-footer"),
+		AuthorName:   str("embed-author"),
+		ImageURL:     str("embed-image"),
+		ThumbnailURL: str("embed-thumbnail"),
+	}
+
+	if color := str("embed-color"); color != "" {
+		c, err := parseColor(color)
+		if err != nil {
+			return nil, err
+		}
+		embed.Color = c
+	}
+
+	switch ts := str("embed-timestamp"); ts {
+	case "":
+	case "now":
+		embed.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	default:
+		embed.Timestamp = ts
+	}
+
+	for _, flag := range []string{"embed-field", "embed-inline-field"} {
+		values, _ := cmd.Flags().GetStringArray(flag)
+		for _, v := range values {
+			name, value, ok := strings.Cut(v, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("invalid --%s %q, expected name=value", flag, v)
+			}
+			set = true
+			embed.Fields = append(embed.Fields, &discord.EmbedField{
+				Name:   strings.TrimSpace(name),
+				Value:  value,
+				Inline: flag == "embed-inline-field",
+			})
+		}
+	}
+
+	if !set {
+		return nil, nil
+	}
+	return embed, nil
+}
+
+// parseColor accepts #RRGGBB, 0xRRGGBB or a decimal color value
+func parseColor(s string) (int, error) {
+	var v int64
+	var err error
+	switch {
+	case strings.HasPrefix(s, "#"):
+		v, err = strconv.ParseInt(s[1:], 16, 32)
+	case strings.HasPrefix(strings.ToLower(s), "0x"):
+		v, err = strconv.ParseInt(s[2:], 16, 32)
+	default:
+		v, err = strconv.ParseInt(s, 10, 32)
+	}
+	if err != nil || v < 0 || v > 0xFFFFFF {
+		return 0, fmt.Errorf("invalid embed color %q, expected #RRGGBB, 0xRRGGBB or 0-16777215", s)
+	}
+	return int(v), nil
+}
+
+// printEmbeds previews embeds in an approval prompt
+func printEmbeds(embeds []*discord.Embed) {
+	for _, e := range embeds {
+		line := func(format string, args ...interface{}) {
+			fmt.Printf("   ┃ "+format+"\n", args...)
+		}
+		if e.AuthorName != "" {
+			line("%s", e.AuthorName)
+		}
+		if e.Title != "" {
+			if e.URL != "" {
+				line("**%s** <%s>", e.Title, e.URL)
+			} else {
+				line("**%s**", e.Title)
+			}
+		}
+		if e.Description != "" {
+			for _, l := range strings.Split(e.Description, "\n") {
+				line("%s", l)
+			}
+		}
+		for _, f := range e.Fields {
+			line("%s: %s", f.Name, f.Value)
+		}
+		if e.ImageURL != "" {
+			line("[image %s]", e.ImageURL)
+		}
+		if e.ThumbnailURL != "" {
+			line("[thumbnail %s]", e.ThumbnailURL)
+		}
+		var footer []string
+		for _, part := range []string{e.Footer, e.Timestamp} {
+			if part != "" {
+				footer = append(footer, part)
+			}
+		}
+		if len(footer) > 0 {
+			line("%s", strings.Join(footer, " • "))
+		}
+		if e.Color != 0 {
+			line("(color #%06X)", e.Color)
+		}
+		fmt.Println()
+	}
+}
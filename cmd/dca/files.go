@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/discord"
+)
+
+// addFileFlags registers the attachment flags shared by send commands
+func addFileFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("file", nil, "Attach a file (repeatable); - reads it from stdin")
+	cmd.Flags().String("stdin-name", "stdin.txt", "File name for an attachment read from stdin")
+}
+
+// readFiles loads the files given with --file
+func readFiles(cmd *cobra.Command) (files []*discord.File, err error) {
+	paths, _ := cmd.Flags().GetStringArray("file")
+	if len(paths) > discord.MaxFiles {
+		return nil, fmt.Errorf("at most %d files can be attached, got %d", discord.MaxFiles, len(paths))
+	}
+
+	usesStdin := false
+	for _, path := range paths {
+		var name string
+		var data []byte
+		if path == "-" {
+			if usesStdin {
+				return nil, fmt.Errorf("stdin can only be attached once")
+			}
+			usesStdin = true
+			name, _ = cmd.Flags().GetString("stdin-name")
+			data, err = io.ReadAll(os.Stdin)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read stdin: %w", err)
+			}
+		} else {
+			name = filepath.Base(path)
+			data, err = os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read attachment: %w", err)
+			}
+		}
+
+		files = append(files, &discord.File{
+			Name:        name,
+			ContentType: detectContentType(name, data),
+			Data:        data,
+		})
+	}
+
+	return files, nil
+}
+
+// detectContentType guesses a MIME type from the file name, falling back
+// to sniffing the content
+func detectContentType(name string, data []byte) string {
+	if ct := mime.TypeByExtension(filepath.Ext(name)); ct != "" {
+		return ct
+	}
+	return http.DetectContentType(data)
+}
+
+// fileSummaries describes attachments for dry-run output
+func fileSummaries(files []*discord.File) []map[string]interface{} {
+	summaries := make([]map[string]interface{}, 0, len(files))
+	for _, f := range files {
+		summaries = append(summaries, map[string]interface{}{
+			"name":         f.Name,
+			"size":         len(f.Data),
+			"content_type": f.ContentType,
+		})
+	}
+	return summaries
+}
+
+// printFiles lists attachments in an approval prompt
+func printFiles(files []*discord.File) {
+	for _, f := range files {
+		fmt.Printf("   📎 %s (%s)\n", f.Name, formatSize(len(f.Data)))
+	}
+	if len(files) > 0 {
+		fmt.Println()
+	}
+}
+
+// formatSize renders a byte count for humans
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
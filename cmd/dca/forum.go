@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/ulfschnabel/dca/internal/config"
@@ -12,40 +13,109 @@ import (
 var forumCmd = &cobra.Command{
 	Use:   "forum",
 	Short: "Forum channel operations",
-	Long:  "List threads and read messages from forum channels",
+	Long:  "List, read and create threads in forum channels",
 }
 
 var forumThreadsCmd = &cobra.Command{
-	Use:   "threads <channel-id>",
+	Use:   "threads <channel>",
 	Short: "List forum threads",
-	Long:  "List active threads in a forum channel",
+	Long:  "List active threads in a forum channel, given by ID, link or \"server/#forum\" path, optionally only those with a --tag",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runForumThreads,
 }
 
 var forumMessagesCmd = &cobra.Command{
-	Use:   "messages <thread-id>",
+	Use:   "messages <thread>",
 	Short: "Get messages from a thread",
-	Long:  "Get messages from a specific forum thread",
+	Long:  "Get messages from a specific forum thread, given by ID or link",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runForumMessages,
 }
 
+var forumTagsCmd = &cobra.Command{
+	Use:   "tags <channel>",
+	Short: "List forum tags",
+	Long:  "List the tags posts in a forum channel can be filed under",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runForumTags,
+}
+
+var forumPostCmd = &cobra.Command{
+	Use:   "post <channel> [message] --title <title>",
+	Short: "Create a forum post",
+	Long: `Create a thread in a forum channel with a starter message and tags
+(requires approval unless --dry-run).
+
+Tags are given by name or ID with --tag; see forum tags for the choices.
+The message takes the same --file, --embed-*, --content-file and mention
+flags as message send. Text over 2000 characters continues in the thread.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runForumPost,
+}
+
 func init() {
 	rootCmd.AddCommand(forumCmd)
 	forumCmd.AddCommand(forumThreadsCmd)
 	forumCmd.AddCommand(forumMessagesCmd)
+	forumCmd.AddCommand(forumTagsCmd)
+	forumCmd.AddCommand(forumPostCmd)
 
-	forumThreadsCmd.Flags().Int("limit", 20, "Number of threads to show")
+	forumThreadsCmd.Flags().Int("limit", 20, "Number of threads to show (0 for all)")
 	forumThreadsCmd.Flags().Bool("active-only", true, "Only show active (non-archived) threads")
-	forumMessagesCmd.Flags().Int("limit", 10, "Number of messages to retrieve")
+	forumThreadsCmd.Flags().StringArray("tag", nil, "Only show threads with this tag, by name or ID (repeatable, matches any)")
+	addHistoryFlags(forumMessagesCmd, 10)
+
+	forumPostCmd.Flags().String("title", "", "Title of the post (required)")
+	forumPostCmd.Flags().StringArray("tag", nil, "Apply a tag, by name or ID (repeatable, up to 5)")
+	forumPostCmd.Flags().String("auto-archive", "", "Archive after this long without activity: 1h, 1d, 3d or 1w (default from the forum)")
+	forumPostCmd.Flags().Bool("dry-run", false, "Show what would be posted without actually posting")
+	addFileFlags(forumPostCmd)
+	addEmbedFlags(forumPostCmd)
+	addContentFlags(forumPostCmd)
+	addMentionFlags(forumPostCmd, false)
+	addNotificationFlags(forumPostCmd)
+}
+
+// forumTags resolves --tag names or IDs against a forum's tags, dropping
+// repeats
+func forumTags(forum *discord.Forum, refs []string) ([]*discord.ForumTag, error) {
+	var tags []*discord.ForumTag
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		tag, err := forum.Tag(ref)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func tagIDs(tags []*discord.ForumTag) []string {
+	ids := make([]string, 0, len(tags))
+	for _, t := range tags {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func tagNames(tags []*discord.ForumTag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
 }
 
 func runForumThreads(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
 	limit, _ := cmd.Flags().GetInt("limit")
 	activeOnly, _ := cmd.Flags().GetBool("active-only")
-	channelID := args[0]
+	tagRefs, _ := cmd.Flags().GetStringArray("tag")
+	channelRef := args[0]
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -64,14 +134,33 @@ func runForumThreads(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
+	channelID, err := newResolver(client).channel(channelRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	opts := discord.ForumThreadOptions{Limit: limit, ActiveOnly: activeOnly}
+	if len(tagRefs) > 0 {
+		forum, err := client.GetForum(channelID)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		tags, err := forumTags(forum, tagRefs)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		opts.TagIDs = tagIDs(tags)
+		opts.Forum = forum
+	}
+
 	// List forum threads
-	threads, err := client.ListForumThreads(channelID, limit, activeOnly)
+	threads, err := client.ListForumThreads(channelID, opts)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
@@ -84,8 +173,11 @@ func runForumThreads(cmd *cobra.Command, args []string) error {
 
 func runForumMessages(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
-	limit, _ := cmd.Flags().GetInt("limit")
-	threadID := args[0]
+	opts, err := historyOptions(cmd)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	threadRef := args[0]
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -104,20 +196,197 @@ func runForumMessages(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
+	threadID, err := newResolver(client).channel(threadRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
 	// Get thread messages
-	messages, err := client.GetThreadMessages(threadID, limit)
+	page, err := client.GetThreadMessages(threadID, opts)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 
+	compactMessages(cmd, page.Messages...)
+
 	return output.PrintSuccess(map[string]interface{}{
-		"messages": messages,
-		"count":    len(messages),
+		"messages":    page.Messages,
+		"count":       len(page.Messages),
+		"next_cursor": page.NextCursor,
 	}, pretty)
 }
+
+func runForumTags(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	channelRef := args[0]
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	channelID, err := newResolver(client).channel(channelRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	forum, err := client.GetForum(channelID)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	return output.PrintSuccess(map[string]interface{}{
+		"channel_id":  forum.ID,
+		"name":        forum.Name,
+		"tags":        forum.Tags,
+		"count":       len(forum.Tags),
+		"require_tag": forum.RequireTag,
+	}, pretty)
+}
+
+func runForumPost(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	title, _ := cmd.Flags().GetString("title")
+	tagRefs, _ := cmd.Flags().GetStringArray("tag")
+	autoArchiveFlag, _ := cmd.Flags().GetString("auto-archive")
+	channelRef := args[0]
+	content := ""
+	if len(args) > 1 {
+		content = args[1]
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return output.PrintError(fmt.Errorf("--title is required"), pretty)
+	}
+	if n := len([]rune(title)); n > 100 {
+		return output.PrintError(fmt.Errorf("title is %d characters, the limit is 100", n), pretty)
+	}
+	autoArchive, err := parseAutoArchive(autoArchiveFlag)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Read the message before connecting so missing files fail fast
+	flow, err := newSendFlow(cmd, cfg, content)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	channelID, err := newResolver(client).channel(channelRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Check the tags against the forum before posting
+	forum, err := client.GetForum(channelID)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	tags, err := forumTags(forum, tagRefs)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	if len(tags) > discord.MaxAppliedTags {
+		return output.PrintError(fmt.Errorf("at most %d tags can be applied, got %d", discord.MaxAppliedTags, len(tags)), pretty)
+	}
+	if forum.RequireTag && len(tags) == 0 {
+		return output.PrintError(fmt.Errorf("%s requires at least one --tag, see forum tags", forum.Name), pretty)
+	}
+	for _, t := range tags {
+		if t.Moderated {
+			flow.warnings = append(flow.warnings, fmt.Sprintf("tag %q can only be applied by moderators", t.Name))
+		}
+	}
+
+	flow.action = "create_forum_post"
+	flow.header = fmt.Sprintf("📌 Create post %q in forum %s", title, forum.Name)
+	if len(tags) > 0 {
+		flow.header += " tagged " + strings.Join(tagNames(tags), ", ")
+	}
+	flow.header += ":"
+	flow.details = map[string]interface{}{
+		"channel_id":            channelID,
+		"title":                 title,
+		"tags":                  tagNames(tags),
+		"auto_archive_duration": autoArchive,
+	}
+	flow.channelID = channelID
+
+	// Create the post; the rest of a split message follows in the thread
+	var thread *discord.Thread
+	flow.send = func(i int, part *discord.OutgoingMessage) (*discord.Message, error) {
+		if i == 0 {
+			t, msg, err := client.CreateForumPost(channelID, &discord.ForumPost{
+				Title:               title,
+				TagIDs:              tagIDs(tags),
+				AutoArchiveDuration: autoArchive,
+				Message:             part,
+			})
+			thread = t
+			return msg, err
+		}
+		return client.SendMessage(thread.ID, part)
+	}
+	flow.result = func(msgs []*discord.Message) interface{} {
+		result := map[string]interface{}{
+			"thread": thread,
+			"tags":   tagNames(tags),
+		}
+		if len(msgs) == 1 {
+			result["message"] = msgs[0]
+		} else {
+			result["messages"] = msgs
+			result["message_ids"] = messageIDs(msgs)
+		}
+		return result
+	}
+	return flow.run(client, pretty)
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+	"github.com/ulfschnabel/dca/internal/snowflake"
+)
+
+func TestForumThreads(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "forum", "threads", fake.ChannelForum, "--active-only=false")
+
+	var data struct {
+		Threads []*discord.ForumThread `json:"threads"`
+		Count   int                    `json:"count"`
+	}
+	resp.decode(t, &data)
+	if data.Count != 2 || data.Threads[0].ID != fake.ThreadActive || data.Threads[1].ID != fake.ThreadArchived {
+		t.Fatalf("expected both threads, most recently active first, got %+v", data.Threads)
+	}
+	if !data.Threads[1].Archived || data.Threads[1].Name != "Build is broken" {
+		t.Errorf("unexpected thread: %+v", data.Threads[1])
+	}
+
+	data.Threads, data.Count = nil, 0
+	env.mustRun(t, "forum", "threads", fake.ChannelForum).decode(t, &data)
+	if data.Count != 1 || data.Threads[0].ID != fake.ThreadActive || data.Threads[0].Archived {
+		t.Errorf("expected only the active thread, got %+v", data.Threads)
+	}
+}
+
+func TestForumThreadsAuthorAndActivity(t *testing.T) {
+	env := newTestEnv(t)
+
+	var data struct {
+		Threads []*discord.ForumThread `json:"threads"`
+	}
+	env.mustRun(t, "forum", "threads", fake.ChannelForum, "--active-only=false").decode(t, &data)
+	if len(data.Threads) != 2 {
+		t.Fatalf("expected 2 threads, got %+v", data.Threads)
+	}
+
+	active := data.Threads[0]
+	if active.Author == nil || active.Author.ID != fake.UserAlice || active.Author.DisplayName != "Alice (ops)" {
+		t.Errorf("expected alice with her server nickname as the author, got %+v", active.Author)
+	}
+	last := env.srv.LastMessage(fake.ThreadActive)
+	if active.LastActivityAt != snowflake.Timestamp(last.ID) {
+		t.Errorf("expected last activity at %s, got %q", snowflake.Timestamp(last.ID), active.LastActivityAt)
+	}
+	if data.Threads[1].Author == nil || data.Threads[1].Author.ID != fake.UserBob {
+		t.Errorf("expected bob as the archived thread's author, got %+v", data.Threads[1].Author)
+	}
+}
+
+func TestForumThreadsPagination(t *testing.T) {
+	env := newTestEnv(t)
+
+	// More active threads than one search page and more archived threads
+	// than one archived page
+	for i := 0; i < 30; i++ {
+		env.srv.AddChannel(&discordgo.Channel{
+			ID: strconv.Itoa(10000 + i), GuildID: fake.GuildID, ParentID: fake.ChannelForum,
+			Name: fmt.Sprintf("active %d", i), Type: discordgo.ChannelTypeGuildPublicThread,
+			ThreadMetadata: &discordgo.ThreadMetadata{},
+		})
+	}
+	for i := 0; i < 120; i++ {
+		env.srv.AddChannel(&discordgo.Channel{
+			ID: strconv.Itoa(20000 + i), GuildID: fake.GuildID, ParentID: fake.ChannelForum,
+			Name: fmt.Sprintf("archived %d", i), Type: discordgo.ChannelTypeGuildPublicThread,
+			ThreadMetadata: &discordgo.ThreadMetadata{Archived: true, ArchiveTimestamp: fake.SeedTime.Add(time.Duration(i) * time.Hour)},
+		})
+	}
+
+	var data struct {
+		Threads []*discord.ForumThread `json:"threads"`
+		Count   int                    `json:"count"`
+	}
+	env.mustRun(t, "forum", "threads", fake.ChannelForum, "--active-only=false", "--limit", "0").decode(t, &data)
+	if data.Count != 152 {
+		t.Fatalf("expected all 152 threads, got %d", data.Count)
+	}
+	seen := make(map[string]bool)
+	for _, th := range data.Threads {
+		if seen[th.ID] {
+			t.Fatalf("thread %s listed twice", th.ID)
+		}
+		seen[th.ID] = true
+	}
+
+	data.Threads, data.Count = nil, 0
+	env.mustRun(t, "forum", "threads", fake.ChannelForum, "--limit", "0").decode(t, &data)
+	if data.Count != 31 {
+		t.Errorf("expected all 31 active threads, got %d", data.Count)
+	}
+
+	data.Threads, data.Count = nil, 0
+	env.mustRun(t, "forum", "threads", fake.ChannelForum, "--active-only=false", "--limit", "40").decode(t, &data)
+	if data.Count != 40 || data.Threads[0].ID != fake.ThreadActive {
+		t.Errorf("expected the 40 most recently active threads, got %d starting at %+v", data.Count, data.Threads[0])
+	}
+}
+
+func TestForumMessages(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "forum", "messages", fake.ThreadActive)
+
+	var data struct {
+		Messages []*discord.Message `json:"messages"`
+		Count    int                `json:"count"`
+	}
+	resp.decode(t, &data)
+	if data.Count != 2 {
+		t.Fatalf("expected 2 messages, got %d", data.Count)
+	}
+	if data.Messages[0].Content != "Run make deploy-staging" {
+		t.Errorf("expected newest message first, got %q", data.Messages[0].Content)
+	}
+}
+
+func TestForumMessagesUnknownThread(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp, _ := env.run(t, "forum", "messages", "999")
+	if resp.OK {
+		t.Fatal("expected an unknown thread to fail")
+	}
+}
+
+func TestForumThreadCreatedAt(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "forum", "threads", fake.ChannelForum, "--active-only=false")
+	var data struct {
+		Threads []*discord.ForumThread `json:"threads"`
+	}
+	resp.decode(t, &data)
+	if len(data.Threads) == 0 || data.Threads[0].CreatedAt != snowflake.Timestamp(data.Threads[0].ID) {
+		t.Errorf("expected created_at from the thread ID, got %+v", data.Threads)
+	}
+}
+
+func TestForumThreadsTagFilter(t *testing.T) {
+	env := newTestEnv(t)
+
+	var data struct {
+		Threads []*discord.ForumThread `json:"threads"`
+		Count   int                    `json:"count"`
+	}
+	env.mustRun(t, "forum", "threads", fake.ChannelForum, "--active-only=false")
+	untagged := env.srv.Requests("GET /channels/" + fake.ChannelForum)
+	env.mustRun(t, "forum", "threads", fake.ChannelForum, "--active-only=false", "--tag", "BUG").decode(t, &data)
+	if data.Count != 1 || data.Threads[0].ID != fake.ThreadArchived || strings.Join(data.Threads[0].Tags, ",") != "bug" {
+		t.Errorf("expected the bug thread with its tag, got %+v", data.Threads)
+	}
+	// Resolving the tags needs no extra fetch of the forum
+	if got := env.srv.Requests("GET /channels/"+fake.ChannelForum) - untagged; got != untagged {
+		t.Errorf("expected %d forum fetches with --tag, got %d", untagged, got)
+	}
+
+	data.Threads, data.Count = nil, 0
+	env.mustRun(t, "forum", "threads", fake.ChannelForum, "--active-only=false", "--tag", "question").decode(t, &data)
+	if data.Count != 1 || data.Threads[0].ID != fake.ThreadActive {
+		t.Errorf("expected the active question thread, got %+v", data.Threads)
+	}
+
+	resp, _ := env.run(t, "forum", "threads", fake.ChannelForum, "--tag", "feature")
+	if resp.OK || !strings.Contains(resp.Error, "available: question, bug, solved") {
+		t.Errorf("expected an unknown tag error listing the tags, got %+v", resp)
+	}
+}
+
+func TestForumTags(t *testing.T) {
+	env := newTestEnv(t)
+
+	var data struct {
+		Tags       []*discord.ForumTag `json:"tags"`
+		Count      int                 `json:"count"`
+		RequireTag bool                `json:"require_tag"`
+	}
+	env.mustRun(t, "forum", "tags", fake.ChannelForum).decode(t, &data)
+	if data.Count != 3 || data.Tags[0].Name != "question" || data.Tags[0].Emoji != "❓" || !data.Tags[2].Moderated {
+		t.Errorf("unexpected tags: %+v", data.Tags)
+	}
+
+	resp, _ := env.run(t, "forum", "tags", fake.ChannelGeneral)
+	if resp.OK || !strings.Contains(resp.Error, "not a forum") {
+		t.Errorf("expected a not a forum error, got %+v", resp)
+	}
+}
+
+type forumPostData struct {
+	Thread  *discord.Thread  `json:"thread"`
+	Message *discord.Message `json:"message"`
+	Tags    []string         `json:"tags"`
+}
+
+func TestForumPost(t *testing.T) {
+	env := newTestEnv(t)
+	log := writeTempFile(t, "crash.log", "panic: boom\n")
+
+	resp := env.mustRun(t, "forum", "post", fake.ChannelForum, "Deploys fail since this morning",
+		"--title", "Deploy broken", "--tag", "bug", "--tag", fake.TagQuestion, "--file", log)
+	var data forumPostData
+	resp.decode(t, &data)
+	if data.Thread.Name != "Deploy broken" || data.Thread.ParentID != fake.ChannelForum {
+		t.Fatalf("unexpected thread: %+v", data.Thread)
+	}
+	if strings.Join(data.Tags, ",") != "bug,question" || strings.Join(data.Thread.AppliedTags, ",") != fake.TagBug+","+fake.TagQuestion {
+		t.Errorf("expected bug and question tags, got %v %v", data.Tags, data.Thread.AppliedTags)
+	}
+	if data.Message.ID != data.Thread.ID || data.Message.Content != "Deploys fail since this morning" || len(data.Message.Attachments) != 1 {
+		t.Errorf("unexpected starter message: %+v", data.Message)
+	}
+	if got := env.srv.Messages(data.Thread.ID); len(got) != 1 {
+		t.Errorf("expected the starter message in the thread, got %d messages", len(got))
+	}
+}
+
+func TestForumPostValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp, _ := env.run(t, "forum", "post", fake.ChannelForum, "text")
+	if resp.OK || !strings.Contains(resp.Error, "--title") {
+		t.Errorf("expected a missing title error, got %+v", resp)
+	}
+
+	resp, _ = env.run(t, "forum", "post", fake.ChannelGeneral, "text", "--title", "Hi")
+	if resp.OK || !strings.Contains(resp.Error, "not a forum") {
+		t.Errorf("expected a not a forum error, got %+v", resp)
+	}
+
+	env.srv.Channel(fake.ChannelForum).Flags |= discordgo.ChannelFlagRequireTag
+	resp, _ = env.run(t, "forum", "post", fake.ChannelForum, "text", "--title", "Hi")
+	if resp.OK || !strings.Contains(resp.Error, "requires at least one --tag") {
+		t.Errorf("expected a missing tag error, got %+v", resp)
+	}
+
+	resp = env.mustRun(t, "forum", "post", fake.ChannelForum, "text", "--title", "Hi", "--tag", "solved", "--dry-run")
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["dry_run"] != true || len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "moderators") {
+		t.Errorf("expected a dry run with a moderated tag warning, got %v %v", data, resp.Warnings)
+	}
+}
+
+func TestForumPostApproval(t *testing.T) {
+	env := newTestEnv(t)
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	env.stdin = "n\n"
+
+	resp, stdout := env.run(t, "forum", "post", fake.ChannelForum, "How do I rotate keys?", "--title", "Key rotation", "--tag", "question")
+	if !strings.Contains(stdout, `Create post "Key rotation" in forum help tagged question`) {
+		t.Errorf("expected an approval prompt, got %q", stdout)
+	}
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["cancelled"] != true {
+		t.Errorf("expected the post to be cancelled, got %v", data)
+	}
+}
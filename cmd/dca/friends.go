@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/output"
+)
+
+var friendsCmd = &cobra.Command{
+	Use:   "friends",
+	Short: "Friend operations",
+	Long:  "List friends and friend requests, and add, accept, remove or block users",
+}
+
+var friendsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List friends",
+	Long: `List your friends with the private nickname you gave them.
+
+With --blocked, list blocked users instead.`,
+	Args: cobra.NoArgs,
+	RunE: runFriendsList,
+}
+
+var friendsRequestsCmd = &cobra.Command{
+	Use:   "requests",
+	Short: "List pending friend requests",
+	Long: `List pending friend requests, both those sent to you (pending_incoming)
+and those you sent (pending_outgoing).`,
+	Args: cobra.NoArgs,
+	RunE: runFriendsRequests,
+}
+
+var friendsAddCmd = &cobra.Command{
+	Use:   "add <username | user-id | mention>",
+	Short: "Send a friend request",
+	Long: `Send a friend request to a user (requires approval unless --dry-run).
+
+A username is sent to Discord as is, so it also reaches users you share
+nothing with. Adding a user who sent you a request accepts it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFriendAction(friendAction{
+		name:   "add_friend",
+		prompt: "➕ Send friend request to %s",
+		apply: func(client discord.API, target *discord.Friend) error {
+			if target.ID == "" {
+				return client.SendFriendRequest(target.Username)
+			}
+			return client.AddFriend(target.ID)
+		},
+		target: func(client discord.API, ref string) (*discord.Friend, error) {
+			if id, ok := userID(ref); ok {
+				return &discord.Friend{Author: discord.Author{ID: id}}, nil
+			}
+			return &discord.Friend{Author: discord.Author{Username: strings.TrimPrefix(ref, "@")}}, nil
+		},
+	}),
+}
+
+var friendsAcceptCmd = &cobra.Command{
+	Use:   "accept <user>",
+	Short: "Accept a friend request",
+	Long: `Accept a pending friend request (requires approval unless --dry-run).
+
+The user may be an ID, a mention, a username or a display name.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFriendAction(friendAction{
+		name:   "accept_friend",
+		prompt: "🤝 Accept friend request from %s",
+		apply: func(client discord.API, target *discord.Friend) error {
+			return client.AddFriend(target.ID)
+		},
+		target: func(client discord.API, ref string) (*discord.Friend, error) {
+			return findRelationship(client, ref, discord.RelationshipPendingIncoming)
+		},
+	}),
+}
+
+var friendsRemoveCmd = &cobra.Command{
+	Use:   "remove <user>",
+	Short: "Remove a friend",
+	Long: `Remove a friend, cancel or decline a friend request, or unblock a user
+(requires approval unless --dry-run).
+
+The user may be an ID, a mention, a username, a display name or the
+nickname you gave a friend.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFriendAction(friendAction{
+		name:   "remove_friend",
+		prompt: "➖ Remove %s from your relationships",
+		apply: func(client discord.API, target *discord.Friend) error {
+			return client.RemoveRelationship(target.ID)
+		},
+		target: func(client discord.API, ref string) (*discord.Friend, error) {
+			return findRelationship(client, ref)
+		},
+	}),
+}
+
+var friendsBlockCmd = &cobra.Command{
+	Use:   "block <user>",
+	Short: "Block a user",
+	Long: `Block a user, ending any friendship or pending request (requires
+approval unless --dry-run).
+
+The user may be an ID, a mention or a username.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFriendAction(friendAction{
+		name:   "block_user",
+		prompt: "🚫 Block %s",
+		apply: func(client discord.API, target *discord.Friend) error {
+			return client.BlockUser(target.ID)
+		},
+		target: func(client discord.API, ref string) (*discord.Friend, error) {
+			if f, err := findRelationship(client, ref); err == nil {
+				return f, nil
+			}
+			user, err := newResolver(client).user(ref)
+			if err != nil {
+				return nil, err
+			}
+			return &discord.Friend{Author: *user}, nil
+		},
+	}),
+}
+
+func init() {
+	rootCmd.AddCommand(friendsCmd)
+	friendsCmd.AddCommand(friendsListCmd)
+	friendsCmd.AddCommand(friendsRequestsCmd)
+	friendsCmd.AddCommand(friendsAddCmd)
+	friendsCmd.AddCommand(friendsAcceptCmd)
+	friendsCmd.AddCommand(friendsRemoveCmd)
+	friendsCmd.AddCommand(friendsBlockCmd)
+
+	friendsListCmd.Flags().Bool("blocked", false, "List blocked users instead of friends")
+	friendsAddCmd.Flags().Bool("dry-run", false, "Show who would be sent a request without sending it")
+	friendsAcceptCmd.Flags().Bool("dry-run", false, "Show whose request would be accepted without accepting it")
+	friendsRemoveCmd.Flags().Bool("dry-run", false, "Show who would be removed without removing them")
+	friendsBlockCmd.Flags().Bool("dry-run", false, "Show who would be blocked without blocking them")
+}
+
+func runFriendsList(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	blocked, _ := cmd.Flags().GetBool("blocked")
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	kind, key := discord.RelationshipFriend, "friends"
+	if blocked {
+		kind, key = discord.RelationshipBlocked, "blocked"
+	}
+	friends, err := listRelationships(client, kind)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	return output.PrintSuccess(map[string]interface{}{
+		key:     friends,
+		"count": len(friends),
+	}, pretty)
+}
+
+func runFriendsRequests(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	requests, err := listRelationships(client, discord.RelationshipPendingIncoming, discord.RelationshipPendingOutgoing)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	return output.PrintSuccess(map[string]interface{}{
+		"requests": requests,
+		"count":    len(requests),
+	}, pretty)
+}
+
+// listRelationships lists the relationships of the given kinds
+func listRelationships(client discord.API, kinds ...string) ([]*discord.Friend, error) {
+	rels, err := client.ListRelationships()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*discord.Friend, 0, len(rels))
+	for _, f := range rels {
+		for _, k := range kinds {
+			if f.Relationship == k {
+				result = append(result, f)
+			}
+		}
+	}
+	return result, nil
+}
+
+// findRelationship resolves a user among the current user's relationships,
+// of the given kinds if any, by ID, mention, username, display name or
+// friend nickname
+func findRelationship(client discord.API, ref string, kinds ...string) (*discord.Friend, error) {
+	if len(kinds) == 0 {
+		kinds = []string{
+			discord.RelationshipFriend,
+			discord.RelationshipBlocked,
+			discord.RelationshipPendingIncoming,
+			discord.RelationshipPendingOutgoing,
+		}
+	}
+	rels, err := listRelationships(client, kinds...)
+	if err != nil {
+		return nil, err
+	}
+
+	id, byID := userID(ref)
+	name := strings.TrimPrefix(strings.TrimSpace(ref), "@")
+	var matches []*discord.Friend
+	for _, f := range rels {
+		if byID && f.ID == id {
+			return f, nil
+		}
+		if strings.EqualFold(f.Username, name) {
+			return f, nil
+		}
+		if strings.EqualFold(f.GlobalName, name) || strings.EqualFold(f.Nickname, name) {
+			matches = append(matches, f)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		if len(kinds) == 1 && kinds[0] == discord.RelationshipPendingIncoming {
+			return nil, fmt.Errorf("no friend request from %q", ref)
+		}
+		return nil, fmt.Errorf("%q is not a friend, blocked or a pending request", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, 0, len(matches))
+	for _, f := range matches {
+		names = append(names, fmt.Sprintf("@%s (%s)", f.Username, f.ID))
+	}
+	return nil, ambiguous("user", ref, names)
+}
+
+// friendAction is a relationship change made by a friends subcommand.
+// target resolves the user the change applies to; a target without an ID
+// is only known by username.
+type friendAction struct {
+	name   string
+	prompt string
+	apply  func(client discord.API, target *discord.Friend) error
+	target func(client discord.API, ref string) (*discord.Friend, error)
+}
+
+// runFriendAction returns the RunE of a relationship-changing command
+func runFriendAction(action friendAction) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		pretty, _ := cmd.Flags().GetBool("output-pretty")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		userRef := args[0]
+
+		// Load config
+		cfg, err := config.Load(cfgFile)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		// Get token
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = cfg.UserToken
+		}
+
+		if token == "" {
+			return output.PrintError(fmt.Errorf("no token configured"), pretty)
+		}
+
+		// Create Discord client
+		client, err := newClient(cmd, cfg, token)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		defer client.Close()
+
+		target, err := action.target(client, userRef)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		// Dry run
+		if dryRun {
+			return output.PrintSuccess(map[string]interface{}{
+				"action":  action.name,
+				"user":    target,
+				"dry_run": true,
+			}, pretty)
+		}
+
+		// Check approval requirement
+		if cfg.RequireApproval {
+			fmt.Printf(action.prompt+"\n\n", describeUser(&target.Author))
+			fmt.Print("Proceed? [y/N]: ")
+
+			reader := bufio.NewReader(os.Stdin)
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
+			}
+
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				return output.PrintSuccess(map[string]interface{}{
+					"action":    action.name,
+					"cancelled": true,
+				}, pretty)
+			}
+		}
+
+		previous := target.Relationship
+		if err := action.apply(client, target); err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		// Report the relationship as it is now
+		target.Relationship = ""
+		target.Nickname = ""
+		target.Since = ""
+		if rels, err := client.ListRelationships(); err == nil {
+			for _, f := range rels {
+				if f.ID == target.ID || (target.ID == "" && strings.EqualFold(f.Username, target.Username)) {
+					target = f
+				}
+			}
+		}
+
+		result := map[string]interface{}{
+			"action": action.name,
+			"user":   target,
+		}
+		if previous != "" {
+			result["previous_relationship"] = previous
+		}
+		return output.PrintSuccess(result, pretty)
+	}
+}
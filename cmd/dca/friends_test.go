@@ -0,0 +1,204 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+type friendsData struct {
+	Friends  []*discord.Friend `json:"friends"`
+	Blocked  []*discord.Friend `json:"blocked"`
+	Requests []*discord.Friend `json:"requests"`
+	Count    int               `json:"count"`
+}
+
+type friendActionData struct {
+	Action               string          `json:"action"`
+	User                 *discord.Friend `json:"user"`
+	PreviousRelationship string          `json:"previous_relationship"`
+	DryRun               bool            `json:"dry_run"`
+	Cancelled            bool            `json:"cancelled"`
+}
+
+// addStranger adds a user who shares nothing with the current user
+func addStranger(env *testEnv, id, username string) {
+	env.srv.AddUser(&discordgo.User{ID: id, Username: username})
+}
+
+func TestFriendsList(t *testing.T) {
+	env := newTestEnv(t)
+	env.srv.SetFriendNickname(fake.UserAlice, "Al")
+	env.srv.AddRelationship(fake.UserBob, fake.RelationshipBlocked)
+
+	var data friendsData
+	env.mustRun(t, "friends", "list").decode(t, &data)
+	if data.Count != 1 || data.Friends[0].ID != fake.UserAlice {
+		t.Fatalf("expected alice as the only friend, got %+v", data.Friends)
+	}
+	alice := data.Friends[0]
+	if alice.Username != "alice" || alice.Relationship != discord.RelationshipFriend || alice.Nickname != "Al" || alice.Since == "" {
+		t.Errorf("unexpected friend: %+v", alice)
+	}
+
+	data = friendsData{}
+	env.mustRun(t, "friends", "list", "--blocked").decode(t, &data)
+	if data.Count != 1 || data.Blocked[0].ID != fake.UserBob || data.Blocked[0].Relationship != discord.RelationshipBlocked {
+		t.Errorf("expected bob as blocked, got %+v", data.Blocked)
+	}
+}
+
+func TestFriendsRequests(t *testing.T) {
+	env := newTestEnv(t)
+	addStranger(env, "103", "carol")
+	env.srv.AddRelationship("103", fake.RelationshipPendingIncoming)
+	env.srv.AddRelationship(fake.UserBob, fake.RelationshipPendingOutgoing)
+
+	var data friendsData
+	env.mustRun(t, "friends", "requests").decode(t, &data)
+	if data.Count != 2 {
+		t.Fatalf("expected two requests, got %+v", data.Requests)
+	}
+	kinds := make(map[string]string)
+	for _, f := range data.Requests {
+		kinds[f.ID] = f.Relationship
+	}
+	if kinds["103"] != discord.RelationshipPendingIncoming || kinds[fake.UserBob] != discord.RelationshipPendingOutgoing {
+		t.Errorf("unexpected requests: %v", kinds)
+	}
+}
+
+func TestFriendsAdd(t *testing.T) {
+	env := newTestEnv(t)
+	addStranger(env, "103", "carol")
+	addStranger(env, "104", "dave")
+
+	// By username, reaching a user who shares nothing with us
+	var data friendActionData
+	env.mustRun(t, "friends", "add", "carol").decode(t, &data)
+	if data.Action != "add_friend" || data.User.ID != "103" || data.User.Relationship != discord.RelationshipPendingOutgoing {
+		t.Errorf("expected an outgoing request to carol, got %+v", data.User)
+	}
+	if env.srv.Relationship("103") != fake.RelationshipPendingOutgoing {
+		t.Errorf("request not stored, relationship is %d", env.srv.Relationship("103"))
+	}
+
+	// By mention
+	env.mustRun(t, "friends", "add", "<@104>")
+	if env.srv.Relationship("104") != fake.RelationshipPendingOutgoing {
+		t.Errorf("expected a request to dave, relationship is %d", env.srv.Relationship("104"))
+	}
+
+	resp, _ := env.run(t, "friends", "add", "nobody")
+	if resp.OK {
+		t.Error("expected an unknown username to fail")
+	}
+}
+
+func TestFriendsAccept(t *testing.T) {
+	env := newTestEnv(t)
+	addStranger(env, "103", "carol")
+	env.srv.AddRelationship("103", fake.RelationshipPendingIncoming)
+
+	resp, _ := env.run(t, "friends", "accept", "bob")
+	if resp.OK || !strings.Contains(resp.Error, `no friend request from "bob"`) {
+		t.Errorf("expected no request from bob, got %+v", resp)
+	}
+
+	var data friendActionData
+	env.mustRun(t, "friends", "accept", "@carol").decode(t, &data)
+	if data.Action != "accept_friend" || data.PreviousRelationship != discord.RelationshipPendingIncoming || data.User.Relationship != discord.RelationshipFriend {
+		t.Errorf("expected carol to become a friend, got %+v", data)
+	}
+	if env.srv.Relationship("103") != fake.RelationshipFriend {
+		t.Errorf("request not accepted, relationship is %d", env.srv.Relationship("103"))
+	}
+}
+
+func TestFriendsRemove(t *testing.T) {
+	env := newTestEnv(t)
+	env.srv.SetFriendNickname(fake.UserAlice, "Al")
+
+	// By the nickname given to her
+	var data friendActionData
+	env.mustRun(t, "friends", "remove", "Al").decode(t, &data)
+	if data.Action != "remove_friend" || data.User.ID != fake.UserAlice || data.PreviousRelationship != discord.RelationshipFriend || data.User.Relationship != "" {
+		t.Errorf("expected alice removed, got %+v", data)
+	}
+	if env.srv.Relationship(fake.UserAlice) != 0 {
+		t.Errorf("friend not removed, relationship is %d", env.srv.Relationship(fake.UserAlice))
+	}
+
+	resp, _ := env.run(t, "friends", "remove", "alice")
+	if resp.OK {
+		t.Error("expected removing a non-friend to fail")
+	}
+}
+
+func TestFriendsBlock(t *testing.T) {
+	env := newTestEnv(t)
+
+	var data friendActionData
+	env.mustRun(t, "friends", "block", "bob").decode(t, &data)
+	if data.Action != "block_user" || data.User.ID != fake.UserBob || data.User.Relationship != discord.RelationshipBlocked {
+		t.Errorf("expected bob blocked, got %+v", data)
+	}
+	if env.srv.Relationship(fake.UserBob) != fake.RelationshipBlocked {
+		t.Errorf("user not blocked, relationship is %d", env.srv.Relationship(fake.UserBob))
+	}
+
+	// Blocking a friend ends the friendship
+	data = friendActionData{}
+	env.mustRun(t, "friends", "block", fake.UserAlice).decode(t, &data)
+	if data.PreviousRelationship != discord.RelationshipFriend || env.srv.Relationship(fake.UserAlice) != fake.RelationshipBlocked {
+		t.Errorf("expected alice blocked, got %+v", data)
+	}
+}
+
+func TestFriendsApproval(t *testing.T) {
+	env := newTestEnv(t)
+
+	var data friendActionData
+	env.mustRun(t, "friends", "remove", "alice", "--dry-run").decode(t, &data)
+	if !data.DryRun || data.User.ID != fake.UserAlice {
+		t.Errorf("unexpected dry-run output: %+v", data)
+	}
+	if env.srv.Relationship(fake.UserAlice) != fake.RelationshipFriend {
+		t.Fatal("dry run removed the friend")
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	env.stdin = "n\n"
+	resp, stdout := env.run(t, "friends", "block", "alice")
+	if !strings.Contains(stdout, "Block @alice ("+fake.UserAlice+")") {
+		t.Errorf("expected an approval prompt, got %q", stdout)
+	}
+	data = friendActionData{}
+	resp.decode(t, &data)
+	if !data.Cancelled || env.srv.Relationship(fake.UserAlice) != fake.RelationshipFriend {
+		t.Errorf("expected the block to be cancelled, got %+v", data)
+	}
+}
+
+func TestDMSendByFriendNickname(t *testing.T) {
+	env := newTestEnv(t)
+	addStranger(env, "103", "carol")
+	env.srv.AddRelationship("103", fake.RelationshipFriend)
+	env.srv.SetFriendNickname("103", "Caz")
+
+	// Carol shares no server or DM; only the friend list knows her
+	var msg discord.Message
+	env.mustRun(t, "dm", "send", "Caz", "hi").decode(t, &msg)
+	if msg.ChannelID == "" || msg.Content != "hi" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	var info discord.UserInfo
+	env.mustRun(t, "users", "info", "carol").decode(t, &info)
+	if info.DMChannelID != msg.ChannelID {
+		t.Errorf("expected the DM with carol, got %q and %q", msg.ChannelID, info.DMChannelID)
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/output"
+)
+
+var dmGroupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Group DM operations",
+	Long: `Create group DMs, add or remove people and rename them.
+
+A group may be given by channel ID, link or name.`,
+}
+
+var dmGroupCreateCmd = &cobra.Command{
+	Use:   "create <user> [user...]",
+	Short: "Create a group DM",
+	Long:  "Start a group DM with one or more users, optionally named with --name (requires approval unless --dry-run)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runDMGroupCreate,
+}
+
+var dmGroupAddCmd = &cobra.Command{
+	Use:   "add <group> <user>",
+	Short: "Add a user to a group DM",
+	Long:  "Add a user to a group DM (requires approval unless --dry-run)",
+	Args:  cobra.ExactArgs(2),
+	RunE: runGroupDMAction(groupDMAction{
+		name:   "add_group_recipient",
+		prompt: "➕ Add %s to group DM %s",
+		key:    "user_id",
+		target: func(r *resolver, group *discord.GroupDM, ref string) (string, string, []string, error) {
+			user, err := r.user(ref)
+			if err != nil {
+				return "", "", nil, err
+			}
+			var warnings []string
+			if group.HasRecipient(user.ID) {
+				warnings = append(warnings, "user is already in the group")
+			}
+			return user.ID, describeUser(user), warnings, nil
+		},
+		apply: func(client discord.API, group *discord.GroupDM, userID string) (*discord.GroupDM, error) {
+			if err := client.AddGroupRecipient(group.ID, userID); err != nil {
+				return nil, err
+			}
+			return client.GetGroupDM(group.ID)
+		},
+	}),
+}
+
+var dmGroupRemoveCmd = &cobra.Command{
+	Use:   "remove <group> <user>",
+	Short: "Remove a user from a group DM",
+	Long: `Remove a user from a group DM (requires approval unless --dry-run).
+
+Only the group's owner may remove people.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runGroupDMAction(groupDMAction{
+		name:   "remove_group_recipient",
+		prompt: "➖ Remove %s from group DM %s",
+		key:    "user_id",
+		target: func(r *resolver, group *discord.GroupDM, ref string) (string, string, []string, error) {
+			id, byID := userID(ref)
+			name := strings.TrimPrefix(strings.TrimSpace(ref), "@")
+			for _, u := range group.Recipients {
+				if (byID && u.ID == id) || strings.EqualFold(u.Username, name) || strings.EqualFold(u.GlobalName, name) {
+					return u.ID, describeUser(u), nil, nil
+				}
+			}
+			return "", "", nil, fmt.Errorf("user %q is not in the group", ref)
+		},
+		apply: func(client discord.API, group *discord.GroupDM, userID string) (*discord.GroupDM, error) {
+			if err := client.RemoveGroupRecipient(group.ID, userID); err != nil {
+				return nil, err
+			}
+			return client.GetGroupDM(group.ID)
+		},
+	}),
+}
+
+var dmGroupRenameCmd = &cobra.Command{
+	Use:   "rename <group> <name>",
+	Short: "Rename a group DM",
+	Long:  "Rename a group DM (requires approval unless --dry-run)",
+	Args:  cobra.ExactArgs(2),
+	RunE: runGroupDMAction(groupDMAction{
+		name:   "rename_group_dm",
+		prompt: "✏️  Rename group DM %[2]s to %[1]q",
+		key:    "name",
+		target: func(r *resolver, group *discord.GroupDM, name string) (string, string, []string, error) {
+			if strings.TrimSpace(name) == "" {
+				return "", "", nil, fmt.Errorf("group name cannot be empty")
+			}
+			return name, name, nil, nil
+		},
+		apply: func(client discord.API, group *discord.GroupDM, name string) (*discord.GroupDM, error) {
+			return client.RenameGroupDM(group.ID, name)
+		},
+	}),
+}
+
+func init() {
+	dmCmd.AddCommand(dmGroupCmd)
+	dmGroupCmd.AddCommand(dmGroupCreateCmd)
+	dmGroupCmd.AddCommand(dmGroupAddCmd)
+	dmGroupCmd.AddCommand(dmGroupRemoveCmd)
+	dmGroupCmd.AddCommand(dmGroupRenameCmd)
+
+	dmGroupCreateCmd.Flags().String("name", "", "Name of the group")
+	dmGroupCreateCmd.Flags().Bool("dry-run", false, "Show the group that would be created without creating it")
+	dmGroupAddCmd.Flags().Bool("dry-run", false, "Show who would be added without adding them")
+	dmGroupRemoveCmd.Flags().Bool("dry-run", false, "Show who would be removed without removing them")
+	dmGroupRenameCmd.Flags().Bool("dry-run", false, "Show the new name without renaming")
+}
+
+func runDMGroupCreate(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	name, _ := cmd.Flags().GetString("name")
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	// Resolve every recipient
+	r := newResolver(client)
+	userIDs := make([]string, 0, len(args))
+	labels := make([]string, 0, len(args))
+	for _, ref := range args {
+		user, err := r.user(ref)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		userIDs = append(userIDs, user.ID)
+		labels = append(labels, describeUser(user))
+	}
+
+	// Dry run
+	if dryRun {
+		return output.PrintSuccess(map[string]interface{}{
+			"action":     "create_group_dm",
+			"user_ids":   userIDs,
+			"group_name": name,
+			"dry_run":    true,
+		}, pretty)
+	}
+
+	// Check approval requirement
+	if cfg.RequireApproval {
+		if name != "" {
+			fmt.Printf("👥 Create group DM %q with %s\n\n", name, strings.Join(labels, ", "))
+		} else {
+			fmt.Printf("👥 Create group DM with %s\n\n", strings.Join(labels, ", "))
+		}
+		fmt.Print("Proceed? [y/N]: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			return output.PrintSuccess(map[string]interface{}{
+				"action":    "create_group_dm",
+				"cancelled": true,
+			}, pretty)
+		}
+	}
+
+	// Create group DM, then name it
+	group, err := client.CreateGroupDM(userIDs)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	if name != "" {
+		group, err = client.RenameGroupDM(group.ID, name)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+	}
+
+	return output.PrintSuccess(map[string]interface{}{
+		"action": "create_group_dm",
+		"group":  group,
+	}, pretty)
+}
+
+// groupDMAction is a change to a group DM made by a dm group subcommand.
+// target checks the command's second argument against the group and
+// returns the value apply takes, how to show it in the prompt and any
+// warnings; key names the value in dry-run output.
+type groupDMAction struct {
+	name   string
+	prompt string
+	key    string
+	target func(r *resolver, group *discord.GroupDM, ref string) (value, label string, warnings []string, err error)
+	apply  func(client discord.API, group *discord.GroupDM, value string) (*discord.GroupDM, error)
+}
+
+// describeUser names a user in prompts
+func describeUser(u *discord.Author) string {
+	switch {
+	case u.ID == "":
+		return "@" + u.Username
+	case u.Username == "":
+		return u.ID
+	}
+	return fmt.Sprintf("@%s (%s)", u.Username, u.ID)
+}
+
+// describeGroup names a group DM in prompts: its name, else its members
+func describeGroup(g *discord.GroupDM) string {
+	if g.Name != "" {
+		return fmt.Sprintf("%q (%s)", g.Name, g.ID)
+	}
+	names := make([]string, 0, len(g.Recipients))
+	for _, u := range g.Recipients {
+		names = append(names, "@"+u.Username)
+	}
+	return fmt.Sprintf("with %s (%s)", strings.Join(names, ", "), g.ID)
+}
+
+// runGroupDMAction returns the RunE of a group DM changing command
+func runGroupDMAction(action groupDMAction) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		pretty, _ := cmd.Flags().GetBool("output-pretty")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		groupRef := args[0]
+
+		// Load config
+		cfg, err := config.Load(cfgFile)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		// Get token
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = cfg.UserToken
+		}
+
+		if token == "" {
+			return output.PrintError(fmt.Errorf("no token configured"), pretty)
+		}
+
+		// Create Discord client
+		client, err := newClient(cmd, cfg, token)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		defer client.Close()
+
+		r := newResolver(client)
+		group, err := r.group(groupRef)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		value, label, warnings, err := action.target(r, group, args[1])
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		// Dry run
+		if dryRun {
+			return output.PrintSuccessWithWarnings(map[string]interface{}{
+				"action":     action.name,
+				"group_id":   group.ID,
+				"group_name": group.Name,
+				action.key:   value,
+				"dry_run":    true,
+			}, warnings, pretty)
+		}
+
+		// Check approval requirement
+		if cfg.RequireApproval {
+			fmt.Printf(action.prompt+"\n\n", label, describeGroup(group))
+			printWarnings(warnings)
+			fmt.Print("Proceed? [y/N]: ")
+
+			reader := bufio.NewReader(os.Stdin)
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
+			}
+
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				return output.PrintSuccess(map[string]interface{}{
+					"action":    action.name,
+					"cancelled": true,
+				}, pretty)
+			}
+		}
+
+		group, err = action.apply(client, group, value)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		return output.PrintSuccessWithWarnings(map[string]interface{}{
+			"action": action.name,
+			"group":  group,
+		}, warnings, pretty)
+	}
+}
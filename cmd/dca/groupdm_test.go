@@ -0,0 +1,216 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+// channelGroupDM is the group DM added by addGroupDM
+const channelGroupDM = "800"
+
+type groupActionData struct {
+	Action    string           `json:"action"`
+	Group     *discord.GroupDM `json:"group"`
+	UserIDs   []string         `json:"user_ids"`
+	UserID    string           `json:"user_id"`
+	Name      string           `json:"name"`
+	DryRun    bool             `json:"dry_run"`
+	Cancelled bool             `json:"cancelled"`
+}
+
+// addGroupDM adds a group DM with alice and bob, owned by the current
+// user, holding the newest message of all
+func addGroupDM(env *testEnv) {
+	alice := &discordgo.User{ID: fake.UserAlice, Username: "alice"}
+	bob := &discordgo.User{ID: fake.UserBob, Username: "bob", GlobalName: "Bobby"}
+	env.srv.AddChannel(&discordgo.Channel{
+		ID: channelGroupDM, Type: discordgo.ChannelTypeGroupDM, Name: "Weekend plans", Icon: "abc123", OwnerID: fake.UserMe,
+		Recipients: []*discordgo.User{alice, bob},
+	})
+	env.srv.AddMessage(&discordgo.Message{
+		ChannelID: channelGroupDM,
+		Author:    bob,
+		Content:   "hiking on saturday?",
+		Timestamp: fake.SeedTime.Add(time.Hour),
+	})
+}
+
+func TestDMListGroup(t *testing.T) {
+	env := newTestEnv(t)
+	addGroupDM(env)
+
+	var data struct {
+		DMChannels []*discord.DMChannel `json:"dm_channels"`
+		Count      int                  `json:"count"`
+	}
+	env.mustRun(t, "dm", "list").decode(t, &data)
+	if data.Count != 2 {
+		t.Fatalf("expected the group and the DM with alice, got %+v", data.DMChannels)
+	}
+	group := data.DMChannels[0]
+	if group.ChannelID != channelGroupDM || group.Type != "group_dm" || group.User != nil || group.Group == nil {
+		t.Fatalf("expected the group DM first, got %+v", group)
+	}
+	if group.Group.Name != "Weekend plans" || !strings.HasSuffix(group.Group.Icon, "/channel-icons/"+channelGroupDM+"/abc123.png") || len(group.Group.Recipients) != 2 {
+		t.Errorf("expected name, icon and both recipients, got %+v", group.Group)
+	}
+	if group.LastMessage == nil || group.LastMessage.Content != "hiking on saturday?" {
+		t.Errorf("unexpected last message: %+v", group.LastMessage)
+	}
+	if dm := data.DMChannels[1]; dm.Type != "dm" || dm.User == nil || dm.User.Username != "alice" || dm.Group != nil {
+		t.Errorf("expected the DM with alice, got %+v", dm)
+	}
+}
+
+func TestDMHistoryGroup(t *testing.T) {
+	env := newTestEnv(t)
+	addGroupDM(env)
+
+	for _, ref := range []string{"weekend plans", channelGroupDM, "https://discord.com/channels/@me/" + channelGroupDM} {
+		var data struct {
+			Messages []*discord.Message `json:"messages"`
+		}
+		env.mustRun(t, "dm", "history", ref).decode(t, &data)
+		if len(data.Messages) != 1 || data.Messages[0].ChannelID != channelGroupDM || data.Messages[0].GuildID != "" {
+			t.Errorf("%s: expected the group's message, got %+v", ref, data.Messages)
+		}
+	}
+
+	// Users still resolve to their DM
+	var data struct {
+		Messages []*discord.Message `json:"messages"`
+	}
+	env.mustRun(t, "dm", "history", fake.UserAlice).decode(t, &data)
+	if len(data.Messages) == 0 || data.Messages[0].ChannelID != fake.ChannelDMAlice {
+		t.Errorf("expected the DM with alice, got %+v", data.Messages)
+	}
+}
+
+func TestActivityRecentGroup(t *testing.T) {
+	env := newTestEnv(t)
+	addGroupDM(env)
+
+	var data struct {
+		Activity []*discord.ActivityMessage `json:"activity"`
+	}
+	env.mustRun(t, "activity", "recent", "--type", "dm").decode(t, &data)
+	if len(data.Activity) == 0 {
+		t.Fatal("expected DM activity")
+	}
+	newest := data.Activity[0]
+	if newest.ChannelID != channelGroupDM || newest.DMUser != nil || newest.DMGroup == nil || newest.DMGroup.Name != "Weekend plans" || len(newest.DMGroup.Recipients) != 2 {
+		t.Errorf("expected the group message with its group, got %+v", newest)
+	}
+}
+
+func TestDMGroupCreate(t *testing.T) {
+	env := newTestEnv(t)
+
+	var data groupActionData
+	env.mustRun(t, "dm", "group", "create", "alice", "bob", "--name", "Book club").decode(t, &data)
+	if data.Action != "create_group_dm" || data.Group == nil || data.Group.Name != "Book club" || data.Group.OwnerID != fake.UserMe {
+		t.Fatalf("unexpected group: %+v", data.Group)
+	}
+	if len(data.Group.Recipients) != 2 || !data.Group.HasRecipient(fake.UserAlice) || !data.Group.HasRecipient(fake.UserBob) {
+		t.Errorf("expected alice and bob in the group, got %+v", data.Group.Recipients)
+	}
+	if ch := env.srv.Channel(data.Group.ID); ch == nil || ch.Type != discordgo.ChannelTypeGroupDM {
+		t.Errorf("group DM not stored: %+v", ch)
+	}
+
+	data = groupActionData{}
+	env.mustRun(t, "dm", "group", "create", "alice", fake.UserBob, "--dry-run").decode(t, &data)
+	if !data.DryRun || strings.Join(data.UserIDs, ",") != fake.UserAlice+","+fake.UserBob {
+		t.Errorf("unexpected dry-run output: %+v", data)
+	}
+}
+
+func TestDMGroupAddRemove(t *testing.T) {
+	env := newTestEnv(t)
+	addGroupDM(env)
+	env.srv.AddUser(&discordgo.User{ID: "103", Username: "carol"})
+
+	var data groupActionData
+	env.mustRun(t, "dm", "group", "add", "Weekend plans", "103").decode(t, &data)
+	if data.Action != "add_group_recipient" || len(data.Group.Recipients) != 3 || !data.Group.HasRecipient("103") {
+		t.Errorf("expected carol added, got %+v", data.Group)
+	}
+
+	resp, _ := env.run(t, "dm", "group", "add", channelGroupDM, "alice", "--dry-run")
+	if !resp.OK || len(resp.Warnings) != 1 || resp.Warnings[0] != "user is already in the group" {
+		t.Errorf("expected an already-in-group warning, got %+v", resp)
+	}
+
+	data = groupActionData{}
+	env.mustRun(t, "dm", "group", "remove", "Weekend plans", "@bob").decode(t, &data)
+	if data.Action != "remove_group_recipient" || data.Group.HasRecipient(fake.UserBob) || len(data.Group.Recipients) != 2 {
+		t.Errorf("expected bob removed, got %+v", data.Group)
+	}
+
+	resp, _ = env.run(t, "dm", "group", "remove", "Weekend plans", "bob")
+	if resp.OK || !strings.Contains(resp.Error, "not in the group") {
+		t.Errorf("expected removing a non-member to fail, got %+v", resp)
+	}
+
+	// Only the owner may remove people
+	env.srv.AddChannel(&discordgo.Channel{
+		ID: "801", Type: discordgo.ChannelTypeGroupDM, OwnerID: fake.UserAlice,
+		Recipients: []*discordgo.User{{ID: fake.UserAlice, Username: "alice"}},
+	})
+	resp, _ = env.run(t, "dm", "group", "remove", "801", "alice")
+	if resp.OK || !strings.Contains(resp.Error, "Missing Permissions") {
+		t.Errorf("expected a permissions error, got %+v", resp)
+	}
+}
+
+func TestDMGroupRename(t *testing.T) {
+	env := newTestEnv(t)
+	addGroupDM(env)
+
+	var data groupActionData
+	env.mustRun(t, "dm", "group", "rename", channelGroupDM, "Hiking").decode(t, &data)
+	if data.Action != "rename_group_dm" || data.Group.Name != "Hiking" || env.srv.Channel(channelGroupDM).Name != "Hiking" {
+		t.Errorf("expected the group renamed, got %+v", data.Group)
+	}
+
+	resp, _ := env.run(t, "dm", "group", "rename", "Weekend plans", "Other")
+	if resp.OK || !strings.Contains(resp.Error, `group DM "Weekend plans" not found`) {
+		t.Errorf("expected the old name to be gone, got %+v", resp)
+	}
+
+	// Group commands only act on group DMs
+	resp, _ = env.run(t, "dm", "group", "rename", fake.ChannelDMAlice, "Other")
+	if resp.OK || !strings.Contains(resp.Error, "not a group DM") {
+		t.Errorf("expected a plain DM to be refused, got %+v", resp)
+	}
+}
+
+func TestDMGroupApproval(t *testing.T) {
+	env := newTestEnv(t)
+	addGroupDM(env)
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	env.stdin = "n\n"
+	resp, stdout := env.run(t, "dm", "group", "rename", "Weekend plans", "Hiking")
+	if !strings.Contains(stdout, `Rename group DM "Weekend plans" (`+channelGroupDM+`) to "Hiking"`) {
+		t.Errorf("expected an approval prompt, got %q", stdout)
+	}
+	var data groupActionData
+	resp.decode(t, &data)
+	if !data.Cancelled || env.srv.Channel(channelGroupDM).Name != "Weekend plans" {
+		t.Errorf("expected the rename to be cancelled, got %+v", data)
+	}
+
+	// Warnings are shown before approving
+	env.stdin = "n\n"
+	_, stdout = env.run(t, "dm", "group", "add", "Weekend plans", "alice")
+	if !strings.Contains(stdout, "⚠️  user is already in the group") {
+		t.Errorf("expected the prompt to warn, got %q", stdout)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/discord"
+)
+
+// addHistoryFlags registers the paging flags shared by history commands
+func addHistoryFlags(cmd *cobra.Command, defaultLimit int) {
+	cmd.Flags().Int("limit", defaultLimit, "Number of messages to retrieve (pages automatically past 100)")
+	cmd.Flags().String("before", "", "Only messages before this message ID (use next_cursor to page back)")
+	cmd.Flags().String("after", "", "Only messages after this message ID (pages forward; next_cursor continues)")
+	cmd.Flags().String("around", "", "Messages around this message ID (max 100)")
+	addTimeWindowFlags(cmd)
+}
+
+// historyOptions reads the paging flags registered by addHistoryFlags
+func historyOptions(cmd *cobra.Command) (discord.HistoryOptions, error) {
+	limit, _ := cmd.Flags().GetInt("limit")
+	before, _ := cmd.Flags().GetString("before")
+	after, _ := cmd.Flags().GetString("after")
+	around, _ := cmd.Flags().GetString("around")
+
+	since, until, err := timeWindow(cmd)
+	if err != nil {
+		return discord.HistoryOptions{}, err
+	}
+
+	return discord.HistoryOptions{
+		Limit:  limit,
+		Before: before,
+		After:  after,
+		Around: around,
+		Since:  since,
+		Until:  until,
+	}, nil
+}
+
+// addTimeWindowFlags registers --since and --until
+func addTimeWindowFlags(cmd *cobra.Command) {
+	cmd.Flags().String("since", "", "Only messages sent at or after this time (RFC3339, YYYY-MM-DD, or a duration ago like 2h, 3d)")
+	cmd.Flags().String("until", "", "Only messages sent before this time (RFC3339, YYYY-MM-DD, or a duration ago like 30m)")
+}
+
+// timeWindow parses --since and --until. Unset flags return zero times.
+func timeWindow(cmd *cobra.Command) (since, until time.Time, err error) {
+	now := time.Now()
+
+	if v, _ := cmd.Flags().GetString("since"); v != "" {
+		if since, err = parseTimeFlag(v, now); err != nil {
+			return since, until, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if v, _ := cmd.Flags().GetString("until"); v != "" {
+		if until, err = parseTimeFlag(v, now); err != nil {
+			return since, until, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return since, until, fmt.Errorf("--since must be before --until")
+	}
+
+	return since, until, nil
+}
+
+// parseTimeFlag accepts an RFC3339 timestamp, a YYYY-MM-DD date (UTC) or a
+// duration before now. Durations take Go syntax plus d (days) and w
+// (weeks), e.g. 90m, 2h, 3d, 1w.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+
+	d, err := parseRelativeDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not an RFC3339 time, a date or a duration", value)
+	}
+	return now.Add(-d), nil
+}
+
+// parseRelativeDuration extends time.ParseDuration with whole days and weeks
+func parseRelativeDuration(value string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return d, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeFlag(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2026-02-24T10:30:00Z", time.Date(2026, 2, 24, 10, 30, 0, 0, time.UTC)},
+		{"2026-02-24T10:30:00+02:00", time.Date(2026, 2, 24, 8, 30, 0, 0, time.UTC)},
+		{"2026-02-24", time.Date(2026, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{"2h", now.Add(-2 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"3d", now.Add(-72 * time.Hour)},
+		{"1w", now.Add(-7 * 24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimeFlag(tt.value, now)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: expected %s, got %s", tt.value, tt.want, got)
+		}
+	}
+
+	for _, bad := range []string{"", "yesterday", "-2h", "xd", "2026-13-01"} {
+		if _, err := parseTimeFlag(bad, now); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
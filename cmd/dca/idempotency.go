@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/ledger"
+)
+
+// addIdempotencyFlag registers --idempotency-key on a send command
+func addIdempotencyFlag(cmd *cobra.Command) {
+	cmd.Flags().String("idempotency-key", "", "Send at most once for this key; retries return the original message")
+}
+
+// sendGuard applies --idempotency-key and the duplicate content check to
+// one send, collecting warnings for the output
+type sendGuard struct {
+	ledger      *ledger.Ledger
+	key         string
+	target      string
+	fingerprint string
+	warnings    []string
+
+	// sent holds the parts an earlier, failed send with the same key
+	// already posted
+	sent []*discord.Message
+}
+
+// newSendGuard opens the ledger and warns if the same content went to
+// target within the configured duplicate window. target is the channel ID,
+// or dm:<user-id> for direct messages.
+func newSendGuard(cmd *cobra.Command, cfg *config.Config, target string, out *discord.OutgoingMessage) (*sendGuard, error) {
+	key, _ := cmd.Flags().GetString("idempotency-key")
+
+	cfgPath := cfgFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath()
+	}
+	l, err := ledger.Open(ledger.DefaultPath(cfgPath))
+	if err != nil {
+		return nil, err
+	}
+
+	g := &sendGuard{ledger: l, key: key, target: target, fingerprint: out.Fingerprint()}
+	if window := cfg.DuplicateCheckWindow(); window > 0 {
+		e := l.Recent(target, g.fingerprint, window, time.Now())
+		if e != nil && (key == "" || e.Key != key) {
+			g.warnings = append(g.warnings, fmt.Sprintf("identical content was sent here %s ago (message %s)",
+				time.Since(e.SentAt).Round(time.Second), strings.Join(e.MessageIDs, ", ")))
+		}
+	}
+	return g, nil
+}
+
+// replay returns the messages sent earlier with the same idempotency key,
+// or nil if the key is new. If that send failed partway, replay returns nil
+// and keeps the parts it posted in g.sent, so only the rest is sent.
+func (g *sendGuard) replay(client discord.API) ([]*discord.Message, error) {
+	if g.key == "" {
+		return nil, nil
+	}
+	e := g.ledger.Lookup(g.key)
+	if e == nil {
+		return nil, nil
+	}
+	if e.Target != g.target || e.Fingerprint != g.fingerprint {
+		return nil, fmt.Errorf("idempotency key %q was already used for a different message", g.key)
+	}
+
+	msgs := make([]*discord.Message, 0, len(e.MessageIDs))
+	for _, id := range e.MessageIDs {
+		msg, err := client.GetMessage(e.ChannelID, id)
+		if err != nil {
+			return nil, fmt.Errorf("idempotency key %q was already used for message %s, which could not be fetched: %w", g.key, id, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	if e.Partial {
+		g.sent = msgs
+		g.warnings = append(g.warnings, fmt.Sprintf("idempotency key %q was used at %s for a send that failed after %d parts; sending the rest",
+			g.key, e.SentAt.Format(time.RFC3339), len(msgs)))
+		return nil, nil
+	}
+	g.warnings = []string{fmt.Sprintf("idempotency key %q was already used at %s; returning the original message instead of sending again",
+		g.key, e.SentAt.Format(time.RFC3339))}
+	return msgs, nil
+}
+
+// setNonces gives each part a nonce derived from the idempotency key, so
+// Discord also drops a retry that races the ledger
+func (g *sendGuard) setNonces(parts []*discord.OutgoingMessage) {
+	if g.key == "" {
+		return
+	}
+	for i, p := range parts {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%d", g.key, i)))
+		p.Nonce = hex.EncodeToString(sum[:])[:discord.MaxNonceLength]
+	}
+}
+
+// record adds a send to the ledger; partial marks one that failed after
+// msgs were posted
+func (g *sendGuard) record(msgs []*discord.Message, partial bool) error {
+	return g.ledger.Record(&ledger.Entry{
+		Key:         g.key,
+		Target:      g.target,
+		ChannelID:   msgs[0].ChannelID,
+		Fingerprint: g.fingerprint,
+		MessageIDs:  messageIDs(msgs),
+		Partial:     partial,
+		SentAt:      time.Now().UTC(),
+	})
+}
+
+// printWarnings lists warnings in an approval prompt
+func printWarnings(warnings []string) {
+	for _, w := range warnings {
+		fmt.Printf("⚠️  %s\n", w)
+	}
+	if len(warnings) > 0 {
+		fmt.Println()
+	}
+}
@@ -26,6 +26,11 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/dca/config.json)")
 	rootCmd.PersistentFlags().String("token", "", "Discord bot token (overrides config)")
 	rootCmd.PersistentFlags().Bool("output-pretty", false, "Pretty print JSON output")
+	rootCmd.PersistentFlags().String("api-base-url", "", "Discord API base URL, e.g. a local mock (overrides config)")
+	rootCmd.PersistentFlags().String("proxy", "", "HTTP(S) proxy URL for Discord requests (overrides config)")
+	rootCmd.PersistentFlags().String("ca-bundle", "", "PEM file with extra CA certificates to trust (overrides config)")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Timeout for each Discord API request, e.g. 30s (overrides config)")
+	rootCmd.PersistentFlags().Bool("compact", false, "Leave attachments, embeds, reactions and other message metadata out of the output")
 }
 
 func main() {
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+// testResponse mirrors output.Response with the data left undecoded
+type testResponse struct {
+	OK       bool            `json:"ok"`
+	Data     json.RawMessage `json:"data"`
+	Error    string          `json:"error"`
+	Warnings []string        `json:"warnings"`
+}
+
+// decode unmarshals the response data into v
+func (r *testResponse) decode(t *testing.T, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		t.Fatalf("failed to decode response data %s: %v", r.Data, err)
+	}
+}
+
+// testEnv runs dca commands against a fake Discord server
+type testEnv struct {
+	srv        *fake.Server
+	configPath string
+	// stdin is fed to the command, e.g. to answer approval prompts
+	stdin string
+}
+
+// newTestEnv starts a fake server and writes a config file that points
+// at it, with approval disabled
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	env := &testEnv{
+		srv:        fake.New(t),
+		configPath: filepath.Join(t.TempDir(), "config.json"),
+	}
+	env.writeConfig(t, &config.Config{UserToken: fake.Token})
+	return env
+}
+
+// writeConfig replaces the config file used by the commands. An empty API
+// base URL is pointed at the fake server.
+func (e *testEnv) writeConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	if cfg.APIBaseURL == "" {
+		cfg.APIBaseURL = e.srv.APIURL()
+	}
+	if err := config.Save(cfg, e.configPath); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+// run executes dca with args and returns the JSON response, which is the
+// last thing written to stdout. Prompts printed before it are returned too.
+func (e *testEnv) run(t *testing.T, args ...string) (*testResponse, string) {
+	t.Helper()
+
+	resetFlags(rootCmd)
+	rootCmd.SetArgs(append([]string{"--config", e.configPath}, args...))
+
+	stdout := captureStdout(t, e.stdin, func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("dca %s: %v", strings.Join(args, " "), err)
+		}
+	})
+
+	// Approval prompts do not end in a newline, so look for the response
+	// object itself rather than the last line
+	var resp testResponse
+	start := strings.LastIndex(stdout, `{"ok":`)
+	if start < 0 || json.Unmarshal([]byte(stdout[start:]), &resp) != nil {
+		t.Fatalf("dca %s: output is not JSON: %q", strings.Join(args, " "), stdout)
+	}
+	return &resp, stdout
+}
+
+// mustRun is run for commands that are expected to succeed
+func (e *testEnv) mustRun(t *testing.T, args ...string) *testResponse {
+	t.Helper()
+
+	resp, _ := e.run(t, args...)
+	if !resp.OK {
+		t.Fatalf("dca %s: unexpected error: %s", strings.Join(args, " "), resp.Error)
+	}
+	return resp
+}
+
+// captureStdout runs fn with os.Stdin reading from stdin and returns what fn
+// wrote to os.Stdout
+func captureStdout(t *testing.T, stdin string, fn func()) string {
+	t.Helper()
+
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_, _ = io.WriteString(inW, stdin)
+		inW.Close()
+	}()
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, outR)
+		done <- buf.String()
+	}()
+
+	origIn, origOut := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = inR, outW
+	defer func() {
+		os.Stdin, os.Stdout = origIn, origOut
+		inR.Close()
+	}()
+
+	fn()
+	outW.Close()
+	return <-done
+}
+
+// resetFlags restores every flag in the command tree to its default, since
+// cobra keeps flag values between executions of the same command. Setting a
+// slice flag appends, so those are emptied instead.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, c := range cmd.Commands() {
+		resetFlags(c)
+	}
+}
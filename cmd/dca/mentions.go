@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+)
+
+// addMentionFlags registers the mention controls of a send command. Reply
+// commands also get --no-reply-ping.
+func addMentionFlags(cmd *cobra.Command, reply bool) {
+	cmd.Flags().String("allow-mentions", "", "Mentions that may notify, comma-separated: users, roles, everyone or none (default from config, else users)")
+	if reply {
+		cmd.Flags().Bool("no-reply-ping", false, "Do not notify the author of the message replied to")
+	}
+}
+
+// mentionPolicy combines the allowed_mentions config with --allow-mentions
+// and --no-reply-ping
+func mentionPolicy(cmd *cobra.Command, cfg *config.Config) (*discord.MentionPolicy, error) {
+	allowed, _ := cmd.Flags().GetString("allow-mentions")
+	source := "--allow-mentions"
+	if allowed == "" {
+		allowed, source = cfg.AllowedMentions, "allowed_mentions"
+	}
+	if allowed == "" {
+		allowed = config.DefaultAllowedMentions
+	}
+
+	policy, err := discord.ParseMentionPolicy(allowed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", source, err)
+	}
+	noReplyPing, _ := cmd.Flags().GetBool("no-reply-ping")
+	policy.ReplyPing = !noReplyPing
+	return policy, nil
+}
+
+// replyMention describes the ping a reply gives the author of the message
+// it replies to
+func replyMention(client discord.API, channelID, messageID string, policy *discord.MentionPolicy) (*discord.Mention, error) {
+	target, err := client.GetMessage(channelID, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message to reply to: %w", err)
+	}
+	return &discord.Mention{
+		Kind:     "reply",
+		ID:       target.Author.ID,
+		Name:     "@" + target.Author.Username,
+		Notified: policy.ReplyPing,
+	}, nil
+}
+
+// printMentions shows who a message will and will not notify in an
+// approval prompt
+func printMentions(mentions []*discord.Mention) {
+	var notified, blocked []string
+	for _, m := range mentions {
+		name := m.Name
+		switch m.Kind {
+		case "role":
+			name += " (role)"
+		case "reply":
+			name += " (reply author)"
+		}
+		if m.Notified {
+			notified = append(notified, name)
+		} else {
+			blocked = append(blocked, name)
+		}
+	}
+
+	if len(notified) > 0 {
+		fmt.Printf("   🔔 Notifies: %s\n", strings.Join(notified, ", "))
+	}
+	if len(blocked) > 0 {
+		fmt.Printf("   🔕 Mentioned without notification: %s\n", strings.Join(blocked, ", "))
+	}
+	if len(mentions) > 0 {
+		fmt.Println()
+	}
+}
@@ -19,34 +19,42 @@ var messageCmd = &cobra.Command{
 }
 
 var messageSendCmd = &cobra.Command{
-	Use:   "send <channel-id> <message>",
+	Use:   "send <channel> [message]",
 	Short: "Send a message",
-	Long:  "Send a message to a channel (requires approval unless --dry-run)",
-	Args:  cobra.ExactArgs(2),
-	RunE:  runMessageSend,
+	Long: `Send a message to a channel (requires approval unless --dry-run).
+
+The channel may be an ID, a link, "server/#channel", "#channel" or
+@username for a DM. Attach files with --file and embeds with --embed-json
+or the --embed-* flags; the message text may then be left out.
+
+The text may also come from --content-file. Text over 2000 characters is
+split on paragraph, line and code block boundaries and sent as several
+messages after a single approval.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runMessageSend,
 }
 
 var messageReplyCmd = &cobra.Command{
-	Use:   "reply <channel-id> <message-id> <message>",
+	Use:   "reply (<message-link> | <channel> <message-id>) [message]",
 	Short: "Reply to a message",
-	Long:  "Reply to a specific message (requires approval unless --dry-run)",
-	Args:  cobra.ExactArgs(3),
+	Long:  "Reply to a specific message, optionally with --file attachments and embeds (requires approval unless --dry-run)",
+	Args:  messageArgs(0, 1),
 	RunE:  runMessageReply,
 }
 
 var messageEditCmd = &cobra.Command{
-	Use:   "edit <channel-id> <message-id> <new-message>",
+	Use:   "edit (<message-link> | <channel> <message-id>) <new-message>",
 	Short: "Edit your message",
 	Long:  "Edit one of your messages (requires approval unless --dry-run)",
-	Args:  cobra.ExactArgs(3),
+	Args:  messageArgs(1, 1),
 	RunE:  runMessageEdit,
 }
 
 var messageDeleteCmd = &cobra.Command{
-	Use:   "delete <channel-id> <message-id>",
+	Use:   "delete (<message-link> | <channel> <message-id>)",
 	Short: "Delete your message",
 	Long:  "Delete one of your messages (requires approval unless --dry-run)",
-	Args:  cobra.ExactArgs(2),
+	Args:  messageArgs(0, 0),
 	RunE:  runMessageDelete,
 }
 
@@ -59,15 +67,29 @@ func init() {
 
 	messageSendCmd.Flags().Bool("dry-run", false, "Show what would be sent without actually sending")
 	messageReplyCmd.Flags().Bool("dry-run", false, "Show what would be sent without actually sending")
+	addFileFlags(messageSendCmd)
+	addFileFlags(messageReplyCmd)
+	addEmbedFlags(messageSendCmd)
+	addEmbedFlags(messageReplyCmd)
+	addContentFlags(messageSendCmd)
+	addContentFlags(messageReplyCmd)
+	addIdempotencyFlag(messageSendCmd)
+	addIdempotencyFlag(messageReplyCmd)
+	addMentionFlags(messageSendCmd, false)
+	addMentionFlags(messageReplyCmd, true)
+	addNotificationFlags(messageSendCmd)
+	addNotificationFlags(messageReplyCmd)
 	messageEditCmd.Flags().Bool("dry-run", false, "Show what would be changed without actually changing")
 	messageDeleteCmd.Flags().Bool("dry-run", false, "Show what would be deleted without actually deleting")
 }
 
 func runMessageSend(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
-	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	channelID := args[0]
-	content := args[1]
+	channelRef := args[0]
+	content := ""
+	if len(args) > 1 {
+		content = args[1]
+	}
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -75,35 +97,10 @@ func runMessageSend(cmd *cobra.Command, args []string) error {
 		return output.PrintError(err, pretty)
 	}
 
-	// Dry run - just show what would be sent
-	if dryRun {
-		return output.PrintSuccess(map[string]interface{}{
-			"action":     "send_message",
-			"channel_id": channelID,
-			"content":    content,
-			"dry_run":    true,
-		}, pretty)
-	}
-
-	// Check approval requirement
-	if cfg.RequireApproval {
-		fmt.Printf("📝 Send message to channel %s:\n", channelID)
-		fmt.Printf("   \"%s\"\n\n", content)
-		fmt.Print("Proceed? [y/N]: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
-		}
-
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
-			return output.PrintSuccess(map[string]interface{}{
-				"action":    "send_message",
-				"cancelled": true,
-			}, pretty)
-		}
+	// Read the message before connecting so missing files fail fast
+	flow, err := newSendFlow(cmd, cfg, content)
+	if err != nil {
+		return output.PrintError(err, pretty)
 	}
 
 	// Get token
@@ -117,27 +114,35 @@ func runMessageSend(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
-	// Send message
-	msg, err := client.SendMessage(channelID, content)
+	channelID, err := newResolver(client).channel(channelRef)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 
-	return output.PrintSuccess(msg, pretty)
+	flow.action = "send_message"
+	flow.header = fmt.Sprintf("📝 Send message to channel %s:", channelID)
+	flow.details = map[string]interface{}{"channel_id": channelID}
+	flow.channelID = channelID
+	flow.target = channelID
+	flow.send = func(_ int, part *discord.OutgoingMessage) (*discord.Message, error) {
+		return client.SendMessage(channelID, part)
+	}
+	return flow.run(client, pretty)
 }
 
 func runMessageReply(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
-	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	channelID := args[0]
-	messageID := args[1]
-	content := args[2]
+	channelRef, messageRef, rest := splitMessageArgs(args)
+	content := ""
+	if len(rest) > 0 {
+		content = rest[0]
+	}
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -145,36 +150,10 @@ func runMessageReply(cmd *cobra.Command, args []string) error {
 		return output.PrintError(err, pretty)
 	}
 
-	// Dry run
-	if dryRun {
-		return output.PrintSuccess(map[string]interface{}{
-			"action":     "reply_message",
-			"channel_id": channelID,
-			"message_id": messageID,
-			"content":    content,
-			"dry_run":    true,
-		}, pretty)
-	}
-
-	// Check approval requirement
-	if cfg.RequireApproval {
-		fmt.Printf("📝 Reply to message %s in channel %s:\n", messageID, channelID)
-		fmt.Printf("   \"%s\"\n\n", content)
-		fmt.Print("Proceed? [y/N]: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
-		}
-
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
-			return output.PrintSuccess(map[string]interface{}{
-				"action":    "reply_message",
-				"cancelled": true,
-			}, pretty)
-		}
+	// Read the message before connecting so missing files fail fast
+	flow, err := newSendFlow(cmd, cfg, content)
+	if err != nil {
+		return output.PrintError(err, pretty)
 	}
 
 	// Get token
@@ -188,27 +167,38 @@ func runMessageReply(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
-	// Reply to message
-	msg, err := client.ReplyToMessage(channelID, messageID, content)
+	channelID, messageID, err := newResolver(client).message(channelRef, messageRef)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 
-	return output.PrintSuccess(msg, pretty)
+	flow.action = "reply_message"
+	flow.header = fmt.Sprintf("📝 Reply to message %s in channel %s:", messageID, channelID)
+	flow.details = map[string]interface{}{"channel_id": channelID, "message_id": messageID}
+	flow.channelID = channelID
+	flow.replyTo = messageID
+	flow.target = channelID
+	// The rest of a split reply follows as plain messages
+	flow.send = func(i int, part *discord.OutgoingMessage) (*discord.Message, error) {
+		if i == 0 {
+			return client.ReplyToMessage(channelID, messageID, part)
+		}
+		return client.SendMessage(channelID, part)
+	}
+	return flow.run(client, pretty)
 }
 
 func runMessageEdit(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	channelID := args[0]
-	messageID := args[1]
-	newContent := args[2]
+	channelRef, messageRef, rest := splitMessageArgs(args)
+	newContent := rest[0]
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -227,12 +217,17 @@ func runMessageEdit(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
+	channelID, messageID, err := newResolver(client).message(channelRef, messageRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
 	// Get original message for approval prompt
 	originalMsg, err := client.GetMessage(channelID, messageID)
 	if err != nil {
@@ -279,14 +274,14 @@ func runMessageEdit(cmd *cobra.Command, args []string) error {
 		return output.PrintError(err, pretty)
 	}
 
+	compactMessages(cmd, msg)
 	return output.PrintSuccess(msg, pretty)
 }
 
 func runMessageDelete(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	channelID := args[0]
-	messageID := args[1]
+	channelRef, messageRef, _ := splitMessageArgs(args)
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -305,12 +300,17 @@ func runMessageDelete(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
+	channelID, messageID, err := newResolver(client).message(channelRef, messageRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
 	// Get original message for approval prompt
 	originalMsg, err := client.GetMessage(channelID, messageID)
 	if err != nil {
@@ -0,0 +1,752 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+func TestMessageSend(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, "hello from the tests")
+
+	var msg discord.Message
+	resp.decode(t, &msg)
+	if msg.Content != "hello from the tests" {
+		t.Errorf("expected sent content, got %q", msg.Content)
+	}
+	if msg.Author.ID != fake.UserMe {
+		t.Errorf("expected author %s, got %s", fake.UserMe, msg.Author.ID)
+	}
+	wantURL := "https://discord.com/channels/" + fake.GuildID + "/" + fake.ChannelGeneral + "/" + msg.ID
+	if msg.GuildID != fake.GuildID || msg.URL != wantURL {
+		t.Errorf("expected guild %s and URL %s, got %q and %q", fake.GuildID, wantURL, msg.GuildID, msg.URL)
+	}
+
+	last := env.srv.LastMessage(fake.ChannelGeneral)
+	if last == nil || last.ID != msg.ID {
+		t.Fatalf("message was not stored by the server")
+	}
+}
+
+func TestMessageSendDryRun(t *testing.T) {
+	env := newTestEnv(t)
+	before := len(env.srv.Messages(fake.ChannelGeneral))
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, "not really", "--dry-run")
+
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["dry_run"] != true || data["content"] != "not really" {
+		t.Errorf("unexpected dry-run output: %v", data)
+	}
+	if got := len(env.srv.Messages(fake.ChannelGeneral)); got != before {
+		t.Errorf("dry run sent a message")
+	}
+}
+
+func TestMessageSendApproval(t *testing.T) {
+	env := newTestEnv(t)
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	before := len(env.srv.Messages(fake.ChannelGeneral))
+
+	env.stdin = "n\n"
+	resp, stdout := env.run(t, "message", "send", fake.ChannelGeneral, "declined")
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["cancelled"] != true {
+		t.Errorf("expected cancelled response, got %v", data)
+	}
+	if !strings.Contains(stdout, "Proceed?") {
+		t.Errorf("expected an approval prompt, got %q", stdout)
+	}
+	if got := len(env.srv.Messages(fake.ChannelGeneral)); got != before {
+		t.Fatalf("declined message was sent")
+	}
+
+	env.stdin = "y\n"
+	resp, _ = env.run(t, "message", "send", fake.ChannelGeneral, "approved")
+	if !resp.OK {
+		t.Fatalf("unexpected error: %s", resp.Error)
+	}
+	if last := env.srv.LastMessage(fake.ChannelGeneral); last.Content != "approved" {
+		t.Errorf("approved message was not sent, last is %q", last.Content)
+	}
+}
+
+func TestMessageReply(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+
+	resp := env.mustRun(t, "message", "reply", fake.ChannelGeneral, target.ID, "replying")
+
+	var msg discord.Message
+	resp.decode(t, &msg)
+	stored := env.srv.Message(fake.ChannelGeneral, msg.ID)
+	if stored == nil || stored.MessageReference == nil || stored.MessageReference.MessageID != target.ID {
+		t.Errorf("reply does not reference message %s", target.ID)
+	}
+	if msg.Type != "reply" || msg.Reference == nil || msg.Reference.MessageID != target.ID {
+		t.Errorf("expected reply reference in output, got %+v", msg.Reference)
+	}
+	if msg.Reference != nil && (msg.Reference.Author == nil || msg.Reference.Author.ID != target.Author.ID) {
+		t.Errorf("expected referenced author %s, got %+v", target.Author.ID, msg.Reference.Author)
+	}
+}
+
+func TestMessageEdit(t *testing.T) {
+	env := newTestEnv(t)
+	own := env.srv.LastMessage(fake.ChannelGeneral)
+
+	resp := env.mustRun(t, "message", "edit", fake.ChannelGeneral, own.ID, "edited text", "--dry-run")
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["original_content"] != own.Content || data["new_content"] != "edited text" {
+		t.Errorf("unexpected dry-run output: %v", data)
+	}
+
+	resp = env.mustRun(t, "message", "edit", fake.ChannelGeneral, own.ID, "edited text")
+	var msg discord.Message
+	resp.decode(t, &msg)
+	if msg.Content != "edited text" {
+		t.Errorf("expected edited content, got %q", msg.Content)
+	}
+	if msg.EditedTimestamp == "" {
+		t.Errorf("expected edited_timestamp to be set")
+	}
+}
+
+func TestMessageEditOthersMessage(t *testing.T) {
+	env := newTestEnv(t)
+	other := env.srv.Messages(fake.ChannelGeneral)[0]
+
+	resp, _ := env.run(t, "message", "edit", fake.ChannelGeneral, other.ID, "not mine")
+	if resp.OK {
+		t.Fatal("expected editing another user's message to fail")
+	}
+}
+
+func TestMessageDelete(t *testing.T) {
+	env := newTestEnv(t)
+	own := env.srv.LastMessage(fake.ChannelGeneral)
+
+	resp := env.mustRun(t, "message", "delete", fake.ChannelGeneral, own.ID)
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["deleted"] != true {
+		t.Errorf("unexpected output: %v", data)
+	}
+	if env.srv.Message(fake.ChannelGeneral, own.ID) != nil {
+		t.Error("message still exists after delete")
+	}
+
+	resp, _ = env.run(t, "message", "delete", fake.ChannelGeneral, own.ID)
+	if resp.OK {
+		t.Error("expected deleting a missing message to fail")
+	}
+}
+
+// writeTempFile creates a file with the given content for --file tests
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMessageSendFiles(t *testing.T) {
+	env := newTestEnv(t)
+	report := writeTempFile(t, "report.md", "# Report\n")
+	shot := writeTempFile(t, "shot.png", "\x89PNG\r\n\x1a\n")
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, "see attached", "--file", report, "--file", shot)
+
+	var msg discord.Message
+	resp.decode(t, &msg)
+	if len(msg.Attachments) != 2 {
+		t.Fatalf("expected 2 attachments, got %+v", msg.Attachments)
+	}
+	if a := msg.Attachments[0]; a.Filename != "report.md" || a.Size != 9 || !strings.HasPrefix(a.ContentType, "text/") {
+		t.Errorf("unexpected first attachment: %+v", a)
+	}
+	if a := msg.Attachments[1]; a.Filename != "shot.png" || a.ContentType != "image/png" {
+		t.Errorf("unexpected second attachment: %+v", a)
+	}
+	if data, ok := env.srv.FileData(msg.Attachments[0].ID); !ok || string(data) != "# Report\n" {
+		t.Errorf("uploaded content not stored, got %q", data)
+	}
+}
+
+func TestMessageReplyFileFromStdin(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+	env.stdin = "panic: boom\n"
+
+	resp := env.mustRun(t, "message", "reply", fake.ChannelGeneral, target.ID, "--file", "-", "--stdin-name", "crash.log")
+
+	var msg discord.Message
+	resp.decode(t, &msg)
+	if msg.Content != "" || len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "crash.log" {
+		t.Fatalf("expected a file-only reply with crash.log, got %+v", msg)
+	}
+	if data, _ := env.srv.FileData(msg.Attachments[0].ID); string(data) != "panic: boom\n" {
+		t.Errorf("expected stdin content, got %q", data)
+	}
+}
+
+func TestMessageSendFilesDryRunAndApproval(t *testing.T) {
+	env := newTestEnv(t)
+	report := writeTempFile(t, "report.md", strings.Repeat("x", 2048))
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, "--file", report, "--dry-run")
+	var data struct {
+		Files []struct {
+			Name string `json:"name"`
+			Size int    `json:"size"`
+		} `json:"files"`
+	}
+	resp.decode(t, &data)
+	if len(data.Files) != 1 || data.Files[0].Name != "report.md" || data.Files[0].Size != 2048 {
+		t.Errorf("expected file in dry-run output, got %+v", data.Files)
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	env.stdin = "n\n"
+	_, stdout := env.run(t, "message", "send", fake.ChannelGeneral, "report", "--file", report)
+	if !strings.Contains(stdout, "report.md (2.0 KB)") {
+		t.Errorf("expected prompt to list the file, got %q", stdout)
+	}
+
+	env.stdin = "contents"
+	resp, _ = env.run(t, "message", "send", fake.ChannelGeneral, "--file", "-")
+	if resp.OK || !strings.Contains(resp.Error, "stdin") {
+		t.Errorf("expected stdin attachment to conflict with approval, got %+v", resp)
+	}
+}
+
+func TestMessageSendFileErrors(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp, _ := env.run(t, "message", "send", fake.ChannelGeneral, "hi", "--file", filepath.Join(t.TempDir(), "missing.txt"))
+	if resp.OK || !strings.Contains(resp.Error, "failed to read attachment") {
+		t.Errorf("expected missing file error, got %+v", resp)
+	}
+
+	resp, _ = env.run(t, "message", "send", fake.ChannelGeneral)
+	if resp.OK || !strings.Contains(resp.Error, "--file is required") {
+		t.Errorf("expected error without content or files, got %+v", resp)
+	}
+}
+
+func TestMessageSendEmbedFlags(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral,
+		"--embed-title", "Release 1.2", "--embed-description", "Shipped",
+		"--embed-color", "#5865F2", "--embed-field", "Version=1.2.0",
+		"--embed-inline-field", "Env=prod", "--embed-footer", "ci")
+
+	var msg discord.Message
+	resp.decode(t, &msg)
+	if msg.Content != "" || len(msg.Embeds) != 1 {
+		t.Fatalf("expected an embed-only message, got %+v", msg)
+	}
+	e := msg.Embeds[0]
+	if e.Title != "Release 1.2" || e.Description != "Shipped" || e.Color != 0x5865F2 || e.Footer != "ci" {
+		t.Errorf("unexpected embed: %+v", e)
+	}
+	if len(e.Fields) != 2 || e.Fields[0].Name != "Version" || e.Fields[0].Value != "1.2.0" || e.Fields[0].Inline || !e.Fields[1].Inline {
+		t.Errorf("unexpected fields: %+v", e.Fields)
+	}
+}
+
+func TestMessageReplyEmbedJSON(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+	path := writeTempFile(t, "embed.json", `{"embeds": [{"title": "Status", "fields": [{"name": "api", "value": "up"}]}]}`)
+
+	resp := env.mustRun(t, "message", "reply", fake.ChannelGeneral, target.ID, "see below", "--embed-json", path)
+
+	var msg discord.Message
+	resp.decode(t, &msg)
+	if msg.Content != "see below" || len(msg.Embeds) != 1 || msg.Embeds[0].Title != "Status" || msg.Reference == nil {
+		t.Fatalf("expected a reply with the embed, got %+v", msg)
+	}
+
+	env.stdin = `{"title": "From stdin"}`
+	resp = env.mustRun(t, "message", "send", fake.ChannelGeneral, "--embed-json", "-")
+	msg = discord.Message{}
+	resp.decode(t, &msg)
+	if len(msg.Embeds) != 1 || msg.Embeds[0].Title != "From stdin" {
+		t.Errorf("expected embed from stdin, got %+v", msg.Embeds)
+	}
+}
+
+func TestMessageSendEmbedJSONUnchanged(t *testing.T) {
+	env := newTestEnv(t)
+	path := writeTempFile(t, "embed.json", `{
+		"title": "Build passed",
+		"author": {"name": "ci", "url": "https://ci.example.com", "icon_url": "https://ci.example.com/bot.png"},
+		"footer": {"text": "main", "icon_url": "https://ci.example.com/branch.png"},
+		"thumbnail": {"url": "https://ci.example.com/badge.png", "width": 64, "height": 64}
+	}`)
+
+	var msg discord.Message
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, "--embed-json", path).decode(t, &msg)
+
+	stored := env.srv.Message(fake.ChannelGeneral, msg.ID)
+	if stored == nil || len(stored.Embeds) != 1 {
+		t.Fatalf("expected the message with one embed, got %+v", stored)
+	}
+	e := stored.Embeds[0]
+	if e.Author == nil || e.Author.IconURL != "https://ci.example.com/bot.png" || e.Author.URL != "https://ci.example.com" {
+		t.Errorf("author not sent as given: %+v", e.Author)
+	}
+	if e.Footer == nil || e.Footer.IconURL != "https://ci.example.com/branch.png" {
+		t.Errorf("footer not sent as given: %+v", e.Footer)
+	}
+	if e.Thumbnail == nil || e.Thumbnail.Width != 64 {
+		t.Errorf("thumbnail not sent as given: %+v", e.Thumbnail)
+	}
+}
+
+func TestMessageSendEmbedValidation(t *testing.T) {
+	env := newTestEnv(t)
+	before := len(env.srv.Messages(fake.ChannelGeneral))
+
+	for _, args := range [][]string{
+		{"--embed-title", strings.Repeat("x", 300)},
+		{"--embed-field", "no-separator"},
+		{"--embed-color", "blue"},
+		{"--embed-json", writeTempFile(t, "bad.json", `{"title": `)},
+	} {
+		resp, _ := env.run(t, append([]string{"message", "send", fake.ChannelGeneral}, args...)...)
+		if resp.OK {
+			t.Errorf("%v: expected validation to fail", args)
+		}
+	}
+
+	if got := len(env.srv.Messages(fake.ChannelGeneral)); got != before {
+		t.Errorf("invalid embeds should not be sent, got %d new messages", got-before)
+	}
+}
+
+func TestMessageSendEmbedDryRunAndApproval(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, "--embed-title", "Deploy", "--dry-run")
+	var data struct {
+		Embeds []*discord.Embed `json:"embeds"`
+	}
+	resp.decode(t, &data)
+	if len(data.Embeds) != 1 || data.Embeds[0].Title != "Deploy" {
+		t.Errorf("expected embed in dry-run output, got %+v", data.Embeds)
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	env.stdin = "n\n"
+	_, stdout := env.run(t, "message", "send", fake.ChannelGeneral,
+		"--embed-title", "Deploy", "--embed-description", "line one\nline two", "--embed-field", "Env=prod")
+	for _, want := range []string{"┃ **Deploy**", "┃ line one", "┃ line two", "┃ Env: prod"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected prompt to contain %q, got %q", want, stdout)
+		}
+	}
+}
+
+// longText builds prose followed by a code block, long enough to need
+// splitting
+func longText() string {
+	var b strings.Builder
+	for i := 0; i < 12; i++ {
+		b.WriteString(strings.Repeat("lorem ipsum ", 15) + "\n\n")
+	}
+	b.WriteString("```go\n")
+	for i := 0; i < 80; i++ {
+		b.WriteString("fmt.Println(\"hello, world\")\n")
+	}
+	b.WriteString("```\nend")
+	return b.String()
+}
+
+func TestMessageSendLongContentFile(t *testing.T) {
+	env := newTestEnv(t)
+	text := longText()
+	path := writeTempFile(t, "notes.md", text+"\n")
+	before := len(env.srv.Messages(fake.ChannelGeneral))
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, "--content-file", path)
+
+	var data struct {
+		Messages   []*discord.Message `json:"messages"`
+		MessageIDs []string           `json:"message_ids"`
+		Count      int                `json:"count"`
+	}
+	resp.decode(t, &data)
+	if data.Count < 2 || len(data.MessageIDs) != data.Count || len(data.Messages) != data.Count {
+		t.Fatalf("expected several messages, got %+v", data)
+	}
+
+	stored := env.srv.Messages(fake.ChannelGeneral)[before:]
+	if len(stored) != data.Count {
+		t.Fatalf("expected %d new messages, got %d", data.Count, len(stored))
+	}
+	var joined []string
+	for i, m := range stored {
+		if m.ID != data.MessageIDs[i] {
+			t.Errorf("part %d: expected ID %s, got %s", i, data.MessageIDs[i], m.ID)
+		}
+		if n := len([]rune(m.Content)); n > discord.MaxContentLength {
+			t.Errorf("part %d is %d characters", i, n)
+		}
+		if strings.Count(m.Content, "```")%2 != 0 {
+			t.Errorf("part %d has an unbalanced code fence", i)
+		}
+		joined = append(joined, m.Content)
+	}
+	if !strings.HasPrefix(joined[0], "lorem ipsum") || !strings.HasSuffix(joined[len(joined)-1], "end") {
+		t.Errorf("parts out of order: %q ... %q", joined[0], joined[len(joined)-1])
+	}
+}
+
+func TestMessageReplyLongFromStdin(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+	env.stdin = longText()
+
+	resp := env.mustRun(t, "message", "reply", fake.ChannelGeneral, target.ID, "--content-file", "-")
+
+	var data struct {
+		Messages []*discord.Message `json:"messages"`
+	}
+	resp.decode(t, &data)
+	if len(data.Messages) < 2 {
+		t.Fatalf("expected a split reply, got %+v", data)
+	}
+	if ref := data.Messages[0].Reference; ref == nil || ref.MessageID != target.ID {
+		t.Errorf("first part should reply to %s, got %+v", target.ID, ref)
+	}
+	if data.Messages[1].Reference != nil {
+		t.Error("later parts should not be replies")
+	}
+}
+
+func TestMessageSendLongDryRunAndApproval(t *testing.T) {
+	env := newTestEnv(t)
+	text := longText()
+	path := writeTempFile(t, "notes.md", text)
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, "--content-file", path, "--dry-run")
+	var data struct {
+		Content string   `json:"content"`
+		Parts   []string `json:"parts"`
+	}
+	resp.decode(t, &data)
+	if data.Content != text || len(data.Parts) < 2 {
+		t.Errorf("expected full content and parts in dry-run output, got %d parts", len(data.Parts))
+	}
+
+	resp, _ = env.run(t, "message", "send", fake.ChannelGeneral, "--content-file", path, "--no-split")
+	if resp.OK || !strings.Contains(resp.Error, "the limit is 2000") {
+		t.Errorf("expected --no-split to reject long text, got %+v", resp)
+	}
+
+	resp, _ = env.run(t, "message", "send", fake.ChannelGeneral, "text", "--content-file", path)
+	if resp.OK || !strings.Contains(resp.Error, "not both") {
+		t.Errorf("expected argument and --content-file to conflict, got %+v", resp)
+	}
+
+	resp, _ = env.run(t, "message", "send", fake.ChannelGeneral, "--content-file", "-", "--file", "-")
+	if resp.OK || !strings.Contains(resp.Error, "stdin can only be read once") {
+		t.Errorf("expected stdin conflict, got %+v", resp)
+	}
+
+	before := len(env.srv.Messages(fake.ChannelGeneral))
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	env.stdin = "n\n"
+	_, stdout := env.run(t, "message", "send", fake.ChannelGeneral, "--content-file", path)
+	if !strings.Contains(stdout, "Split into") || !strings.Contains(stdout, "[1/") {
+		t.Errorf("expected prompt to list the parts, got %q", stdout)
+	}
+	if strings.Count(stdout, "Proceed?") != 1 {
+		t.Errorf("expected a single approval prompt, got %q", stdout)
+	}
+	if got := len(env.srv.Messages(fake.ChannelGeneral)); got != before {
+		t.Errorf("declined send should not post anything, got %d new messages", got-before)
+	}
+}
+
+func TestMessageSendIdempotencyKey(t *testing.T) {
+	env := newTestEnv(t)
+	before := len(env.srv.Messages(fake.ChannelGeneral))
+
+	var first, second discord.Message
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, "deploy done", "--idempotency-key", "deploy-42").decode(t, &first)
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, "deploy done", "--idempotency-key", "deploy-42")
+	resp.decode(t, &second)
+	if second.ID != first.ID {
+		t.Errorf("expected the original message %s on retry, got %s", first.ID, second.ID)
+	}
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "already used") {
+		t.Errorf("expected a replay warning, got %v", resp.Warnings)
+	}
+	if got := len(env.srv.Messages(fake.ChannelGeneral)) - before; got != 1 {
+		t.Errorf("expected one message to be posted, got %d", got)
+	}
+
+	resp, _ = env.run(t, "message", "send", fake.ChannelGeneral, "something else", "--idempotency-key", "deploy-42")
+	if resp.OK || !strings.Contains(resp.Error, "different message") {
+		t.Errorf("expected reusing a key for other content to fail, got %+v", resp)
+	}
+
+	// Without the ledger, Discord's nonce still catches the retry
+	if err := os.Remove(filepath.Join(filepath.Dir(env.configPath), "sent.json")); err != nil {
+		t.Fatal(err)
+	}
+	var third discord.Message
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, "deploy done", "--idempotency-key", "deploy-42").decode(t, &third)
+	if third.ID != first.ID {
+		t.Errorf("expected the nonce to return %s, got %s", first.ID, third.ID)
+	}
+}
+
+func TestMessageSendIdempotencyKeyPartial(t *testing.T) {
+	env := newTestEnv(t)
+	path := writeTempFile(t, "notes.md", longText())
+	before := len(env.srv.Messages(fake.ChannelGeneral))
+
+	// The send breaks off at part 2
+	env.srv.FailMessage(2)
+	resp, _ := env.run(t, "message", "send", fake.ChannelGeneral, "--content-file", path, "--idempotency-key", "notes-1")
+	if resp.OK || !strings.Contains(resp.Error, "sent 1 of") {
+		t.Fatalf("expected the send to fail after one part, got %+v", resp)
+	}
+	first := env.srv.Messages(fake.ChannelGeneral)[before]
+
+	// Once Discord forgets the nonces, the retry must not post part 1 again
+	env.srv.ForgetNonces()
+	resp = env.mustRun(t, "message", "send", fake.ChannelGeneral, "--content-file", path, "--idempotency-key", "notes-1")
+	var data struct {
+		MessageIDs []string `json:"message_ids"`
+		Count      int      `json:"count"`
+	}
+	resp.decode(t, &data)
+	if data.Count < 2 || data.MessageIDs[0] != first.ID {
+		t.Fatalf("expected every part, starting with %s, got %+v", first.ID, data)
+	}
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "sending the rest") {
+		t.Errorf("expected a resume warning, got %v", resp.Warnings)
+	}
+	if got := len(env.srv.Messages(fake.ChannelGeneral)) - before; got != data.Count {
+		t.Errorf("expected %d messages to be posted, got %d", data.Count, got)
+	}
+
+	// The completed send now replays as a whole
+	resp = env.mustRun(t, "message", "send", fake.ChannelGeneral, "--content-file", path, "--idempotency-key", "notes-1")
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "already used") {
+		t.Errorf("expected a replay warning, got %v", resp.Warnings)
+	}
+	if got := len(env.srv.Messages(fake.ChannelGeneral)) - before; got != data.Count {
+		t.Errorf("replay posted again: %d messages", got)
+	}
+}
+
+func TestMessageSendDuplicateWarning(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, "hello")
+	if len(resp.Warnings) != 0 {
+		t.Errorf("unexpected warnings on first send: %v", resp.Warnings)
+	}
+
+	resp = env.mustRun(t, "message", "send", fake.ChannelGeneral, "hello", "--dry-run")
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "identical content") {
+		t.Errorf("expected a duplicate warning, got %v", resp.Warnings)
+	}
+
+	resp = env.mustRun(t, "message", "send", fake.ChannelRandom, "hello")
+	if len(resp.Warnings) != 0 {
+		t.Errorf("other channels are not duplicates, got %v", resp.Warnings)
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	env.stdin = "n\n"
+	_, stdout := env.run(t, "message", "send", fake.ChannelGeneral, "hello")
+	if !strings.Contains(stdout, "identical content was sent here") {
+		t.Errorf("expected the prompt to warn about the duplicate, got %q", stdout)
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, DuplicateWindow: "0"})
+	resp = env.mustRun(t, "message", "send", fake.ChannelGeneral, "hello")
+	if len(resp.Warnings) != 0 {
+		t.Errorf("duplicate_window 0 should disable the check, got %v", resp.Warnings)
+	}
+}
+
+func TestMessageSendAllowedMentions(t *testing.T) {
+	env := newTestEnv(t)
+	content := "<@" + fake.UserAlice + "> <@&" + fake.RoleModerators + "> @everyone"
+
+	parse := func(msgID string) string {
+		t.Helper()
+		am := env.srv.AllowedMentions(msgID)
+		if am == nil {
+			t.Fatal("expected allowed_mentions to be sent")
+		}
+		var kinds []string
+		for _, p := range am.Parse {
+			kinds = append(kinds, string(p))
+		}
+		return strings.Join(kinds, ",")
+	}
+
+	var msg discord.Message
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, content).decode(t, &msg)
+	if got := parse(msg.ID); got != "users" {
+		t.Errorf("expected only user pings by default, got %q", got)
+	}
+
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, content, "--allow-mentions", "users,roles,everyone").decode(t, &msg)
+	if got := parse(msg.ID); got != "users,roles,everyone" {
+		t.Errorf("expected all mentions allowed, got %q", got)
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, AllowedMentions: "none"})
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, content).decode(t, &msg)
+	if got := parse(msg.ID); got != "" {
+		t.Errorf("expected no pings with allowed_mentions none, got %q", got)
+	}
+
+	resp, _ := env.run(t, "message", "send", fake.ChannelGeneral, content, "--allow-mentions", "all")
+	if resp.OK || !strings.Contains(resp.Error, "--allow-mentions") {
+		t.Errorf("expected an invalid --allow-mentions error, got %+v", resp)
+	}
+}
+
+func TestMessageReplyPing(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+
+	var msg discord.Message
+	env.mustRun(t, "message", "reply", fake.ChannelGeneral, target.ID, "ack").decode(t, &msg)
+	if am := env.srv.AllowedMentions(msg.ID); am == nil || !am.RepliedUser {
+		t.Errorf("replies should ping the author by default, got %+v", am)
+	}
+
+	env.mustRun(t, "message", "reply", fake.ChannelGeneral, target.ID, "ack", "--no-reply-ping").decode(t, &msg)
+	if am := env.srv.AllowedMentions(msg.ID); am == nil || am.RepliedUser {
+		t.Errorf("--no-reply-ping should not ping the author, got %+v", am)
+	}
+}
+
+func TestMessageSendMentionPreview(t *testing.T) {
+	env := newTestEnv(t)
+	content := "<@" + fake.UserAlice + "> <@&" + fake.RoleModerators + "> @everyone"
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, content, "--dry-run")
+	var data struct {
+		Mentions []*discord.Mention `json:"mentions"`
+	}
+	resp.decode(t, &data)
+	if len(data.Mentions) != 3 || data.Mentions[0].Name != "@Alice (ops)" || !data.Mentions[0].Notified ||
+		data.Mentions[1].Name != "@moderators" || data.Mentions[1].Notified || data.Mentions[2].Notified {
+		t.Errorf("unexpected mentions in dry-run output: %+v", data.Mentions)
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	env.stdin = "n\n"
+	_, stdout := env.run(t, "message", "send", fake.ChannelGeneral, content)
+	for _, want := range []string{"Notifies: @Alice (ops)", "without notification: @moderators (role), @everyone"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected prompt to contain %q, got %q", want, stdout)
+		}
+	}
+
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+	env.stdin = "n\n"
+	_, stdout = env.run(t, "message", "reply", fake.ChannelGeneral, target.ID, "ok", "--no-reply-ping")
+	if !strings.Contains(stdout, "without notification: @"+target.Author.Username+" (reply author)") {
+		t.Errorf("expected prompt to show the silenced reply author, got %q", stdout)
+	}
+}
+
+func TestMessageSendSilent(t *testing.T) {
+	env := newTestEnv(t)
+
+	var msg discord.Message
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, "see https://example.com", "--silent", "--suppress-embeds").decode(t, &msg)
+	if strings.Join(msg.Flags, ",") != "suppress_embeds,silent" {
+		t.Errorf("expected suppress_embeds and silent flags, got %v", msg.Flags)
+	}
+
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+	env.mustRun(t, "message", "reply", fake.ChannelGeneral, target.ID, "ack", "--silent").decode(t, &msg)
+	if strings.Join(msg.Flags, ",") != "silent" {
+		t.Errorf("expected a silent reply, got %v", msg.Flags)
+	}
+
+	msg = discord.Message{}
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, "loud").decode(t, &msg)
+	if len(msg.Flags) != 0 {
+		t.Errorf("expected no flags by default, got %v", msg.Flags)
+	}
+}
+
+func TestMessageSendQuietHours(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now().UTC()
+	span := func(from, to time.Duration) string {
+		return now.Add(from).Format("15:04") + "-" + now.Add(to).Format("15:04")
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, QuietHours: span(-time.Hour, time.Hour), QuietHoursTimezone: "UTC"})
+	var msg discord.Message
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, "late night").decode(t, &msg)
+	if strings.Join(msg.Flags, ",") != "silent" {
+		t.Errorf("expected a silent message during quiet hours, got %v", msg.Flags)
+	}
+	msg = discord.Message{}
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, "urgent", "--silent=false").decode(t, &msg)
+	if len(msg.Flags) != 0 {
+		t.Errorf("--silent=false should override quiet hours, got %v", msg.Flags)
+	}
+
+	resp := env.mustRun(t, "message", "send", fake.ChannelGeneral, "late night", "--dry-run")
+	var data struct {
+		Silent bool `json:"silent"`
+	}
+	resp.decode(t, &data)
+	if !data.Silent {
+		t.Error("expected dry-run output to report silent")
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, QuietHours: span(-time.Hour, time.Hour), QuietHoursTimezone: "UTC", RequireApproval: true})
+	env.stdin = "n\n"
+	_, stdout := env.run(t, "message", "send", fake.ChannelGeneral, "late night")
+	if !strings.Contains(stdout, "(quiet hours)") {
+		t.Errorf("expected prompt to mention quiet hours, got %q", stdout)
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, QuietHours: span(time.Hour, 2*time.Hour), QuietHoursTimezone: "UTC"})
+	msg = discord.Message{}
+	env.mustRun(t, "message", "send", fake.ChannelGeneral, "daytime").decode(t, &msg)
+	if len(msg.Flags) != 0 {
+		t.Errorf("expected no flags outside quiet hours, got %v", msg.Flags)
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, QuietHours: "22:00"})
+	resp, _ = env.run(t, "message", "send", fake.ChannelGeneral, "hi")
+	if resp.OK || !strings.Contains(resp.Error, "quiet_hours") {
+		t.Errorf("expected an invalid quiet_hours error, got %+v", resp)
+	}
+}
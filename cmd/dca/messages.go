@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/discord"
+)
+
+// addRenderFlag registers --render on commands that list messages
+func addRenderFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("render", false, "Resolve mentions, channels, roles and custom emoji in content (raw text kept in raw_content)")
+}
+
+// renderMessages resolves mention and emoji markup when --render is set
+func renderMessages(cmd *cobra.Command, client discord.API, msgs ...*discord.Message) {
+	if render, _ := cmd.Flags().GetBool("render"); !render {
+		return
+	}
+	r := discord.NewRenderer(client)
+	for _, m := range msgs {
+		r.Render(m)
+	}
+}
+
+// compactMessages strips message metadata when --compact is set
+func compactMessages(cmd *cobra.Command, msgs ...*discord.Message) {
+	if compact, _ := cmd.Flags().GetBool("compact"); !compact {
+		return
+	}
+	for _, m := range msgs {
+		if m != nil {
+			m.Compact()
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+)
+
+// addNotificationFlags registers --silent and --suppress-embeds on a send
+// command
+func addNotificationFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("silent", false, "Send without push or desktop notifications (default on during quiet_hours)")
+	cmd.Flags().Bool("suppress-embeds", false, "Hide link previews")
+}
+
+// applyNotificationFlags sets the silent and suppress-embeds flags of out.
+// Without an explicit --silent, messages are silent during quiet hours.
+func applyNotificationFlags(cmd *cobra.Command, cfg *config.Config, out *discord.OutgoingMessage) {
+	if cmd.Flags().Changed("silent") {
+		out.Silent, _ = cmd.Flags().GetBool("silent")
+	} else {
+		out.Silent = cfg.InQuietHours(time.Now())
+	}
+	out.SuppressEmbeds, _ = cmd.Flags().GetBool("suppress-embeds")
+}
+
+// printNotificationFlags shows in an approval prompt how a message will be
+// delivered
+func printNotificationFlags(cmd *cobra.Command, out *discord.OutgoingMessage) {
+	if out.Silent {
+		if cmd.Flags().Changed("silent") {
+			fmt.Println("   🔕 Silent: no push or desktop notifications")
+		} else {
+			fmt.Println("   🔕 Silent: no push or desktop notifications (quiet hours)")
+		}
+	}
+	if out.SuppressEmbeds {
+		fmt.Println("   🔗 Link previews suppressed")
+	}
+	if out.Silent || out.SuppressEmbeds {
+		fmt.Println()
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/output"
+)
+
+var pinsCmd = &cobra.Command{
+	Use:   "pins",
+	Short: "Pinned message operations",
+	Long:  "List, pin and unpin a channel's pinned messages",
+}
+
+var pinsListCmd = &cobra.Command{
+	Use:   "list <channel>",
+	Short: "List pinned messages",
+	Long: `List a channel's pinned messages, most recently pinned first.
+
+The channel may be an ID, a link, "server/#channel", "#channel" or
+@username for a DM.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPinsList,
+}
+
+var pinsAddCmd = &cobra.Command{
+	Use:   "add (<message-link> | <channel> <message-id>)",
+	Short: "Pin a message",
+	Long:  "Pin a message in its channel (requires approval unless --dry-run)",
+	Args:  messageArgs(0, 0),
+	RunE:  runPinsAdd,
+}
+
+var pinsRemoveCmd = &cobra.Command{
+	Use:   "remove (<message-link> | <channel> <message-id>)",
+	Short: "Unpin a message",
+	Long:  "Unpin a message (requires approval unless --dry-run)",
+	Args:  messageArgs(0, 0),
+	RunE:  runPinsRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(pinsCmd)
+	pinsCmd.AddCommand(pinsListCmd)
+	pinsCmd.AddCommand(pinsAddCmd)
+	pinsCmd.AddCommand(pinsRemoveCmd)
+
+	addRenderFlag(pinsListCmd)
+	pinsAddCmd.Flags().Bool("dry-run", false, "Show what would be pinned without actually pinning")
+	pinsRemoveCmd.Flags().Bool("dry-run", false, "Show what would be unpinned without actually unpinning")
+}
+
+func runPinsList(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	channelRef := args[0]
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	channelID, err := newResolver(client).channel(channelRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// List pinned messages
+	msgs, err := client.ListPins(channelID)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	renderMessages(cmd, client, msgs...)
+	compactMessages(cmd, msgs...)
+
+	return output.PrintSuccess(map[string]interface{}{
+		"channel_id": channelID,
+		"messages":   msgs,
+		"count":      len(msgs),
+	}, pretty)
+}
+
+func runPinsAdd(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	channelRef, messageRef, _ := splitMessageArgs(args)
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	channelID, messageID, err := newResolver(client).message(channelRef, messageRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get original message for approval prompt
+	originalMsg, err := client.GetMessage(channelID, messageID)
+	if err != nil {
+		return output.PrintError(fmt.Errorf("failed to get message: %w", err), pretty)
+	}
+
+	var warnings []string
+	if originalMsg.Pinned {
+		warnings = append(warnings, "message is already pinned")
+	}
+
+	// Dry run
+	if dryRun {
+		return output.PrintSuccessWithWarnings(map[string]interface{}{
+			"action":     "pin_message",
+			"channel_id": channelID,
+			"message_id": messageID,
+			"content":    originalMsg.Content,
+			"dry_run":    true,
+		}, warnings, pretty)
+	}
+
+	// Check approval requirement
+	if cfg.RequireApproval {
+		fmt.Printf("📌 Pin message %s in channel %s:\n", messageID, channelID)
+		fmt.Printf("   \"%s\"\n\n", originalMsg.Content)
+		fmt.Print("Proceed? [y/N]: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			return output.PrintSuccess(map[string]interface{}{
+				"action":    "pin_message",
+				"cancelled": true,
+			}, pretty)
+		}
+	}
+
+	// Pin message
+	err = client.PinMessage(channelID, messageID)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	return output.PrintSuccessWithWarnings(map[string]interface{}{
+		"action":     "pin_message",
+		"channel_id": channelID,
+		"message_id": messageID,
+		"pinned":     true,
+	}, warnings, pretty)
+}
+
+func runPinsRemove(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	channelRef, messageRef, _ := splitMessageArgs(args)
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	channelID, messageID, err := newResolver(client).message(channelRef, messageRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get original message for approval prompt
+	originalMsg, err := client.GetMessage(channelID, messageID)
+	if err != nil {
+		return output.PrintError(fmt.Errorf("failed to get message: %w", err), pretty)
+	}
+
+	var warnings []string
+	if !originalMsg.Pinned {
+		warnings = append(warnings, "message is not pinned")
+	}
+
+	// Dry run
+	if dryRun {
+		return output.PrintSuccessWithWarnings(map[string]interface{}{
+			"action":     "unpin_message",
+			"channel_id": channelID,
+			"message_id": messageID,
+			"content":    originalMsg.Content,
+			"dry_run":    true,
+		}, warnings, pretty)
+	}
+
+	// Check approval requirement
+	if cfg.RequireApproval {
+		fmt.Printf("📍 Unpin message %s in channel %s:\n", messageID, channelID)
+		fmt.Printf("   \"%s\"\n\n", originalMsg.Content)
+		fmt.Print("Proceed? [y/N]: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			return output.PrintSuccess(map[string]interface{}{
+				"action":    "unpin_message",
+				"cancelled": true,
+			}, pretty)
+		}
+	}
+
+	// Unpin message
+	err = client.UnpinMessage(channelID, messageID)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	return output.PrintSuccessWithWarnings(map[string]interface{}{
+		"action":     "unpin_message",
+		"channel_id": channelID,
+		"message_id": messageID,
+		"unpinned":   true,
+	}, warnings, pretty)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+type pinsListData struct {
+	Messages []*discord.Message `json:"messages"`
+	Count    int                `json:"count"`
+}
+
+func TestPinsList(t *testing.T) {
+	env := newTestEnv(t)
+	msgs := env.srv.Messages(fake.ChannelGeneral)
+	env.srv.Pin(fake.ChannelGeneral, msgs[0].ID)
+	env.srv.Pin(fake.ChannelGeneral, msgs[1].ID)
+
+	var data pinsListData
+	env.mustRun(t, "pins", "list", fake.ChannelGeneral).decode(t, &data)
+	if data.Count != 2 || data.Messages[0].ID != msgs[1].ID || data.Messages[1].ID != msgs[0].ID {
+		t.Fatalf("expected both pins, most recently pinned first, got %+v", data.Messages)
+	}
+	pin := data.Messages[1]
+	if !pin.Pinned || pin.Content != "good morning everyone" || pin.Author.DisplayName != "Alice (ops)" || pin.URL == "" {
+		t.Errorf("expected a full message, got %+v", pin)
+	}
+
+	data = pinsListData{}
+	env.mustRun(t, "pins", "list", fake.ChannelRandom).decode(t, &data)
+	if data.Count != 0 || data.Messages == nil {
+		t.Errorf("expected an empty list, got %+v", data)
+	}
+}
+
+func TestPinsAddRemove(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+
+	var data map[string]interface{}
+	env.mustRun(t, "pins", "add", fake.ChannelGeneral, target.ID).decode(t, &data)
+	if data["pinned"] != true || !env.srv.Message(fake.ChannelGeneral, target.ID).Pinned {
+		t.Fatalf("expected the message pinned, got %v", data)
+	}
+
+	resp := env.mustRun(t, "pins", "add", fake.ChannelGeneral, target.ID)
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "already pinned") {
+		t.Errorf("expected an already pinned warning, got %v", resp.Warnings)
+	}
+
+	data = nil
+	env.mustRun(t, "pins", "remove", fake.ChannelGeneral, target.ID).decode(t, &data)
+	if data["unpinned"] != true || env.srv.Message(fake.ChannelGeneral, target.ID).Pinned {
+		t.Errorf("expected the message unpinned, got %v", data)
+	}
+
+	resp, _ = env.run(t, "pins", "add", fake.ChannelGeneral, "999")
+	if resp.OK {
+		t.Error("expected pinning a missing message to fail")
+	}
+}
+
+func TestPinsDryRunAndApproval(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+
+	resp := env.mustRun(t, "pins", "add", fake.ChannelGeneral, target.ID, "--dry-run")
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["dry_run"] != true || data["content"] != target.Content {
+		t.Errorf("unexpected dry-run output: %v", data)
+	}
+	if env.srv.Message(fake.ChannelGeneral, target.ID).Pinned {
+		t.Fatal("dry run pinned the message")
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	env.stdin = "n\n"
+	resp, stdout := env.run(t, "pins", "add", fake.ChannelGeneral, target.ID)
+	if !strings.Contains(stdout, "Pin message "+target.ID) {
+		t.Errorf("expected an approval prompt, got %q", stdout)
+	}
+	data = nil
+	resp.decode(t, &data)
+	if data["cancelled"] != true || env.srv.Message(fake.ChannelGeneral, target.ID).Pinned {
+		t.Errorf("expected the pin to be cancelled, got %v", data)
+	}
+}
@@ -18,37 +18,104 @@ var reactionCmd = &cobra.Command{
 	Long:  "Add, remove, and list message reactions",
 }
 
+var reactionListCmd = &cobra.Command{
+	Use:   "list (<message-link> | <channel> <message-id>)",
+	Short: "List reactions",
+	Long:  "List a message's reactions with the users who reacted",
+	Args:  messageArgs(0, 0),
+	RunE:  runReactionList,
+}
+
 var reactionAddCmd = &cobra.Command{
-	Use:   "add <channel-id> <message-id> <emoji>",
+	Use:   "add (<message-link> | <channel> <message-id>) <emoji>",
 	Short: "Add a reaction",
-	Long:  "Add an emoji reaction to a message (requires approval unless --dry-run)",
-	Args:  cobra.ExactArgs(3),
-	RunE:  runReactionAdd,
+	Long: `Add an emoji reaction to a message (requires approval unless --dry-run).
+
+The emoji can be a unicode emoji, a shortcode like :thumbsup:, or the name
+of one of the server's custom emoji like :partyparrot:.`,
+	Args: messageArgs(1, 1),
+	RunE: runReactionAdd,
 }
 
 var reactionRemoveCmd = &cobra.Command{
-	Use:   "remove <channel-id> <message-id> <emoji>",
+	Use:   "remove (<message-link> | <channel> <message-id>) <emoji>",
 	Short: "Remove a reaction",
-	Long:  "Remove your emoji reaction from a message (requires approval unless --dry-run)",
-	Args:  cobra.ExactArgs(3),
-	RunE:  runReactionRemove,
+	Long: `Remove your emoji reaction from a message (requires approval unless --dry-run).
+
+The emoji is given as for reaction add.`,
+	Args: messageArgs(1, 1),
+	RunE: runReactionRemove,
 }
 
 func init() {
 	rootCmd.AddCommand(reactionCmd)
+	reactionCmd.AddCommand(reactionListCmd)
 	reactionCmd.AddCommand(reactionAddCmd)
 	reactionCmd.AddCommand(reactionRemoveCmd)
 
+	reactionListCmd.Flags().String("emoji", "", "Only list this emoji's reactions")
+	reactionListCmd.Flags().Int("limit", 100, "Users to list per emoji (0 for all)")
+
 	reactionAddCmd.Flags().Bool("dry-run", false, "Show what would be added without actually adding")
 	reactionRemoveCmd.Flags().Bool("dry-run", false, "Show what would be removed without actually removing")
 }
 
+func runReactionList(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	emoji, _ := cmd.Flags().GetString("emoji")
+	limit, _ := cmd.Flags().GetInt("limit")
+	channelRef, messageRef, _ := splitMessageArgs(args)
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	channelID, messageID, err := newResolver(client).message(channelRef, messageRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// List reactions
+	reactions, err := client.ListReactions(channelID, messageID, discord.ReactionListOptions{
+		Emoji: emoji,
+		Limit: limit,
+	})
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	return output.PrintSuccess(map[string]interface{}{
+		"channel_id": channelID,
+		"message_id": messageID,
+		"reactions":  reactions,
+		"count":      len(reactions),
+	}, pretty)
+}
+
 func runReactionAdd(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	channelID := args[0]
-	messageID := args[1]
-	emoji := args[2]
+	channelRef, messageRef, rest := splitMessageArgs(args)
+	emoji := rest[0]
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -56,6 +123,32 @@ func runReactionAdd(cmd *cobra.Command, args []string) error {
 		return output.PrintError(err, pretty)
 	}
 
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	channelID, messageID, err := newResolver(client).message(channelRef, messageRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	emoji, err = client.ResolveEmoji(channelID, emoji)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
 	// Dry run
 	if dryRun {
 		return output.PrintSuccess(map[string]interface{}{
@@ -87,23 +180,6 @@ func runReactionAdd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Get token
-	token, _ := cmd.Flags().GetString("token")
-	if token == "" {
-		token = cfg.UserToken
-	}
-
-	if token == "" {
-		return output.PrintError(fmt.Errorf("no token configured"), pretty)
-	}
-
-	// Create Discord client
-	client, err := discord.New(token)
-	if err != nil {
-		return output.PrintError(err, pretty)
-	}
-	defer client.Close()
-
 	// Add reaction
 	err = client.AddReaction(channelID, messageID, emoji)
 	if err != nil {
@@ -122,9 +198,8 @@ func runReactionAdd(cmd *cobra.Command, args []string) error {
 func runReactionRemove(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	channelID := args[0]
-	messageID := args[1]
-	emoji := args[2]
+	channelRef, messageRef, rest := splitMessageArgs(args)
+	emoji := rest[0]
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -132,6 +207,32 @@ func runReactionRemove(cmd *cobra.Command, args []string) error {
 		return output.PrintError(err, pretty)
 	}
 
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	channelID, messageID, err := newResolver(client).message(channelRef, messageRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	emoji, err = client.ResolveEmoji(channelID, emoji)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
 	// Dry run
 	if dryRun {
 		return output.PrintSuccess(map[string]interface{}{
@@ -163,23 +264,6 @@ func runReactionRemove(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Get token
-	token, _ := cmd.Flags().GetString("token")
-	if token == "" {
-		token = cfg.UserToken
-	}
-
-	if token == "" {
-		return output.PrintError(fmt.Errorf("no token configured"), pretty)
-	}
-
-	// Create Discord client
-	client, err := discord.New(token)
-	if err != nil {
-		return output.PrintError(err, pretty)
-	}
-	defer client.Close()
-
 	// Remove reaction
 	err = client.RemoveReaction(channelID, messageID, emoji)
 	if err != nil {
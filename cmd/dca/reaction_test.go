@@ -0,0 +1,156 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+type reactionListData struct {
+	Reactions []*discord.ReactionUsers `json:"reactions"`
+	Count     int                      `json:"count"`
+}
+
+func TestReactionAddRemove(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+
+	resp := env.mustRun(t, "reaction", "add", fake.ChannelGeneral, target.ID, "👍")
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["added"] != true {
+		t.Errorf("unexpected output: %v", data)
+	}
+
+	stored := env.srv.Message(fake.ChannelGeneral, target.ID)
+	if len(stored.Reactions) != 1 || stored.Reactions[0].Emoji.Name != "👍" || !stored.Reactions[0].Me {
+		t.Fatalf("reaction not stored: %+v", stored.Reactions)
+	}
+
+	env.mustRun(t, "reaction", "remove", fake.ChannelGeneral, target.ID, "👍")
+	if stored := env.srv.Message(fake.ChannelGeneral, target.ID); len(stored.Reactions) != 0 {
+		t.Errorf("reaction not removed: %+v", stored.Reactions)
+	}
+}
+
+func TestReactionAddDryRun(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+
+	resp := env.mustRun(t, "reaction", "add", fake.ChannelGeneral, target.ID, "🎉", "--dry-run")
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["dry_run"] != true || data["emoji"] != "🎉" {
+		t.Errorf("unexpected dry-run output: %v", data)
+	}
+	if stored := env.srv.Message(fake.ChannelGeneral, target.ID); len(stored.Reactions) != 0 {
+		t.Errorf("dry run added a reaction")
+	}
+}
+
+func TestReactionAddUnknownMessage(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp, _ := env.run(t, "reaction", "add", fake.ChannelGeneral, "999", "👍")
+	if resp.OK {
+		t.Fatal("expected reacting to a missing message to fail")
+	}
+}
+
+func TestReactionShortcodes(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+
+	resp := env.mustRun(t, "reaction", "add", fake.ChannelGeneral, target.ID, ":thumbsup:", "--dry-run")
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["emoji"] != "👍" {
+		t.Errorf("expected the shortcode resolved in the dry run, got %v", data["emoji"])
+	}
+
+	env.mustRun(t, "reaction", "add", fake.ChannelGeneral, target.ID, ":thumbsup:")
+	env.mustRun(t, "reaction", "add", fake.ChannelGeneral, target.ID, ":partyparrot:")
+	stored := env.srv.Message(fake.ChannelGeneral, target.ID)
+	if len(stored.Reactions) != 2 || stored.Reactions[0].Emoji.Name != "👍" || stored.Reactions[1].Emoji.ID != fake.EmojiPartyParrot {
+		t.Fatalf("expected a thumbs up and the server's party parrot, got %+v", stored.Reactions)
+	}
+
+	env.mustRun(t, "reaction", "remove", fake.ChannelGeneral, target.ID, "partyparrot")
+	if stored := env.srv.Message(fake.ChannelGeneral, target.ID); len(stored.Reactions) != 1 {
+		t.Errorf("expected the party parrot removed, got %+v", stored.Reactions)
+	}
+
+	resp, _ = env.run(t, "reaction", "add", fake.ChannelGeneral, target.ID, ":nosuchemoji:")
+	if resp.OK || !strings.Contains(resp.Error, "unknown emoji :nosuchemoji:") {
+		t.Errorf("expected an unknown emoji error, got %+v", resp)
+	}
+
+	// DMs have no server emoji to fall back to
+	dm := env.srv.Messages(fake.ChannelDMAlice)[0]
+	resp, _ = env.run(t, "reaction", "add", fake.ChannelDMAlice, dm.ID, ":partyparrot:")
+	if resp.OK || !strings.Contains(resp.Error, "unknown emoji") {
+		t.Errorf("expected custom emoji to fail in a DM, got %+v", resp)
+	}
+}
+
+func TestReactionList(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+	env.srv.React(fake.ChannelGeneral, target.ID, fake.UserAlice, "👍")
+	env.srv.React(fake.ChannelGeneral, target.ID, fake.UserBob, "👍")
+	env.srv.React(fake.ChannelGeneral, target.ID, fake.UserBob, "partyparrot:"+fake.EmojiPartyParrot)
+	env.mustRun(t, "reaction", "add", fake.ChannelGeneral, target.ID, "👍")
+
+	var data reactionListData
+	env.mustRun(t, "reaction", "list", fake.ChannelGeneral, target.ID).decode(t, &data)
+	if data.Count != 2 {
+		t.Fatalf("expected 2 emoji, got %+v", data.Reactions)
+	}
+	thumbs := data.Reactions[0]
+	if thumbs.Emoji != "👍" || thumbs.Count != 3 || !thumbs.Me || len(thumbs.Users) != 3 || thumbs.HasMore {
+		t.Fatalf("unexpected thumbs up reactions: %+v", thumbs)
+	}
+	if thumbs.Users[0].ID != fake.UserMe || thumbs.Users[1].DisplayName != "Alice (ops)" {
+		t.Errorf("expected users by ID with server nicknames, got %+v %+v", thumbs.Users[0], thumbs.Users[1])
+	}
+
+	data = reactionListData{}
+	env.mustRun(t, "reaction", "list", fake.ChannelGeneral, target.ID, "--emoji", ":partyparrot:").decode(t, &data)
+	if data.Count != 1 || data.Reactions[0].Emoji != "partyparrot:"+fake.EmojiPartyParrot || data.Reactions[0].Me {
+		t.Errorf("expected only the party parrot, got %+v", data.Reactions)
+	}
+
+	resp, _ := env.run(t, "reaction", "list", fake.ChannelGeneral, target.ID, "--emoji", "tada")
+	if resp.OK || !strings.Contains(resp.Error, "no 🎉 reactions") {
+		t.Errorf("expected a missing reaction error, got %+v", resp)
+	}
+}
+
+func TestReactionListPagination(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+	for i := 0; i < 150; i++ {
+		id := strconv.Itoa(1000 + i)
+		env.srv.AddUser(&discordgo.User{ID: id, Username: "user" + id})
+		env.srv.React(fake.ChannelGeneral, target.ID, id, "🔥")
+	}
+
+	var data reactionListData
+	env.mustRun(t, "reaction", "list", fake.ChannelGeneral, target.ID, "--limit", "0").decode(t, &data)
+	if data.Count != 1 || len(data.Reactions[0].Users) != 150 || data.Reactions[0].HasMore {
+		t.Fatalf("expected all 150 users across pages, got %+v", data.Reactions)
+	}
+	if data.Reactions[0].Users[149].ID != "1149" {
+		t.Errorf("expected users in ID order, got %s last", data.Reactions[0].Users[149].ID)
+	}
+
+	data = reactionListData{}
+	env.mustRun(t, "reaction", "list", fake.ChannelGeneral, target.ID, "--limit", "120").decode(t, &data)
+	if len(data.Reactions[0].Users) != 120 || !data.Reactions[0].HasMore {
+		t.Errorf("expected 120 users with more available, got %d", len(data.Reactions[0].Users))
+	}
+}
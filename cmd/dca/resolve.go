@@ -0,0 +1,362 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/discord"
+)
+
+// discordLinkPattern matches channel and message links copied from any
+// Discord client
+var discordLinkPattern = regexp.MustCompile(`^https?://(?:(?:ptb|canary)\.)?discord(?:app)?\.com/channels/(@me|\d+)/(\d+)(?:/(\d+))?/?$`)
+
+// discordLink is a parsed channel or message link. GuildID is "@me" for DMs.
+type discordLink struct {
+	GuildID   string
+	ChannelID string
+	MessageID string
+}
+
+func parseDiscordLink(s string) (*discordLink, bool) {
+	m := discordLinkPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return nil, false
+	}
+	return &discordLink{GuildID: m[1], ChannelID: m[2], MessageID: m[3]}, true
+}
+
+// isMessageLink reports whether s is a link to a single message
+func isMessageLink(s string) bool {
+	link, ok := parseDiscordLink(s)
+	return ok && link.MessageID != ""
+}
+
+// messageArgs accepts either <channel> <message-id> or a single message link
+// in their place, followed by between minRest and maxRest more arguments
+func messageArgs(minRest, maxRest int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		rest := len(args) - 2
+		if len(args) > 0 && isMessageLink(args[0]) {
+			rest = len(args) - 1
+		}
+		if rest < minRest || rest > maxRest {
+			if minRest == maxRest {
+				return fmt.Errorf("expected a message link or <channel> <message-id>, followed by %d more argument(s)", minRest)
+			}
+			return fmt.Errorf("expected a message link or <channel> <message-id>, followed by %d to %d more arguments", minRest, maxRest)
+		}
+		return nil
+	}
+}
+
+// splitMessageArgs returns the channel and message references accepted by
+// messageArgs and the arguments after them
+func splitMessageArgs(args []string) (channelRef, messageRef string, rest []string) {
+	if isMessageLink(args[0]) {
+		return args[0], args[0], args[1:]
+	}
+	return args[0], args[1], args[2:]
+}
+
+// resolver turns the references users type into Discord IDs. Besides raw
+// IDs it accepts links, server names, server/#channel paths and @username
+// for DMs. Guild and channel listings are fetched once per command.
+type resolver struct {
+	client   discord.API
+	guilds   []*discord.Guild
+	channels map[string][]*discord.Channel
+}
+
+func newResolver(client discord.API) *resolver {
+	return &resolver{client: client, channels: make(map[string][]*discord.Channel)}
+}
+
+// ambiguous reports a name that matches more than one candidate
+func ambiguous(kind, ref string, candidates []string) error {
+	return fmt.Errorf("%s %q is ambiguous, candidates: %s", kind, ref, strings.Join(candidates, ", "))
+}
+
+// guild resolves a server ID, link or name
+func (r *resolver) guild(ref string) (string, error) {
+	if isNumeric(ref) {
+		return ref, nil
+	}
+	if link, ok := parseDiscordLink(ref); ok {
+		if link.GuildID == "@me" {
+			return "", fmt.Errorf("%s is a DM link, not a server", ref)
+		}
+		return link.GuildID, nil
+	}
+
+	guilds, err := r.listGuilds()
+	if err != nil {
+		return "", err
+	}
+	var matches []*discord.Guild
+	for _, g := range guilds {
+		if strings.EqualFold(g.Name, ref) {
+			matches = append(matches, g)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("server %q not found", ref)
+	case 1:
+		return matches[0].ID, nil
+	}
+	candidates := make([]string, 0, len(matches))
+	for _, g := range matches {
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", g.Name, g.ID))
+	}
+	return "", ambiguous("server", ref, candidates)
+}
+
+// channel resolves a channel ID, link, <#id> mention, server/#channel path,
+// #channel name searched across all servers, or @username for a DM
+func (r *resolver) channel(ref string) (string, error) {
+	if isNumeric(ref) {
+		return ref, nil
+	}
+	if link, ok := parseDiscordLink(ref); ok {
+		return link.ChannelID, nil
+	}
+	if id, ok := strings.CutPrefix(ref, "<#"); ok && strings.HasSuffix(id, ">") && isNumeric(strings.TrimSuffix(id, ">")) {
+		return strings.TrimSuffix(id, ">"), nil
+	}
+	if strings.HasPrefix(ref, "@") {
+		user, err := r.user(ref)
+		if err != nil {
+			return "", err
+		}
+		ch, err := r.client.GetDMChannel(user.ID)
+		if err != nil {
+			return "", err
+		}
+		return ch.ID, nil
+	}
+
+	var guilds []*discord.Guild
+	name := ref
+	if i := strings.LastIndex(ref, "/"); i >= 0 {
+		guildID, err := r.guild(ref[:i])
+		if err != nil {
+			return "", err
+		}
+		guilds = []*discord.Guild{{ID: guildID, Name: ref[:i]}}
+		name = ref[i+1:]
+	} else {
+		var err error
+		if guilds, err = r.listGuilds(); err != nil {
+			return "", err
+		}
+	}
+	name = strings.TrimPrefix(name, "#")
+
+	var candidates []string
+	var matchID string
+	for _, g := range guilds {
+		channels, err := r.listChannels(g.ID)
+		if err != nil {
+			if len(guilds) == 1 {
+				return "", err
+			}
+			continue
+		}
+		for _, ch := range channels {
+			if ch.Type == "category" || !strings.EqualFold(ch.Name, name) {
+				continue
+			}
+			matchID = ch.ID
+			candidates = append(candidates, fmt.Sprintf("%s/#%s (%s)", g.Name, ch.Name, ch.ID))
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("channel %q not found", ref)
+	case 1:
+		return matchID, nil
+	}
+	return "", ambiguous("channel", ref, candidates)
+}
+
+// message resolves a message given as a link, or as a channel reference and
+// message ID
+func (r *resolver) message(channelRef, messageRef string) (channelID, messageID string, err error) {
+	if link, ok := parseDiscordLink(messageRef); ok {
+		if link.MessageID == "" {
+			return "", "", fmt.Errorf("%s links to a channel, not a message", messageRef)
+		}
+		return link.ChannelID, link.MessageID, nil
+	}
+	if !isNumeric(messageRef) {
+		return "", "", fmt.Errorf("invalid message ID %q", messageRef)
+	}
+	channelID, err = r.channel(channelRef)
+	if err != nil {
+		return "", "", err
+	}
+	return channelID, messageRef, nil
+}
+
+// user resolves a user ID, <@id> mention, or a username or display name
+// with or without a leading @
+func (r *resolver) user(ref string) (*discord.Author, error) {
+	ref = strings.TrimSpace(ref)
+	if id, ok := userID(ref); ok {
+		return &discord.Author{ID: id}, nil
+	}
+	name := strings.TrimPrefix(ref, "@")
+	candidates, err := r.client.LookupUsers(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Exact usernames win over display names and nicknames; prefix and
+	// partial matches are only suggestions
+	for _, kinds := range [][]string{
+		{discord.MatchUsername},
+		{discord.MatchGlobalName, discord.MatchNick},
+	} {
+		var matches []*discord.UserCandidate
+		for _, c := range candidates {
+			if slices.Contains(kinds, c.Match) {
+				matches = append(matches, c)
+			}
+		}
+		switch {
+		case len(matches) == 1:
+			return &matches[0].Author, nil
+		case len(matches) > 1:
+			names := make([]string, 0, len(matches))
+			for _, c := range matches {
+				names = append(names, fmt.Sprintf("@%s (%s)", c.Username, c.ID))
+			}
+			return nil, ambiguous("user", ref, names)
+		}
+	}
+
+	if len(candidates) > 0 {
+		names := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			names = append(names, "@"+c.Username)
+		}
+		return nil, fmt.Errorf("user %q not found, similar: %s; pass a user ID for someone you share no server, DM or friendship with", ref, strings.Join(names, ", "))
+	}
+	return nil, fmt.Errorf("user %q not found among friends, DMs or server members; pass their user ID instead", ref)
+}
+
+// group resolves a group DM by channel ID, link or name
+func (r *resolver) group(ref string) (*discord.GroupDM, error) {
+	if link, ok := parseDiscordLink(ref); ok {
+		return r.client.GetGroupDM(link.ChannelID)
+	}
+	if isNumeric(ref) {
+		return r.client.GetGroupDM(ref)
+	}
+	groups, err := r.groupsNamed(ref)
+	if err != nil {
+		return nil, err
+	}
+	switch len(groups) {
+	case 0:
+		return nil, fmt.Errorf("group DM %q not found", ref)
+	case 1:
+		return groups[0], nil
+	}
+	return nil, ambiguousGroup(ref, groups)
+}
+
+// dm resolves the channel of a conversation: a group DM by ID, link or
+// name, else the DM with a user
+func (r *resolver) dm(ref string) (string, error) {
+	if link, ok := parseDiscordLink(ref); ok {
+		return link.ChannelID, nil
+	}
+	if id, ok := userID(ref); ok {
+		if group, err := r.client.GetGroupDM(id); err == nil {
+			return group.ID, nil
+		}
+	} else if !strings.HasPrefix(ref, "@") {
+		groups, err := r.groupsNamed(ref)
+		if err != nil {
+			return "", err
+		}
+		switch len(groups) {
+		case 0:
+		case 1:
+			return groups[0].ID, nil
+		default:
+			return "", ambiguousGroup(ref, groups)
+		}
+	}
+
+	user, err := r.user(ref)
+	if err != nil {
+		return "", err
+	}
+	ch, err := r.client.GetDMChannel(user.ID)
+	if err != nil {
+		return "", err
+	}
+	return ch.ID, nil
+}
+
+// groupsNamed lists the group DMs with a name
+func (r *resolver) groupsNamed(name string) ([]*discord.GroupDM, error) {
+	groups, err := r.client.ListGroupDMs()
+	if err != nil {
+		return nil, err
+	}
+	var matches []*discord.GroupDM
+	for _, g := range groups {
+		if strings.EqualFold(g.Name, name) {
+			matches = append(matches, g)
+		}
+	}
+	return matches, nil
+}
+
+// ambiguousGroup reports a name shared by several group DMs
+func ambiguousGroup(ref string, groups []*discord.GroupDM) error {
+	candidates := make([]string, 0, len(groups))
+	for _, g := range groups {
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", g.Name, g.ID))
+	}
+	return ambiguous("group DM", ref, candidates)
+}
+
+// userID returns the ID a user ID or <@id> mention refers to
+func userID(ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if id, ok := strings.CutPrefix(ref, "<@"); ok && strings.HasSuffix(id, ">") {
+		ref = strings.TrimPrefix(strings.TrimSuffix(id, ">"), "!")
+	}
+	return ref, isNumeric(ref)
+}
+
+func (r *resolver) listGuilds() ([]*discord.Guild, error) {
+	if r.guilds == nil {
+		guilds, err := r.client.ListGuilds()
+		if err != nil {
+			return nil, err
+		}
+		r.guilds = guilds
+	}
+	return r.guilds, nil
+}
+
+func (r *resolver) listChannels(guildID string) ([]*discord.Channel, error) {
+	if channels, ok := r.channels[guildID]; ok {
+		return channels, nil
+	}
+	channels, err := r.client.ListChannels(guildID)
+	if err != nil {
+		return nil, err
+	}
+	r.channels[guildID] = channels
+	return channels, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+func TestParseDiscordLink(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *discordLink
+	}{
+		{"https://discord.com/channels/1/2/3", &discordLink{"1", "2", "3"}},
+		{"https://canary.discord.com/channels/1/2", &discordLink{"1", "2", ""}},
+		{"https://discordapp.com/channels/@me/2/3/", &discordLink{"@me", "2", "3"}},
+		{"https://example.com/channels/1/2/3", nil},
+		{"https://discord.com/channels/1", nil},
+		{"300", nil},
+	}
+	for _, tt := range tests {
+		got, ok := parseDiscordLink(tt.in)
+		if tt.want == nil {
+			if ok {
+				t.Errorf("parseDiscordLink(%q) = %+v, want no match", tt.in, got)
+			}
+			continue
+		}
+		if !ok || *got != *tt.want {
+			t.Errorf("parseDiscordLink(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResolveChannelReferences(t *testing.T) {
+	env := newTestEnv(t)
+	link := "https://discord.com/channels/" + fake.GuildID + "/" + fake.ChannelGeneral
+
+	for _, ref := range []string{
+		fake.ChannelGeneral,
+		link,
+		"<#" + fake.ChannelGeneral + ">",
+		"Test Guild/#general",
+		"test guild/general",
+		fake.GuildID + "/#general",
+		"#general",
+	} {
+		resp := env.mustRun(t, "channels", "history", ref, "--limit", "1")
+		var data historyData
+		resp.decode(t, &data)
+		if len(data.Messages) != 1 || data.Messages[0].ChannelID != fake.ChannelGeneral {
+			t.Errorf("%s: expected a message from #general, got %+v", ref, data.Messages)
+		}
+	}
+
+	resp := env.mustRun(t, "channels", "history", "@alice", "--limit", "1")
+	var data historyData
+	resp.decode(t, &data)
+	if len(data.Messages) != 1 || data.Messages[0].ChannelID != fake.ChannelDMAlice {
+		t.Errorf("@alice: expected a message from the DM, got %+v", data.Messages)
+	}
+}
+
+func TestResolveAmbiguousChannel(t *testing.T) {
+	env := newTestEnv(t)
+	env.srv.AddGuild(&discordgo.Guild{ID: "210", Name: "Other Guild"})
+	env.srv.AddChannel(&discordgo.Channel{ID: "310", GuildID: "210", Name: "general", Type: discordgo.ChannelTypeGuildText})
+
+	resp, _ := env.run(t, "channels", "history", "#general")
+	if resp.OK {
+		t.Fatal("expected an ambiguous channel name to fail")
+	}
+	for _, want := range []string{"ambiguous", "Test Guild/#general (" + fake.ChannelGeneral + ")", "Other Guild/#general (310)"} {
+		if !strings.Contains(resp.Error, want) {
+			t.Errorf("expected error to mention %q, got %q", want, resp.Error)
+		}
+	}
+
+	// Qualifying with the server resolves it
+	env.mustRun(t, "channels", "history", "Other Guild/#general")
+
+	resp, _ = env.run(t, "channels", "history", "Test Guild/#nope")
+	if resp.OK || !strings.Contains(resp.Error, "not found") {
+		t.Errorf("expected not found error, got %+v", resp)
+	}
+}
+
+func TestResolveServerName(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "channels", "list", "Test Guild")
+	var data struct {
+		Count int `json:"count"`
+	}
+	resp.decode(t, &data)
+	if data.Count == 0 {
+		t.Error("expected channels for the named server")
+	}
+}
+
+func TestMessageReplyToLink(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+	link := "https://discord.com/channels/" + fake.GuildID + "/" + fake.ChannelGeneral + "/" + target.ID
+
+	resp := env.mustRun(t, "message", "reply", link, "via link")
+
+	var msg discord.Message
+	resp.decode(t, &msg)
+	if msg.ChannelID != fake.ChannelGeneral || msg.Reference == nil || msg.Reference.MessageID != target.ID {
+		t.Errorf("expected reply to %s in #general, got %+v", target.ID, msg)
+	}
+
+	resp = env.mustRun(t, "reaction", "add", link, "👍", "--dry-run")
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["channel_id"] != fake.ChannelGeneral || data["message_id"] != target.ID {
+		t.Errorf("unexpected dry-run output: %v", data)
+	}
+}
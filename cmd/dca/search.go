@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/ulfschnabel/dca/internal/config"
@@ -10,7 +11,7 @@ import (
 )
 
 var searchCmd = &cobra.Command{
-	Use:   "search <server-id> <query>",
+	Use:   "search <server> <query>",
 	Short: "Search messages in a server",
 	Long: `Search for messages in a Discord server by keyword.
 
@@ -19,7 +20,13 @@ Results are paginated in groups of 25. Use --offset to get more pages.
 Examples:
   dca search 123456789 "error log"
   dca search 123456789 "deployment" --channel-id 987654321
-  dca search 123456789 "bug" --author-id 111222333 --sort-by timestamp`,
+  dca search 123456789 "bug" --author-id 111222333 --sort-by timestamp
+  dca search 123456789 "outage" --since 2h
+  dca search "My Server" "deploy" --channel-id "#ops" --author-id @alice
+  dca search "My Server" "runbook" --pinned
+
+The server may be an ID, a name or a link; --channel-id also takes a
+channel name and --author-id a username.`,
 	Args: cobra.ExactArgs(2),
 	RunE: runSearch,
 }
@@ -27,18 +34,25 @@ Examples:
 func init() {
 	rootCmd.AddCommand(searchCmd)
 
-	searchCmd.Flags().String("channel-id", "", "Filter results to a specific channel")
-	searchCmd.Flags().String("author-id", "", "Filter results by author ID")
+	searchCmd.Flags().String("channel-id", "", "Filter results to a specific channel (ID or name)")
+	searchCmd.Flags().String("author-id", "", "Filter results by author (ID or username)")
 	searchCmd.Flags().String("has", "", "Filter by attachment type: link, embed, file, video, image, sound")
 	searchCmd.Flags().Int("offset", 0, "Pagination offset (multiples of 25)")
 	searchCmd.Flags().String("sort-by", "", "Sort by: relevance or timestamp")
 	searchCmd.Flags().String("sort-order", "", "Sort order: asc or desc")
+	searchCmd.Flags().Bool("pinned", false, "Only match pinned messages")
+	addTimeWindowFlags(searchCmd)
+	addRenderFlag(searchCmd)
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
-	serverID := args[0]
+	serverRef := args[0]
 	query := args[1]
+	since, until, err := timeWindow(cmd)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
 
 	cfg, err := config.Load(cfgFile)
 	if err != nil {
@@ -54,18 +68,42 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return output.PrintError(fmt.Errorf("no token configured"), pretty)
 	}
 
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
+	res := newResolver(client)
+	serverID, err := res.guild(serverRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Channel names are looked up in the searched server
 	channelID, _ := cmd.Flags().GetString("channel-id")
+	if channelID != "" {
+		ref := channelID
+		if !isNumeric(ref) && !strings.Contains(ref, "/") && !strings.HasPrefix(ref, "<#") {
+			ref = serverID + "/" + ref
+		}
+		if channelID, err = res.channel(ref); err != nil {
+			return output.PrintError(err, pretty)
+		}
+	}
 	authorID, _ := cmd.Flags().GetString("author-id")
+	if authorID != "" {
+		author, err := res.user(authorID)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		authorID = author.ID
+	}
 	has, _ := cmd.Flags().GetString("has")
 	offset, _ := cmd.Flags().GetInt("offset")
 	sortBy, _ := cmd.Flags().GetString("sort-by")
 	sortOrder, _ := cmd.Flags().GetString("sort-order")
+	pinned, _ := cmd.Flags().GetBool("pinned")
 
 	opts := discord.SearchOptions{
 		Content:   query,
@@ -75,6 +113,9 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		Offset:    offset,
 		SortBy:    sortBy,
 		SortOrder: sortOrder,
+		Pinned:    pinned,
+		Since:     since,
+		Until:     until,
 	}
 
 	result, err := client.SearchGuildMessages(serverID, opts)
@@ -82,6 +123,13 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return output.PrintError(err, pretty)
 	}
 
+	msgs := make([]*discord.Message, len(result.Messages))
+	for i, m := range result.Messages {
+		msgs[i] = &m.Message
+	}
+	renderMessages(cmd, client, msgs...)
+	compactMessages(cmd, msgs...)
+
 	return output.PrintSuccess(map[string]interface{}{
 		"messages":      result.Messages,
 		"count":         len(result.Messages),
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+type searchData struct {
+	Messages     []*discord.SearchMessage `json:"messages"`
+	Count        int                      `json:"count"`
+	TotalResults int                      `json:"total_results"`
+}
+
+func TestSearch(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "search", fake.GuildID, "deploy")
+
+	var data searchData
+	resp.decode(t, &data)
+	if data.TotalResults != 3 || data.Count != 3 {
+		t.Fatalf("expected 3 hits, got count=%d total=%d", data.Count, data.TotalResults)
+	}
+	for _, m := range data.Messages {
+		if m.ID == "" || m.Author.Username == "" {
+			t.Errorf("incomplete search hit: %+v", m)
+		}
+		if want := "https://discord.com/channels/" + fake.GuildID + "/" + m.ChannelID + "/" + m.ID; m.URL != want {
+			t.Errorf("expected URL %s, got %s", want, m.URL)
+		}
+		if m.Author.ID == fake.UserAlice && m.Author.DisplayName != "Alice (ops)" {
+			t.Errorf("expected alice's nickname on search hit, got %+v", m.Author)
+		}
+	}
+}
+
+func TestSearchFilters(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "search", fake.GuildID, "deploy", "--channel-id", fake.ChannelGeneral)
+	var data searchData
+	resp.decode(t, &data)
+	if data.Count != 1 || data.Messages[0].Content != "the deploy is done" {
+		t.Errorf("--channel-id: unexpected hits %+v", data.Messages)
+	}
+
+	resp = env.mustRun(t, "search", fake.GuildID, "deploy", "--author-id", fake.UserAlice)
+	data = searchData{}
+	resp.decode(t, &data)
+	if data.Count != 1 || data.Messages[0].Author.ID != fake.UserAlice {
+		t.Errorf("--author-id: unexpected hits %+v", data.Messages)
+	}
+}
+
+func TestSearchPinned(t *testing.T) {
+	env := newTestEnv(t)
+	var pinned string
+	for _, m := range env.srv.Messages(fake.ThreadActive) {
+		if m.Content == "Run make deploy-staging" {
+			pinned = m.ID
+		}
+	}
+	env.srv.Pin(fake.ThreadActive, pinned)
+
+	var data searchData
+	env.mustRun(t, "search", fake.GuildID, "deploy", "--pinned").decode(t, &data)
+	if data.Count != 1 || data.Messages[0].ID != pinned || !data.Messages[0].Pinned {
+		t.Errorf("expected only the pinned message, got %+v", data.Messages)
+	}
+}
+
+func TestSearchUnknownGuild(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp, _ := env.run(t, "search", "999", "anything")
+	if resp.OK {
+		t.Fatal("expected an unknown server to fail")
+	}
+}
+
+func TestSearchSinceUntil(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "search", fake.GuildID, "deploy",
+		"--since", fake.SeedTime.Add(2*time.Minute).Format(time.RFC3339),
+		"--until", fake.SeedTime.Add(4*time.Minute).Format(time.RFC3339))
+	var data searchData
+	resp.decode(t, &data)
+	if data.Count != 1 || data.Messages[0].Content != "How do I deploy to staging?" {
+		t.Errorf("expected only the hit inside the window, got %+v", data.Messages)
+	}
+}
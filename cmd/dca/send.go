@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/output"
+)
+
+// addContentFlags registers the message text flags shared by send commands
+func addContentFlags(cmd *cobra.Command) {
+	cmd.Flags().String("content-file", "", "Read the message text from a file; - reads stdin")
+	cmd.Flags().Bool("no-split", false, "Fail instead of splitting text over 2000 characters into several messages")
+}
+
+// stdinFlags lists the flags that are set to read from stdin
+func stdinFlags(cmd *cobra.Command) []string {
+	var flags []string
+	if path, _ := cmd.Flags().GetString("content-file"); path == "-" {
+		flags = append(flags, "--content-file")
+	}
+	if paths, _ := cmd.Flags().GetStringArray("file"); slices.Contains(paths, "-") {
+		flags = append(flags, "--file")
+	}
+	if path, _ := cmd.Flags().GetString("embed-json"); path == "-" {
+		flags = append(flags, "--embed-json")
+	}
+	return flags
+}
+
+// readContent returns the message text from the argument or --content-file
+func readContent(cmd *cobra.Command, content string) (string, error) {
+	path, _ := cmd.Flags().GetString("content-file")
+	if path == "" {
+		return content, nil
+	}
+	if content != "" {
+		return "", fmt.Errorf("give the message text as an argument or with --content-file, not both")
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read message content: %w", err)
+	}
+	return strings.TrimRight(string(data), " \t\r\n"), nil
+}
+
+// outgoingMessage assembles the message to send from the content argument,
+// the --content-file, --file and --embed-* flags, the mention policy and
+// the notification flags.
+// usesStdin reports whether any of them read stdin, which is then
+// unavailable for prompts.
+func outgoingMessage(cmd *cobra.Command, cfg *config.Config, content string) (out *discord.OutgoingMessage, usesStdin bool, err error) {
+	stdin := stdinFlags(cmd)
+	if len(stdin) > 1 {
+		return nil, false, fmt.Errorf("stdin can only be read once, but %s both read it", strings.Join(stdin, " and "))
+	}
+
+	mentions, err := mentionPolicy(cmd, cfg)
+	if err != nil {
+		return nil, false, err
+	}
+	content, err = readContent(cmd, content)
+	if err != nil {
+		return nil, false, err
+	}
+	files, err := readFiles(cmd)
+	if err != nil {
+		return nil, false, err
+	}
+	embeds, err := readEmbeds(cmd)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := discord.ValidateEmbeds(embeds); err != nil {
+		return nil, false, err
+	}
+	if content == "" && len(files) == 0 && len(embeds) == 0 {
+		if cmd.Flags().Lookup("embed-json") != nil {
+			return nil, false, fmt.Errorf("message content, an embed or --file is required")
+		}
+		return nil, false, fmt.Errorf("message content or --file is required")
+	}
+	out = &discord.OutgoingMessage{Content: content, Files: files, Embeds: embeds, Mentions: mentions}
+	applyNotificationFlags(cmd, cfg, out)
+	return out, len(stdin) > 0, nil
+}
+
+// splitMessage breaks out into the messages to send, unless --no-split
+// asks for long text to be rejected instead
+func splitMessage(cmd *cobra.Command, out *discord.OutgoingMessage) ([]*discord.OutgoingMessage, error) {
+	parts := out.Split()
+	if noSplit, _ := cmd.Flags().GetBool("no-split"); noSplit && len(parts) > 1 {
+		return nil, fmt.Errorf("message is %d characters, the limit is %d", utf8.RuneCountInString(out.Content), discord.MaxContentLength)
+	}
+	return parts, nil
+}
+
+// partContents lists the text of each part for dry-run output
+func partContents(parts []*discord.OutgoingMessage) []string {
+	contents := make([]string, 0, len(parts))
+	for _, p := range parts {
+		contents = append(contents, p.Content)
+	}
+	return contents
+}
+
+// printContent shows the text to send in an approval prompt
+func printContent(parts []*discord.OutgoingMessage) {
+	if len(parts) == 1 {
+		fmt.Printf("   \"%s\"\n\n", parts[0].Content)
+		return
+	}
+	fmt.Printf("   Split into %d messages:\n", len(parts))
+	for i, p := range parts {
+		fmt.Printf("   [%d/%d] \"%s\"\n", i+1, len(parts), p.Content)
+	}
+	fmt.Println()
+}
+
+// sendParts sends the parts of a message in order, after the ones already
+// sent. If one fails, the error names the parts that were sent.
+func sendParts(parts []*discord.OutgoingMessage, sent []*discord.Message, send func(i int, part *discord.OutgoingMessage) (*discord.Message, error)) ([]*discord.Message, error) {
+	for i := len(sent); i < len(parts); i++ {
+		msg, err := send(i, parts[i])
+		if err != nil {
+			if len(sent) == 0 {
+				return nil, err
+			}
+			return sent, fmt.Errorf("sent %d of %d parts (%s) before failing: %w", len(sent), len(parts), strings.Join(messageIDs(sent), ", "), err)
+		}
+		sent = append(sent, msg)
+	}
+	return sent, nil
+}
+
+// sentOutput is the result of a send: the message, or every part when the
+// text was split
+func sentOutput(cmd *cobra.Command, msgs []*discord.Message) interface{} {
+	compactMessages(cmd, msgs...)
+	if len(msgs) == 1 {
+		return msgs[0]
+	}
+	return map[string]interface{}{
+		"messages":    msgs,
+		"message_ids": messageIDs(msgs),
+		"count":       len(msgs),
+	}
+}
+
+func messageIDs(msgs []*discord.Message) []string {
+	ids := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+// sendFlow is the part shared by the commands that post a message: send,
+// reply, dm send, thread reply and forum post. newSendFlow reads the
+// message before the command connects, so missing files fail fast; the
+// command then fills in where the message goes and how to send it, and run
+// does the rest.
+type sendFlow struct {
+	cmd       *cobra.Command
+	cfg       *config.Config
+	out       *discord.OutgoingMessage
+	parts     []*discord.OutgoingMessage
+	usesStdin bool
+
+	action    string                 // action in the output, e.g. send_message
+	header    string                 // first line of the approval prompt
+	details   map[string]interface{} // where the message goes, for dry-run output
+	channelID string                 // channel mentions are resolved in; "" for DMs
+	replyTo   string                 // message replied to, whose author may be pinged
+	target    string                 // ledger target for --idempotency-key; "" for none
+	warnings  []string
+
+	// send posts one part of the message
+	send func(i int, part *discord.OutgoingMessage) (*discord.Message, error)
+	// result is the output of a completed send; sentOutput if nil
+	result func(msgs []*discord.Message) interface{}
+}
+
+// newSendFlow reads and splits the message a send command posts
+func newSendFlow(cmd *cobra.Command, cfg *config.Config, content string) (*sendFlow, error) {
+	out, usesStdin, err := outgoingMessage(cmd, cfg, content)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := splitMessage(cmd, out)
+	if err != nil {
+		return nil, err
+	}
+	return &sendFlow{cmd: cmd, cfg: cfg, out: out, parts: parts, usesStdin: usesStdin}, nil
+}
+
+// run applies the idempotency key, then shows the dry run, or asks for
+// approval and sends the message, printing the result
+func (f *sendFlow) run(client discord.API, pretty bool) error {
+	dryRun, _ := f.cmd.Flags().GetBool("dry-run")
+
+	var guard *sendGuard
+	if f.target != "" {
+		var err error
+		guard, err = newSendGuard(f.cmd, f.cfg, f.target, f.out)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		// A retry with the same idempotency key returns the original message
+		previous, err := guard.replay(client)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		if previous != nil {
+			return output.PrintSuccessWithWarnings(sentOutput(f.cmd, previous), guard.warnings, pretty)
+		}
+		guard.setNonces(f.parts)
+		f.warnings = append(guard.warnings, f.warnings...)
+	}
+
+	// Work out who will be notified, for the dry run and approval prompt
+	var mentions []*discord.Mention
+	if dryRun || f.cfg.RequireApproval {
+		if f.replyTo != "" {
+			reply, err := replyMention(client, f.channelID, f.replyTo, f.out.Mentions)
+			if err != nil {
+				return output.PrintError(err, pretty)
+			}
+			mentions = append(mentions, reply)
+		}
+		mentions = append(mentions, discord.NewRenderer(client).Mentions(f.channelID, f.out.Content, f.out.Mentions)...)
+	}
+
+	// Dry run - just show what would be sent
+	if dryRun {
+		result := map[string]interface{}{
+			"action":          f.action,
+			"content":         f.out.Content,
+			"files":           fileSummaries(f.out.Files),
+			"mentions":        mentions,
+			"silent":          f.out.Silent,
+			"suppress_embeds": f.out.SuppressEmbeds,
+			"dry_run":         true,
+		}
+		for k, v := range f.details {
+			result[k] = v
+		}
+		if f.cmd.Flags().Lookup("embed-json") != nil {
+			result["embeds"] = f.out.Embeds
+		}
+		if len(f.parts) > 1 {
+			result["parts"] = partContents(f.parts)
+		}
+		return output.PrintSuccessWithWarnings(result, f.warnings, pretty)
+	}
+
+	// Approval answers are read from stdin, so it cannot also carry input
+	if f.cfg.RequireApproval && f.usesStdin {
+		return output.PrintError(fmt.Errorf("cannot prompt for approval while input is read from stdin; use a file path instead"), pretty)
+	}
+
+	// Check approval requirement
+	if f.cfg.RequireApproval {
+		fmt.Println(f.header)
+		printContent(f.parts)
+		printEmbeds(f.out.Embeds)
+		printFiles(f.out.Files)
+		printMentions(mentions)
+		printNotificationFlags(f.cmd, f.out)
+		printWarnings(f.warnings)
+		fmt.Print("Proceed? [y/N]: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			return output.PrintSuccess(map[string]interface{}{
+				"action":    f.action,
+				"cancelled": true,
+			}, pretty)
+		}
+	}
+
+	// Send the message, in several parts if it is too long
+	var sent []*discord.Message
+	if guard != nil {
+		sent = guard.sent
+	}
+	msgs, err := sendParts(f.parts, sent, f.send)
+	if err != nil {
+		// Record the parts that went out, so a retry only sends the rest
+		if guard != nil && len(msgs) > 0 {
+			if rerr := guard.record(msgs, true); rerr != nil {
+				err = fmt.Errorf("%w; the sent parts were not recorded: %v", err, rerr)
+			}
+		}
+		return output.PrintError(err, pretty)
+	}
+	if guard != nil {
+		// The messages are already posted, so failing to record only warns
+		if err := guard.record(msgs, false); err != nil {
+			f.warnings = append(f.warnings, fmt.Sprintf("message sent but not recorded: %v", err))
+		}
+	}
+
+	if f.result != nil {
+		return output.PrintSuccessWithWarnings(f.result(msgs), f.warnings, pretty)
+	}
+	return output.PrintSuccessWithWarnings(sentOutput(f.cmd, msgs), f.warnings, pretty)
+}
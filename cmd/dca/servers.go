@@ -5,7 +5,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/ulfschnabel/dca/internal/config"
-	"github.com/ulfschnabel/dca/internal/discord"
 	"github.com/ulfschnabel/dca/internal/output"
 )
 
@@ -23,9 +22,9 @@ var serversListCmd = &cobra.Command{
 }
 
 var serversInfoCmd = &cobra.Command{
-	Use:   "info <server-id>",
+	Use:   "info <server>",
 	Short: "Get server information",
-	Long:  "Get detailed information about a specific server",
+	Long:  "Get detailed information about a specific server, given by ID, name or link",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runServersInfo,
 }
@@ -56,7 +55,7 @@ func runServersList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
@@ -76,7 +75,7 @@ func runServersList(cmd *cobra.Command, args []string) error {
 
 func runServersInfo(cmd *cobra.Command, args []string) error {
 	pretty, _ := cmd.Flags().GetBool("output-pretty")
-	serverID := args[0]
+	serverRef := args[0]
 
 	// Load config
 	cfg, err := config.Load(cfgFile)
@@ -95,12 +94,17 @@ func runServersInfo(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create Discord client
-	client, err := discord.New(token)
+	client, err := newClient(cmd, cfg, token)
 	if err != nil {
 		return output.PrintError(err, pretty)
 	}
 	defer client.Close()
 
+	serverID, err := newResolver(client).guild(serverRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
 	// Get guild info
 	guild, err := client.GetGuild(serverID)
 	if err != nil {
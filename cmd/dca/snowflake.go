@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/output"
+	"github.com/ulfschnabel/dca/internal/snowflake"
+)
+
+var snowflakeCmd = &cobra.Command{
+	Use:   "snowflake",
+	Short: "Snowflake ID utilities",
+	Long:  "Decode Discord IDs and convert times to IDs (no token needed)",
+}
+
+var snowflakeDecodeCmd = &cobra.Command{
+	Use:   "decode <id>...",
+	Short: "Decode snowflake IDs",
+	Long:  "Show when each ID was created, plus its worker, process and increment fields",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runSnowflakeDecode,
+}
+
+var snowflakeFromTimeCmd = &cobra.Command{
+	Use:   "from-time <time>",
+	Short: "Convert a time to snowflake IDs",
+	Long: `Convert a time to the range of snowflake IDs Discord assigns in that
+millisecond. The time is RFC3339, YYYY-MM-DD, or a duration ago like 2h.
+
+Use min_id as an --after/--before cursor to start paging at that time.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSnowflakeFromTime,
+}
+
+func init() {
+	rootCmd.AddCommand(snowflakeCmd)
+	snowflakeCmd.AddCommand(snowflakeDecodeCmd)
+	snowflakeCmd.AddCommand(snowflakeFromTimeCmd)
+}
+
+func runSnowflakeDecode(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+
+	decoded := make([]*snowflake.Decoded, 0, len(args))
+	for _, arg := range args {
+		d, err := snowflake.Decode(arg)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		decoded = append(decoded, d)
+	}
+
+	if len(decoded) == 1 {
+		return output.PrintSuccess(decoded[0], pretty)
+	}
+	return output.PrintSuccess(map[string]interface{}{
+		"snowflakes": decoded,
+		"count":      len(decoded),
+	}, pretty)
+}
+
+func runSnowflakeFromTime(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+
+	t, err := parseTimeFlag(args[0], time.Now())
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	if t.UnixMilli() < snowflake.Epoch {
+		return output.PrintError(fmt.Errorf("%s is before the Discord epoch (2015-01-01)", t.Format(time.RFC3339)), pretty)
+	}
+
+	return output.PrintSuccess(map[string]interface{}{
+		"time":   t.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		"min_id": snowflake.FromTime(t).String(),
+		"max_id": snowflake.MaxAt(t).String(),
+	}, pretty)
+}
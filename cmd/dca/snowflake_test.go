@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ulfschnabel/dca/internal/snowflake"
+)
+
+func TestSnowflakeDecode(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "snowflake", "decode", "175928847299117063")
+	var d snowflake.Decoded
+	resp.decode(t, &d)
+	if d.Timestamp != "2016-04-30T11:18:25.796Z" || d.WorkerID != 1 || d.Increment != 7 {
+		t.Errorf("unexpected decode: %+v", d)
+	}
+
+	resp = env.mustRun(t, "snowflake", "decode", "175928847299117063", "0")
+	var many struct {
+		Snowflakes []*snowflake.Decoded `json:"snowflakes"`
+		Count      int                  `json:"count"`
+	}
+	resp.decode(t, &many)
+	if many.Count != 2 || many.Snowflakes[1].Timestamp != "2015-01-01T00:00:00.000Z" {
+		t.Errorf("unexpected decode: %+v", many)
+	}
+
+	resp, _ = env.run(t, "snowflake", "decode", "not-an-id")
+	if resp.OK {
+		t.Error("expected an invalid ID to fail")
+	}
+}
+
+func TestSnowflakeFromTime(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "snowflake", "from-time", "2016-04-30T11:18:25.796Z")
+	var data map[string]string
+	resp.decode(t, &data)
+	if data["min_id"] != "175928847298985984" || data["max_id"] != "175928847303180287" {
+		t.Errorf("unexpected range: %v", data)
+	}
+
+	resp, _ = env.run(t, "snowflake", "from-time", "2014-12-31")
+	if resp.OK {
+		t.Error("expected a time before the Discord epoch to fail")
+	}
+}
@@ -0,0 +1,533 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/output"
+	"github.com/ulfschnabel/dca/internal/snowflake"
+)
+
+var threadCmd = &cobra.Command{
+	Use:   "thread",
+	Short: "Thread operations",
+	Long:  "List, create, reply in, join, leave, archive and lock threads",
+}
+
+var threadListCmd = &cobra.Command{
+	Use:   "list [channel]",
+	Short: "List active threads",
+	Long:  "List the active threads of a channel, or of a whole server with --server",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runThreadList,
+}
+
+var threadCreateCmd = &cobra.Command{
+	Use:   "create (<message-link> | <channel> [message-id]) <name>",
+	Short: "Create a thread",
+	Long: `Start a thread from a message, or a standalone thread in a channel when
+no message is given (requires approval unless --dry-run).`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runThreadCreate,
+}
+
+var threadReplyCmd = &cobra.Command{
+	Use:   "reply <thread> [message]",
+	Short: "Post in a thread",
+	Long: `Post a message in a thread (requires approval unless --dry-run).
+
+Takes the same --file, --embed-*, --content-file and mention flags as
+message send. Posting in an archived thread reopens it.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runThreadReply,
+}
+
+var threadJoinCmd = &cobra.Command{
+	Use:   "join <thread>",
+	Short: "Join a thread",
+	Long:  "Join a thread so it shows up in your thread list (requires approval unless --dry-run)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runThreadAction(threadJoin),
+}
+
+var threadLeaveCmd = &cobra.Command{
+	Use:   "leave <thread>",
+	Short: "Leave a thread",
+	Long:  "Leave a thread (requires approval unless --dry-run)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runThreadAction(threadLeave),
+}
+
+var threadArchiveCmd = &cobra.Command{
+	Use:   "archive <thread>",
+	Short: "Archive a thread",
+	Long:  "Archive a thread (requires approval unless --dry-run)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runThreadAction(threadArchive),
+}
+
+var threadUnarchiveCmd = &cobra.Command{
+	Use:   "unarchive <thread>",
+	Short: "Unarchive a thread",
+	Long:  "Reopen an archived thread (requires approval unless --dry-run)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runThreadAction(threadUnarchive),
+}
+
+var threadLockCmd = &cobra.Command{
+	Use:   "lock <thread>",
+	Short: "Lock a thread",
+	Long:  "Lock a thread so only moderators can reopen it (requires approval unless --dry-run)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runThreadAction(threadLock),
+}
+
+var threadUnlockCmd = &cobra.Command{
+	Use:   "unlock <thread>",
+	Short: "Unlock a thread",
+	Long:  "Unlock a locked thread (requires approval unless --dry-run)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runThreadAction(threadUnlock),
+}
+
+func init() {
+	rootCmd.AddCommand(threadCmd)
+	threadCmd.AddCommand(threadListCmd)
+	threadCmd.AddCommand(threadCreateCmd)
+	threadCmd.AddCommand(threadReplyCmd)
+
+	threadListCmd.Flags().String("server", "", "List the active threads of every channel in this server")
+	threadListCmd.Flags().Int("limit", 25, "Number of threads to show")
+	threadListCmd.Flags().Bool("joined", false, "Only show threads you have joined")
+
+	threadCreateCmd.Flags().Bool("dry-run", false, "Show what would be created without actually creating")
+	threadCreateCmd.Flags().String("auto-archive", "", "Archive after this long without activity: 1h, 1d, 3d or 1w (default from the channel)")
+	threadCreateCmd.Flags().Bool("private", false, "Create a private thread; only for threads not started from a message")
+
+	threadReplyCmd.Flags().Bool("dry-run", false, "Show what would be sent without actually sending")
+	addFileFlags(threadReplyCmd)
+	addEmbedFlags(threadReplyCmd)
+	addContentFlags(threadReplyCmd)
+	addIdempotencyFlag(threadReplyCmd)
+	addMentionFlags(threadReplyCmd, false)
+	addNotificationFlags(threadReplyCmd)
+
+	for _, c := range []*cobra.Command{threadJoinCmd, threadLeaveCmd, threadArchiveCmd, threadUnarchiveCmd, threadLockCmd, threadUnlockCmd} {
+		threadCmd.AddCommand(c)
+		c.Flags().Bool("dry-run", false, "Show what would be changed without actually changing")
+	}
+}
+
+// autoArchiveDurations are the inactivity periods Discord accepts, in
+// minutes
+var autoArchiveDurations = map[string]int{
+	"1h": 60, "60": 60,
+	"1d": 1440, "24h": 1440, "1440": 1440,
+	"3d": 4320, "72h": 4320, "4320": 4320,
+	"1w": 10080, "7d": 10080, "10080": 10080,
+}
+
+// parseAutoArchive converts --auto-archive to minutes, 0 when unset
+func parseAutoArchive(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	minutes, ok := autoArchiveDurations[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("invalid --auto-archive %q, expected 1h, 1d, 3d or 1w", s)
+	}
+	return minutes, nil
+}
+
+func runThreadList(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	serverRef, _ := cmd.Flags().GetString("server")
+	limit, _ := cmd.Flags().GetInt("limit")
+	joinedOnly, _ := cmd.Flags().GetBool("joined")
+
+	if (len(args) == 0) == (serverRef == "") {
+		return output.PrintError(fmt.Errorf("give either a channel or --server"), pretty)
+	}
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	// Threads are listed per guild, so find the channel's guild first
+	r := newResolver(client)
+	var guildID, channelID string
+	if serverRef != "" {
+		guildID, err = r.guild(serverRef)
+	} else {
+		channelID, err = r.channel(args[0])
+		if err == nil {
+			var ch *discord.Channel
+			if ch, err = client.GetChannel(channelID); err == nil {
+				guildID = ch.GuildID
+			}
+		}
+	}
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	if guildID == "" {
+		return output.PrintError(fmt.Errorf("channel %s is not in a server", channelID), pretty)
+	}
+
+	threads, err := client.ListActiveThreads(guildID, channelID)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Most recently active first
+	sort.SliceStable(threads, func(i, j int) bool {
+		return snowflake.Less(threads[j].LastActivityID(), threads[i].LastActivityID())
+	})
+	result := make([]*discord.Thread, 0, len(threads))
+	for _, t := range threads {
+		if joinedOnly && !t.Joined {
+			continue
+		}
+		result = append(result, t)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+
+	return output.PrintSuccess(map[string]interface{}{
+		"threads": result,
+		"count":   len(result),
+	}, pretty)
+}
+
+func runThreadCreate(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	private, _ := cmd.Flags().GetBool("private")
+	autoArchiveFlag, _ := cmd.Flags().GetString("auto-archive")
+
+	// <message-link> <name>, <channel> <message-id> <name> or <channel> <name>
+	var channelRef, messageRef string
+	name := args[len(args)-1]
+	switch {
+	case len(args) == 3:
+		channelRef, messageRef = args[0], args[1]
+	case isMessageLink(args[0]):
+		channelRef, messageRef = args[0], args[0]
+	default:
+		channelRef = args[0]
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return output.PrintError(fmt.Errorf("thread name is required"), pretty)
+	}
+	if n := len([]rune(name)); n > 100 {
+		return output.PrintError(fmt.Errorf("thread name is %d characters, the limit is 100", n), pretty)
+	}
+	if private && messageRef != "" {
+		return output.PrintError(fmt.Errorf("--private only applies to threads not started from a message"), pretty)
+	}
+	autoArchive, err := parseAutoArchive(autoArchiveFlag)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	var channelID, messageID string
+	if messageRef != "" {
+		channelID, messageID, err = newResolver(client).message(channelRef, messageRef)
+	} else {
+		channelID, err = newResolver(client).channel(channelRef)
+	}
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Dry run
+	if dryRun {
+		return output.PrintSuccess(map[string]interface{}{
+			"action":                "create_thread",
+			"channel_id":            channelID,
+			"message_id":            messageID,
+			"name":                  name,
+			"private":               private,
+			"auto_archive_duration": autoArchive,
+			"dry_run":               true,
+		}, pretty)
+	}
+
+	// Check approval requirement
+	if cfg.RequireApproval {
+		if messageID != "" {
+			fmt.Printf("🧵 Create thread %q from message %s in channel %s\n\n", name, messageID, channelID)
+		} else if private {
+			fmt.Printf("🧵 Create private thread %q in channel %s\n\n", name, channelID)
+		} else {
+			fmt.Printf("🧵 Create thread %q in channel %s\n\n", name, channelID)
+		}
+		fmt.Print("Proceed? [y/N]: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			return output.PrintSuccess(map[string]interface{}{
+				"action":    "create_thread",
+				"cancelled": true,
+			}, pretty)
+		}
+	}
+
+	// Create thread
+	thread, err := client.StartThread(channelID, messageID, discord.ThreadOptions{
+		Name:                name,
+		AutoArchiveDuration: autoArchive,
+		Private:             private,
+	})
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	return output.PrintSuccess(thread, pretty)
+}
+
+func runThreadReply(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	threadRef := args[0]
+	content := ""
+	if len(args) > 1 {
+		content = args[1]
+	}
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Read the message before connecting so missing files fail fast
+	flow, err := newSendFlow(cmd, cfg, content)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	threadID, err := newResolver(client).channel(threadRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	thread, err := client.GetThread(threadID)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	if thread.Archived && thread.Locked {
+		return output.PrintError(fmt.Errorf("thread %q is archived and locked", thread.Name), pretty)
+	}
+	if thread.Archived {
+		flow.warnings = append(flow.warnings, fmt.Sprintf("thread %q is archived; posting will reopen it", thread.Name))
+	}
+
+	flow.action = "reply_to_thread"
+	flow.header = fmt.Sprintf("🧵 Post in thread %q (%s):", thread.Name, threadID)
+	flow.details = map[string]interface{}{"thread_id": threadID, "thread_name": thread.Name}
+	flow.channelID = threadID
+	flow.target = threadID
+	flow.send = func(_ int, part *discord.OutgoingMessage) (*discord.Message, error) {
+		return client.SendMessage(threadID, part)
+	}
+	return flow.run(client, pretty)
+}
+
+// threadAction is a change to a thread's membership or state
+type threadAction struct {
+	name   string // action in the output, e.g. archive_thread
+	prompt string // approval prompt, formatted with the thread name and ID
+	apply  func(client discord.API, thread *discord.Thread) (*discord.Thread, error)
+}
+
+func threadState(archived, locked *bool) func(discord.API, *discord.Thread) (*discord.Thread, error) {
+	return func(client discord.API, thread *discord.Thread) (*discord.Thread, error) {
+		return client.UpdateThread(thread.ID, discord.ThreadUpdate{Archived: archived, Locked: locked})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+var (
+	threadJoin = threadAction{
+		name:   "join_thread",
+		prompt: "➕ Join thread %q (%s)",
+		apply: func(client discord.API, thread *discord.Thread) (*discord.Thread, error) {
+			if err := client.JoinThread(thread.ID); err != nil {
+				return nil, err
+			}
+			thread.Joined = true
+			return thread, nil
+		},
+	}
+	threadLeave = threadAction{
+		name:   "leave_thread",
+		prompt: "➖ Leave thread %q (%s)",
+		apply: func(client discord.API, thread *discord.Thread) (*discord.Thread, error) {
+			if err := client.LeaveThread(thread.ID); err != nil {
+				return nil, err
+			}
+			thread.Joined = false
+			return thread, nil
+		},
+	}
+	threadArchive   = threadAction{"archive_thread", "📦 Archive thread %q (%s)", threadState(boolPtr(true), nil)}
+	threadUnarchive = threadAction{"unarchive_thread", "📂 Unarchive thread %q (%s)", threadState(boolPtr(false), nil)}
+	threadLock      = threadAction{"lock_thread", "🔒 Lock thread %q (%s)", threadState(nil, boolPtr(true))}
+	threadUnlock    = threadAction{"unlock_thread", "🔓 Unlock thread %q (%s)", threadState(nil, boolPtr(false))}
+)
+
+// runThreadAction returns the RunE of a thread membership or state command
+func runThreadAction(action threadAction) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		pretty, _ := cmd.Flags().GetBool("output-pretty")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		threadRef := args[0]
+
+		// Load config
+		cfg, err := config.Load(cfgFile)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		// Get token
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = cfg.UserToken
+		}
+
+		if token == "" {
+			return output.PrintError(fmt.Errorf("no token configured"), pretty)
+		}
+
+		// Create Discord client
+		client, err := newClient(cmd, cfg, token)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		defer client.Close()
+
+		threadID, err := newResolver(client).channel(threadRef)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+		thread, err := client.GetThread(threadID)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		// Dry run
+		if dryRun {
+			return output.PrintSuccess(map[string]interface{}{
+				"action":      action.name,
+				"thread_id":   thread.ID,
+				"thread_name": thread.Name,
+				"dry_run":     true,
+			}, pretty)
+		}
+
+		// Check approval requirement
+		if cfg.RequireApproval {
+			fmt.Printf(action.prompt+"\n\n", thread.Name, thread.ID)
+			fmt.Print("Proceed? [y/N]: ")
+
+			reader := bufio.NewReader(os.Stdin)
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				return output.PrintError(fmt.Errorf("failed to read response: %w", err), pretty)
+			}
+
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				return output.PrintSuccess(map[string]interface{}{
+					"action":    action.name,
+					"cancelled": true,
+				}, pretty)
+			}
+		}
+
+		thread, err = action.apply(client, thread)
+		if err != nil {
+			return output.PrintError(err, pretty)
+		}
+
+		return output.PrintSuccess(map[string]interface{}{
+			"action": action.name,
+			"thread": thread,
+		}, pretty)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+)
+
+type threadListData struct {
+	Threads []*discord.Thread `json:"threads"`
+	Count   int               `json:"count"`
+}
+
+type threadActionData struct {
+	Action string          `json:"action"`
+	Thread *discord.Thread `json:"thread"`
+}
+
+func TestThreadList(t *testing.T) {
+	env := newTestEnv(t)
+
+	var data threadListData
+	env.mustRun(t, "thread", "list", fake.ChannelForum).decode(t, &data)
+	if data.Count != 1 || data.Threads[0].ID != fake.ThreadActive || data.Threads[0].Archived {
+		t.Fatalf("expected only the active thread, got %+v", data.Threads)
+	}
+
+	data = threadListData{}
+	env.mustRun(t, "thread", "list", "--server", fake.GuildID).decode(t, &data)
+	if data.Count != 1 {
+		t.Errorf("expected 1 active thread in the server, got %+v", data.Threads)
+	}
+
+	resp, _ := env.run(t, "thread", "list")
+	if resp.OK || !strings.Contains(resp.Error, "--server") {
+		t.Errorf("expected a missing channel error, got %+v", resp)
+	}
+}
+
+func TestThreadListFallsBackToSearch(t *testing.T) {
+	env := newTestEnv(t)
+	env.srv.BotOnlyActiveThreads()
+	env.mustRun(t, "thread", "join", fake.ThreadActive)
+
+	// Channels the user cannot view are skipped
+	env.srv.AddChannel(&discordgo.Channel{ID: "306", GuildID: fake.GuildID, Type: discordgo.ChannelTypeGuildText, Name: "staff"})
+	env.srv.HideChannel("306")
+
+	var data threadListData
+	env.mustRun(t, "thread", "list", "--server", fake.GuildID, "--joined").decode(t, &data)
+	if data.Count != 1 || data.Threads[0].ID != fake.ThreadActive || !data.Threads[0].Joined {
+		t.Fatalf("expected the joined active thread from thread search, got %+v", data.Threads)
+	}
+
+	resp, _ := env.run(t, "thread", "list", "306")
+	if resp.OK || !strings.Contains(resp.Error, "Missing Access") {
+		t.Errorf("expected a hidden channel to fail on its own, got %+v", resp)
+	}
+}
+
+func TestThreadCreateFromMessage(t *testing.T) {
+	env := newTestEnv(t)
+	target := env.srv.Messages(fake.ChannelGeneral)[0]
+
+	var thread discord.Thread
+	env.mustRun(t, "thread", "create", fake.ChannelGeneral, target.ID, "Morning chat", "--auto-archive", "1w").decode(t, &thread)
+	if thread.ID != target.ID || thread.ParentID != fake.ChannelGeneral || thread.Name != "Morning chat" {
+		t.Errorf("expected a thread on message %s, got %+v", target.ID, thread)
+	}
+	if thread.AutoArchiveDuration != 10080 {
+		t.Errorf("expected a one week auto-archive, got %d", thread.AutoArchiveDuration)
+	}
+	if !env.srv.Joined(thread.ID) {
+		t.Error("expected the creator to join the thread")
+	}
+
+	resp, _ := env.run(t, "thread", "create", fake.ChannelGeneral, target.ID, "Again")
+	if resp.OK {
+		t.Error("expected a second thread on the same message to fail")
+	}
+}
+
+func TestThreadCreateStandalone(t *testing.T) {
+	env := newTestEnv(t)
+
+	var thread discord.Thread
+	env.mustRun(t, "thread", "create", fake.ChannelGeneral, "Planning", "--private").decode(t, &thread)
+	if thread.Type != "private_thread" || thread.ParentID != fake.ChannelGeneral {
+		t.Errorf("expected a private thread in #general, got %+v", thread)
+	}
+
+	resp := env.mustRun(t, "thread", "create", fake.ChannelGeneral, "Planning", "--dry-run")
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["dry_run"] != true || data["name"] != "Planning" {
+		t.Errorf("unexpected dry-run output: %v", data)
+	}
+
+	resp, _ = env.run(t, "thread", "create", fake.ChannelGeneral, "Planning", "--auto-archive", "2d")
+	if resp.OK || !strings.Contains(resp.Error, "--auto-archive") {
+		t.Errorf("expected an invalid --auto-archive error, got %+v", resp)
+	}
+}
+
+func TestThreadReply(t *testing.T) {
+	env := newTestEnv(t)
+
+	var msg discord.Message
+	env.mustRun(t, "thread", "reply", fake.ThreadActive, "deployed, thanks").decode(t, &msg)
+	if msg.ChannelID != fake.ThreadActive || env.srv.LastMessage(fake.ThreadActive).Content != "deployed, thanks" {
+		t.Errorf("expected the reply in the thread, got %+v", msg)
+	}
+
+	// Posting reopens an archived thread, with a warning
+	resp := env.mustRun(t, "thread", "reply", fake.ThreadArchived, "still broken?")
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "archived") {
+		t.Errorf("expected an archived warning, got %v", resp.Warnings)
+	}
+	if env.srv.Channel(fake.ThreadArchived).ThreadMetadata.Archived {
+		t.Error("expected the thread to be reopened")
+	}
+
+	resp, _ = env.run(t, "thread", "reply", fake.ChannelGeneral, "hi")
+	if resp.OK || !strings.Contains(resp.Error, "not a thread") {
+		t.Errorf("expected a not a thread error, got %+v", resp)
+	}
+}
+
+func TestThreadJoinLeave(t *testing.T) {
+	env := newTestEnv(t)
+
+	var data threadActionData
+	env.mustRun(t, "thread", "join", fake.ThreadActive).decode(t, &data)
+	if data.Action != "join_thread" || !data.Thread.Joined || !env.srv.Joined(fake.ThreadActive) {
+		t.Errorf("expected to join the thread, got %+v", data)
+	}
+
+	data = threadActionData{}
+	env.mustRun(t, "thread", "leave", fake.ThreadActive).decode(t, &data)
+	if data.Thread.Joined || env.srv.Joined(fake.ThreadActive) {
+		t.Errorf("expected to leave the thread, got %+v", data)
+	}
+}
+
+func TestThreadArchiveAndLock(t *testing.T) {
+	env := newTestEnv(t)
+
+	var data threadActionData
+	env.mustRun(t, "thread", "lock", fake.ThreadActive).decode(t, &data)
+	env.mustRun(t, "thread", "archive", fake.ThreadActive).decode(t, &data)
+	if !data.Thread.Archived || !data.Thread.Locked {
+		t.Fatalf("expected an archived, locked thread, got %+v", data.Thread)
+	}
+
+	resp, _ := env.run(t, "thread", "reply", fake.ThreadActive, "hello?")
+	if resp.OK || !strings.Contains(resp.Error, "locked") {
+		t.Errorf("expected replies to a locked archived thread to fail, got %+v", resp)
+	}
+
+	env.mustRun(t, "thread", "unlock", fake.ThreadActive)
+	data = threadActionData{}
+	env.mustRun(t, "thread", "unarchive", fake.ThreadActive).decode(t, &data)
+	if data.Thread.Archived || data.Thread.Locked {
+		t.Errorf("expected an open thread, got %+v", data.Thread)
+	}
+}
+
+func TestThreadActionDryRunAndApproval(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.mustRun(t, "thread", "archive", fake.ThreadActive, "--dry-run")
+	var data map[string]interface{}
+	resp.decode(t, &data)
+	if data["dry_run"] != true || data["thread_name"] != "How do I deploy?" {
+		t.Errorf("unexpected dry-run output: %v", data)
+	}
+
+	env.writeConfig(t, &config.Config{UserToken: fake.Token, RequireApproval: true})
+	env.stdin = "n\n"
+	resp, stdout := env.run(t, "thread", "archive", fake.ThreadActive)
+	if !strings.Contains(stdout, `Archive thread "How do I deploy?"`) {
+		t.Errorf("expected an approval prompt, got %q", stdout)
+	}
+	data = nil
+	resp.decode(t, &data)
+	if data["cancelled"] != true || env.srv.Channel(fake.ThreadActive).ThreadMetadata.Archived {
+		t.Errorf("expected the archive to be cancelled, got %v", data)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/ulfschnabel/dca/internal/config"
+	"github.com/ulfschnabel/dca/internal/output"
+)
+
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "User operations",
+	Long:  "Look up users and what you share with them",
+}
+
+var usersLookupCmd = &cobra.Command{
+	Use:   "lookup <name>",
+	Short: "Find users by name",
+	Long: `Find the users a name may refer to among your friends, DM recipients
+and the members of your servers.
+
+Every candidate is listed with how the name matched, a confidence score
+between 0 and 1 and the servers you share with them. An ID is looked up
+directly.
+
+Discord offers no way to look up a stranger by username, so users you share
+no friendship, DM or server with are only found by their ID.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUsersLookup,
+}
+
+var usersInfoCmd = &cobra.Command{
+	Use:   "info <user>",
+	Short: "Show user details",
+	Long: `Show a user with your relationship, any existing DM and the servers
+you share.
+
+The user may be an ID, a mention or a username.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUsersInfo,
+}
+
+func init() {
+	rootCmd.AddCommand(usersCmd)
+	usersCmd.AddCommand(usersLookupCmd)
+	usersCmd.AddCommand(usersInfoCmd)
+}
+
+func runUsersLookup(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	query := args[0]
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	// Look up users
+	candidates, err := client.LookupUsers(query)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	return output.PrintSuccess(map[string]interface{}{
+		"query":      query,
+		"candidates": candidates,
+		"count":      len(candidates),
+	}, pretty)
+}
+
+func runUsersInfo(cmd *cobra.Command, args []string) error {
+	pretty, _ := cmd.Flags().GetBool("output-pretty")
+	userRef := args[0]
+
+	// Load config
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get token
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.UserToken
+	}
+
+	if token == "" {
+		return output.PrintError(fmt.Errorf("no token configured"), pretty)
+	}
+
+	// Create Discord client
+	client, err := newClient(cmd, cfg, token)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+	defer client.Close()
+
+	user, err := newResolver(client).user(userRef)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	// Get user details
+	info, err := client.GetUserInfo(user.ID)
+	if err != nil {
+		return output.PrintError(err, pretty)
+	}
+
+	return output.PrintSuccess(info, pretty)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/discord"
+	"github.com/ulfschnabel/dca/internal/discord/fake"
+	"github.com/ulfschnabel/dca/internal/snowflake"
+)
+
+type usersLookupData struct {
+	Candidates []*discord.UserCandidate `json:"candidates"`
+	Count      int                      `json:"count"`
+}
+
+func TestUsersLookup(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp, stdout := env.run(t, "users", "lookup", "alice")
+	if !resp.OK || strings.Contains(stdout, "Searching") {
+		t.Fatalf("expected clean JSON output, got %q", stdout)
+	}
+	var data usersLookupData
+	resp.decode(t, &data)
+	if data.Count != 1 {
+		t.Fatalf("expected one candidate, got %+v", data.Candidates)
+	}
+	alice := data.Candidates[0]
+	if alice.ID != fake.UserAlice || alice.Match != discord.MatchUsername || alice.Confidence != 1 || alice.Relationship != "friend" {
+		t.Errorf("unexpected candidate: %+v", alice)
+	}
+	if strings.Join(alice.Sources, ",") != "friend,dm,guild_member" {
+		t.Errorf("expected alice from every source, got %v", alice.Sources)
+	}
+	if len(alice.MutualGuilds) != 1 || alice.MutualGuilds[0].Name != "Test Guild" || alice.MutualGuilds[0].Nick != "Alice (ops)" {
+		t.Errorf("expected the shared server with her nickname, got %+v", alice.MutualGuilds)
+	}
+
+	// Display names and nicknames match too, below exact usernames
+	data = usersLookupData{}
+	env.mustRun(t, "users", "lookup", "bobby").decode(t, &data)
+	if data.Count != 1 || data.Candidates[0].Match != discord.MatchGlobalName || data.Candidates[0].Confidence != 0.9 {
+		t.Errorf("expected bob by display name, got %+v", data.Candidates)
+	}
+
+	data = usersLookupData{}
+	env.mustRun(t, "users", "lookup", "nobody").decode(t, &data)
+	if data.Count != 0 || data.Candidates == nil {
+		t.Errorf("expected no candidates, got %+v", data)
+	}
+}
+
+func TestUsersLookupRanking(t *testing.T) {
+	env := newTestEnv(t)
+	env.srv.AddUser(&discordgo.User{ID: "103", Username: "alicia"})
+	env.srv.AddMember(fake.GuildID, &discordgo.Member{User: &discordgo.User{ID: "103", Username: "alicia"}})
+
+	var data usersLookupData
+	env.mustRun(t, "users", "lookup", "ali").decode(t, &data)
+	if data.Count != 2 {
+		t.Fatalf("expected two prefix matches, got %+v", data.Candidates)
+	}
+	// Alice is a friend with a DM, so she ranks first
+	if data.Candidates[0].ID != fake.UserAlice || data.Candidates[1].ID != "103" || data.Candidates[0].Confidence <= data.Candidates[1].Confidence {
+		t.Errorf("expected alice ahead of alicia, got %+v %+v", data.Candidates[0], data.Candidates[1])
+	}
+
+	// An exact username still resolves despite the similar name
+	env.mustRun(t, "dm", "history", "alice", "--limit", "1")
+
+	resp, _ := env.run(t, "dm", "history", "ali")
+	if resp.OK || !strings.Contains(resp.Error, "similar: @alice, @alicia") || !strings.Contains(resp.Error, "pass a user ID") {
+		t.Errorf("expected a not found error with suggestions, got %+v", resp)
+	}
+
+	// Strangers can only be reached by ID
+	resp, _ = env.run(t, "dm", "history", "nobody")
+	if resp.OK || !strings.Contains(resp.Error, "pass their user ID instead") {
+		t.Errorf("expected the error to suggest a user ID, got %+v", resp)
+	}
+}
+
+func TestUsersLookupRelationships(t *testing.T) {
+	env := newTestEnv(t)
+	env.srv.AddRelationship(fake.UserBob, fake.RelationshipBlocked)
+	addStranger(env, "103", "carol")
+	env.srv.AddRelationship("103", fake.RelationshipBlocked)
+	addStranger(env, "104", "dave")
+	env.srv.AddRelationship("104", fake.RelationshipPendingIncoming)
+
+	// Blocked users are not friends, and only found where we meet them
+	var data usersLookupData
+	env.mustRun(t, "users", "lookup", "bob").decode(t, &data)
+	if data.Count != 1 || strings.Join(data.Candidates[0].Sources, ",") != "guild_member" || data.Candidates[0].Relationship != discord.RelationshipBlocked {
+		t.Errorf("expected bob as a blocked server member, got %+v", data.Candidates)
+	}
+	resp, _ := env.run(t, "dm", "send", "carol", "hi")
+	if resp.OK {
+		t.Error("expected a blocked user to be found by ID only")
+	}
+
+	data = usersLookupData{}
+	env.mustRun(t, "users", "lookup", "dave").decode(t, &data)
+	if data.Count != 1 || strings.Join(data.Candidates[0].Sources, ",") != "friend_request" {
+		t.Errorf("expected dave from his friend request, got %+v", data.Candidates)
+	}
+
+	// A friend found by the nickname we gave them still lists shared servers
+	env.srv.AddRelationship("103", fake.RelationshipFriend)
+	env.srv.SetFriendNickname("103", "Caz")
+	env.srv.AddMember(fake.GuildID, &discordgo.Member{User: &discordgo.User{ID: "103", Username: "carol"}, Nick: "Carol C"})
+	data = usersLookupData{}
+	env.mustRun(t, "users", "lookup", "caz").decode(t, &data)
+	if data.Count != 1 || data.Candidates[0].Match != discord.MatchNick {
+		t.Fatalf("expected carol by friend nickname, got %+v", data.Candidates)
+	}
+	if mg := data.Candidates[0].MutualGuilds; len(mg) != 1 || mg[0].ID != fake.GuildID || mg[0].Nick != "Carol C" {
+		t.Errorf("expected the shared server, got %+v", mg)
+	}
+}
+
+func TestUsersLookupAmbiguous(t *testing.T) {
+	env := newTestEnv(t)
+	env.srv.AddUser(&discordgo.User{ID: "103", Username: "bobcat", GlobalName: "Bobby"})
+	env.srv.AddMember(fake.GuildID, &discordgo.Member{User: &discordgo.User{ID: "103", Username: "bobcat", GlobalName: "Bobby"}})
+
+	resp, _ := env.run(t, "users", "info", "Bobby")
+	if resp.OK || !strings.Contains(resp.Error, "ambiguous") || !strings.Contains(resp.Error, "@bobcat (103)") {
+		t.Errorf("expected an ambiguous user error, got %+v", resp)
+	}
+}
+
+func TestUsersInfo(t *testing.T) {
+	env := newTestEnv(t)
+
+	var info discord.UserInfo
+	env.mustRun(t, "users", "info", "@alice").decode(t, &info)
+	if info.ID != fake.UserAlice || info.Relationship != "friend" || info.DMChannelID != fake.ChannelDMAlice {
+		t.Errorf("unexpected user info: %+v", info)
+	}
+	if info.CreatedAt != snowflake.Timestamp(fake.UserAlice) {
+		t.Errorf("expected created_at from the user ID, got %q", info.CreatedAt)
+	}
+	if len(info.MutualGuilds) != 1 || info.MutualGuilds[0].ID != fake.GuildID || info.MutualGuilds[0].Nick != "Alice (ops)" {
+		t.Errorf("expected the shared server, got %+v", info.MutualGuilds)
+	}
+
+	info = discord.UserInfo{}
+	env.mustRun(t, "users", "info", fake.UserBob).decode(t, &info)
+	if info.Username != "bob" || info.Relationship != "" || info.DMChannelID != "" || len(info.MutualGuilds) != 1 {
+		t.Errorf("unexpected user info for bob: %+v", info)
+	}
+
+	resp, _ := env.run(t, "users", "info", "999")
+	if resp.OK {
+		t.Error("expected an unknown user ID to fail")
+	}
+}
@@ -5,13 +5,43 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	UserToken       string `json:"user_token"`
 	RequireApproval bool   `json:"require_approval"`
+
+	// Network settings, all optional
+	APIBaseURL     string `json:"api_base_url,omitempty"`
+	HTTPProxy      string `json:"http_proxy,omitempty"`
+	CABundle       string `json:"ca_bundle,omitempty"`
+	RequestTimeout string `json:"request_timeout,omitempty"`
+
+	// DuplicateWindow is how far back sends are checked for identical
+	// content, as a duration; "0" turns the check off
+	DuplicateWindow string `json:"duplicate_window,omitempty"`
+
+	// AllowedMentions lists who sent messages may notify: users, roles,
+	// everyone, comma-separated, or none
+	AllowedMentions string `json:"allowed_mentions,omitempty"`
+
+	// QuietHours is a daily range such as "22:00-07:00" during which
+	// messages are sent silently unless --silent=false is given. It may
+	// wrap past midnight and is read in QuietHoursTimezone, an IANA zone
+	// name, or local time if that is unset.
+	QuietHours         string `json:"quiet_hours,omitempty"`
+	QuietHoursTimezone string `json:"quiet_hours_timezone,omitempty"`
 }
 
+// DefaultAllowedMentions applies when allowed_mentions is unset: users may
+// be pinged, roles, @everyone and @here may not
+const DefaultAllowedMentions = "users"
+
+// DefaultDuplicateWindow applies when duplicate_window is unset
+const DefaultDuplicateWindow = 10 * time.Minute
+
 // DefaultConfigPath returns the default config file path
 func DefaultConfigPath() string {
 	home, err := os.UserHomeDir()
@@ -40,9 +70,94 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	if cfg.RequestTimeout != "" {
+		if _, err := time.ParseDuration(cfg.RequestTimeout); err != nil {
+			return nil, fmt.Errorf("invalid request_timeout %q: %w", cfg.RequestTimeout, err)
+		}
+	}
+
+	if cfg.DuplicateWindow != "" {
+		if _, err := time.ParseDuration(cfg.DuplicateWindow); err != nil {
+			return nil, fmt.Errorf("invalid duplicate_window %q: %w", cfg.DuplicateWindow, err)
+		}
+	}
+
+	if cfg.QuietHours != "" {
+		if _, _, err := parseQuietHours(cfg.QuietHours); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.QuietHoursTimezone != "" {
+		if _, err := time.LoadLocation(cfg.QuietHoursTimezone); err != nil {
+			return nil, fmt.Errorf("invalid quiet_hours_timezone %q: %w", cfg.QuietHoursTimezone, err)
+		}
+	}
+
 	return &cfg, nil
 }
 
+// parseQuietHours returns the start and end of a HH:MM-HH:MM range in
+// minutes after midnight
+func parseQuietHours(s string) (start, end int, err error) {
+	from, to, ok := strings.Cut(s, "-")
+	if ok {
+		if start, err = parseClock(from); err == nil {
+			end, err = parseClock(to)
+		}
+	}
+	if !ok || err != nil {
+		return 0, 0, fmt.Errorf("invalid quiet_hours %q, expected HH:MM-HH:MM", s)
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// InQuietHours reports whether t falls within the configured quiet hours
+func (c *Config) InQuietHours(t time.Time) bool {
+	if c.QuietHours == "" {
+		return false
+	}
+	start, end, err := parseQuietHours(c.QuietHours)
+	if err != nil {
+		return false
+	}
+	if c.QuietHoursTimezone != "" {
+		if loc, err := time.LoadLocation(c.QuietHoursTimezone); err == nil {
+			t = t.In(loc)
+		}
+	} else {
+		t = t.Local()
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// DuplicateCheckWindow returns how far back to look for identical sends
+func (c *Config) DuplicateCheckWindow() time.Duration {
+	if c.DuplicateWindow == "" {
+		return DefaultDuplicateWindow
+	}
+	d, _ := time.ParseDuration(c.DuplicateWindow)
+	return d
+}
+
+// Timeout returns the configured request timeout, or zero if unset
+func (c *Config) Timeout() time.Duration {
+	d, _ := time.ParseDuration(c.RequestTimeout)
+	return d
+}
+
 // Save writes the config to the specified path
 func Save(cfg *Config, path string) error {
 	if path == "" {
@@ -0,0 +1,72 @@
+package discord
+
+import "io"
+
+// API is the set of Discord operations used by the dca commands.
+// *Client implements it against the Discord REST API; tests point a Client
+// at the in-process server from internal/discord/fake instead.
+type API interface {
+	Close() error
+
+	ListGuilds() ([]*Guild, error)
+	GetGuild(guildID string) (*Guild, error)
+	ListChannels(guildID string) ([]*Channel, error)
+	GetChannel(channelID string) (*Channel, error)
+	ListRoles(guildID string) ([]*Role, error)
+	GetUser(userID string) (*Author, error)
+	GetMember(guildID, userID string) (*Author, error)
+
+	GetMessages(channelID string, opts HistoryOptions) (*MessagePage, error)
+	GetMessage(channelID, messageID string) (*Message, error)
+	SendMessage(channelID string, msg *OutgoingMessage) (*Message, error)
+	ReplyToMessage(channelID, messageID string, msg *OutgoingMessage) (*Message, error)
+	EditMessage(channelID, messageID, newContent string) (*Message, error)
+	DeleteMessage(channelID, messageID string) error
+	DownloadAttachment(url string, w io.Writer) (int64, error)
+
+	ListPins(channelID string) ([]*Message, error)
+	PinMessage(channelID, messageID string) error
+	UnpinMessage(channelID, messageID string) error
+
+	AddReaction(channelID, messageID, emoji string) error
+	RemoveReaction(channelID, messageID, emoji string) error
+	ListReactions(channelID, messageID string, opts ReactionListOptions) ([]*ReactionUsers, error)
+	ResolveEmoji(channelID, ref string) (string, error)
+
+	SendDirectMessage(userID string, msg *OutgoingMessage) (*Message, error)
+	GetDMChannel(userID string) (*Channel, error)
+	GetDMHistory(userID string, opts HistoryOptions) (*MessagePage, error)
+	ListDMChannels(limit int, activeOnly bool) ([]*DMChannel, error)
+	ListGroupDMs() ([]*GroupDM, error)
+	GetGroupDM(channelID string) (*GroupDM, error)
+	CreateGroupDM(userIDs []string) (*GroupDM, error)
+	AddGroupRecipient(channelID, userID string) error
+	RemoveGroupRecipient(channelID, userID string) error
+	RenameGroupDM(channelID, name string) (*GroupDM, error)
+	LookupUsers(query string) ([]*UserCandidate, error)
+	GetUserInfo(userID string) (*UserInfo, error)
+
+	ListRelationships() ([]*Friend, error)
+	SendFriendRequest(username string) error
+	AddFriend(userID string) error
+	BlockUser(userID string) error
+	RemoveRelationship(userID string) error
+
+	GetRecentActivity(opts ActivityOptions) ([]*ActivityMessage, error)
+
+	ListForumThreads(channelID string, opts ForumThreadOptions) ([]*ForumThread, error)
+	GetForum(channelID string) (*Forum, error)
+	CreateForumPost(channelID string, post *ForumPost) (*Thread, *Message, error)
+	GetThreadMessages(threadID string, opts HistoryOptions) (*MessagePage, error)
+
+	GetThread(threadID string) (*Thread, error)
+	ListActiveThreads(guildID, channelID string) ([]*Thread, error)
+	StartThread(channelID, messageID string, opts ThreadOptions) (*Thread, error)
+	JoinThread(threadID string) error
+	LeaveThread(threadID string) error
+	UpdateThread(threadID string, update ThreadUpdate) (*Thread, error)
+
+	SearchGuildMessages(guildID string, opts SearchOptions) (*SearchResult, error)
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,28 @@
+package discord
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DownloadAttachment streams an attachment from the CDN into w and returns
+// the number of bytes written. The user token is not sent along, since CDN
+// URLs are signed.
+func (c *Client) DownloadAttachment(url string, w io.Writer) (int64, error) {
+	resp, err := c.session.Client.Get(url)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download attachment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to download attachment: %s", resp.Status)
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("failed to download attachment: %w", err)
+	}
+	return n, nil
+}
@@ -4,15 +4,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/snowflake"
 )
 
 // Client wraps the Discord session
 type Client struct {
 	session *discordgo.Session
+
+	// Lookup caches, keyed by "guildID/userID" and channel ID
+	nicks         map[string]string
+	members       map[string]*Author
+	channelGuilds map[string]string
 }
 
 // New creates a new Discord client with a user token
@@ -23,7 +30,29 @@ func New(token string) (*Client, error) {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
-	return &Client{session: session}, nil
+	return &Client{
+		session:       session,
+		nicks:         make(map[string]string),
+		members:       make(map[string]*Author),
+		channelGuilds: make(map[string]string),
+	}, nil
+}
+
+// NewWithOptions creates a Discord client whose REST requests go through
+// the configured base URL, proxy, CA bundle and timeout
+func NewWithOptions(token string, opts Options) (*Client, error) {
+	httpClient, err := newHTTPClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := New(token)
+	if err != nil {
+		return nil, err
+	}
+
+	c.session.Client = httpClient
+	return c, nil
 }
 
 // Close closes the Discord session
@@ -83,17 +112,20 @@ type Channel struct {
 	Type     string `json:"type"`
 	Topic    string `json:"topic,omitempty"`
 	ParentID string `json:"parent_id,omitempty"`
+	GuildID  string `json:"guild_id,omitempty"`
 }
 
 // ForumThread represents a thread in a forum channel
 type ForumThread struct {
-	ID            string  `json:"id"`
-	Name          string  `json:"name"`
-	MessageCount  int     `json:"message_count"`
-	CreatedAt     string  `json:"created_at"`
-	LastMessageID string  `json:"last_message_id,omitempty"`
-	Archived      bool    `json:"archived"`
-	Author        *Author `json:"author,omitempty"`
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	MessageCount   int      `json:"message_count"`
+	CreatedAt      string   `json:"created_at"`
+	LastMessageID  string   `json:"last_message_id,omitempty"`
+	Archived       bool     `json:"archived"`
+	Author         *Author  `json:"author,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	LastActivityAt string   `json:"last_activity_at,omitempty"`
 }
 
 // channelTypeToString converts a ChannelType to a readable string
@@ -143,80 +175,256 @@ func (c *Client) ListChannels(guildID string) ([]*Channel, error) {
 			Type:     channelTypeToString(ch.Type),
 			Topic:    ch.Topic,
 			ParentID: ch.ParentID,
+			GuildID:  ch.GuildID,
 		})
 	}
 
 	return result, nil
 }
 
-// Message represents a Discord message
-type Message struct {
-	ID        string `json:"id"`
-	ChannelID string `json:"channel_id"`
-	Author    Author `json:"author"`
-	Content   string `json:"content"`
-	Timestamp string `json:"timestamp"`
+// GetChannel returns a single channel, thread or DM
+func (c *Client) GetChannel(channelID string) (*Channel, error) {
+	ch, err := c.session.Channel(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel: %w", err)
+	}
+
+	return &Channel{
+		ID:       ch.ID,
+		Name:     ch.Name,
+		Type:     channelTypeToString(ch.Type),
+		Topic:    ch.Topic,
+		ParentID: ch.ParentID,
+		GuildID:  ch.GuildID,
+	}, nil
 }
 
-// Author represents a message author
-type Author struct {
+// Role represents a guild role
+type Role struct {
 	ID       string `json:"id"`
-	Username string `json:"username"`
-	Bot      bool   `json:"bot"`
+	Name     string `json:"name"`
+	Color    int    `json:"color,omitempty"`
+	Position int    `json:"position"`
 }
 
-// GetMessages retrieves messages from a channel
-func (c *Client) GetMessages(channelID string, limit int) ([]*Message, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 50
+// ListRoles returns the roles of a guild
+func (c *Client) ListRoles(guildID string) ([]*Role, error) {
+	roles, err := c.session.GuildRoles(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
 	}
 
-	msgs, err := c.session.ChannelMessages(channelID, limit, "", "", "")
+	result := make([]*Role, 0, len(roles))
+	for _, r := range roles {
+		result = append(result, &Role{
+			ID:       r.ID,
+			Name:     r.Name,
+			Color:    r.Color,
+			Position: r.Position,
+		})
+	}
+
+	return result, nil
+}
+
+// GetUser returns a user by ID
+func (c *Client) GetUser(userID string) (*Author, error) {
+	u, err := c.session.User(userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get messages: %w", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	author := newAuthor(u)
+	return &author, nil
+}
+
+// maxPageSize is the most messages Discord returns for one history request
+const maxPageSize = 100
+
+// HistoryOptions selects which part of a channel's history to fetch. At most
+// one of Before, After and Around may be set; with none set the newest
+// messages are returned. Since and Until further restrict the result to
+// messages sent in [Since, Until); zero values leave that side open.
+type HistoryOptions struct {
+	Limit  int
+	Before string
+	After  string
+	Around string
+	Since  time.Time
+	Until  time.Time
+}
+
+// MessagePage is a window of channel history, newest first. NextCursor is
+// the message ID to pass as Before (or After, when paging forward) to get
+// the next page; it is empty once the history is exhausted.
+type MessagePage struct {
+	Messages   []*Message `json:"messages"`
+	NextCursor string     `json:"next_cursor"`
+}
+
+// GetMessages retrieves messages from a channel, paging through the history
+// until opts.Limit messages have been collected
+func (c *Client) GetMessages(channelID string, opts HistoryOptions) (*MessagePage, error) {
+	cursors := 0
+	for _, id := range []string{opts.Before, opts.After, opts.Around} {
+		if id != "" {
+			cursors++
+		}
+	}
+	if cursors > 1 {
+		return nil, fmt.Errorf("only one of before, after and around can be used")
+	}
+
+	if !opts.Since.IsZero() && !opts.Until.IsZero() && !opts.Since.Before(opts.Until) {
+		return nil, fmt.Errorf("since must be before until")
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	// The window bounds become ID cursors so Discord does the filtering;
+	// they also tell the pager when the window is covered
+	window := newIDWindow(opts.Since, opts.Until)
+
+	var msgs []*discordgo.Message
+	var nextCursor string
+	if opts.Around != "" {
+		// Discord centres a single page on the message; it cannot be continued
+		if limit > maxPageSize {
+			return nil, fmt.Errorf("around returns at most %d messages", maxPageSize)
+		}
+		page, err := c.session.ChannelMessages(channelID, limit, "", "", opts.Around)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get messages: %w", err)
+		}
+		for _, m := range page {
+			if window.contains(m.ID) {
+				msgs = append(msgs, m)
+			}
+		}
+	} else {
+		forward := opts.After != ""
+		cursor := opts.Before
+		if forward {
+			cursor = opts.After
+			// after is exclusive, so start just below the first ID of the window
+			if window.sinceID != "" && snowflake.Less(cursor, window.sinceID) {
+				cursor = snowflake.Prev(window.sinceID)
+			}
+		} else if window.untilID != "" && (cursor == "" || snowflake.Less(window.untilID, cursor)) {
+			cursor = window.untilID
+		}
+
+		for len(msgs) < limit {
+			size := limit - len(msgs)
+			if size > maxPageSize {
+				size = maxPageSize
+			}
+
+			var page []*discordgo.Message
+			var err error
+			if forward {
+				page, err = c.session.ChannelMessages(channelID, size, "", cursor, "")
+			} else {
+				page, err = c.session.ChannelMessages(channelID, size, cursor, "", "")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get messages: %w", err)
+			}
+
+			covered := false
+			for _, m := range page {
+				if window.contains(m.ID) {
+					msgs = append(msgs, m)
+				} else {
+					covered = true
+				}
+			}
+
+			if covered || len(page) < size {
+				nextCursor = ""
+				break
+			}
+			cursor = edgeMessageID(page, forward)
+			nextCursor = cursor
+		}
+
+		if forward {
+			sort.Slice(msgs, func(i, j int) bool { return snowflake.Less(msgs[j].ID, msgs[i].ID) })
+		}
 	}
 
 	result := make([]*Message, 0, len(msgs))
 	for _, m := range msgs {
-		result = append(result, &Message{
-			ID:        m.ID,
-			ChannelID: m.ChannelID,
-			Author: Author{
-				ID:       m.Author.ID,
-				Username: m.Author.Username,
-				Bot:      m.Author.Bot,
-			},
-			Content:   m.Content,
-			Timestamp: m.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
-		})
+		result = append(result, newMessage(m))
+	}
+	if len(msgs) > 0 {
+		if guildID, ok := c.messageGuildID(msgs[0]); ok {
+			for _, m := range result {
+				m.setGuild(guildID)
+			}
+			c.applyNicks(guildID, result)
+		}
 	}
 
-	return result, nil
+	return &MessagePage{Messages: result, NextCursor: nextCursor}, nil
+}
+
+// edgeMessageID returns the newest message ID in a page when paging forward
+// and the oldest otherwise
+func edgeMessageID(page []*discordgo.Message, newest bool) string {
+	edge := page[0].ID
+	for _, m := range page[1:] {
+		if snowflake.Less(m.ID, edge) != newest {
+			edge = m.ID
+		}
+	}
+	return edge
+}
+
+// idWindow holds the snowflake bounds of a [since, until) time window
+type idWindow struct {
+	sinceID string
+	untilID string
+}
+
+func newIDWindow(since, until time.Time) idWindow {
+	var w idWindow
+	if !since.IsZero() {
+		w.sinceID = snowflake.FromTime(since).String()
+	}
+	if !until.IsZero() {
+		w.untilID = snowflake.FromTime(until).String()
+	}
+	return w
+}
+
+// skipChannel reports whether a channel's last message predates the window,
+// which saves fetching its history at all
+func (w idWindow) skipChannel(lastMessageID string) bool {
+	return w.sinceID != "" && lastMessageID != "" && snowflake.Less(lastMessageID, w.sinceID)
+}
+
+// contains reports whether a message ID falls inside the window
+func (w idWindow) contains(id string) bool {
+	return (w.sinceID == "" || !snowflake.Less(id, w.sinceID)) && (w.untilID == "" || snowflake.Less(id, w.untilID))
 }
 
 // SendMessage sends a message to a channel
-func (c *Client) SendMessage(channelID, content string) (*Message, error) {
-	msg, err := c.session.ChannelMessageSend(channelID, content)
+func (c *Client) SendMessage(channelID string, out *OutgoingMessage) (*Message, error) {
+	msg, err := c.createMessage(channelID, out, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send message: %w", err)
 	}
 
-	return &Message{
-		ID:        msg.ID,
-		ChannelID: msg.ChannelID,
-		Author: Author{
-			ID:       msg.Author.ID,
-			Username: msg.Author.Username,
-			Bot:      msg.Author.Bot,
-		},
-		Content:   msg.Content,
-		Timestamp: msg.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+	return c.convertMessage(msg), nil
 }
 
 // ReplyToMessage replies to a specific message
-func (c *Client) ReplyToMessage(channelID, messageID, content string) (*Message, error) {
-	msg, err := c.session.ChannelMessageSendReply(channelID, content, &discordgo.MessageReference{
+func (c *Client) ReplyToMessage(channelID, messageID string, out *OutgoingMessage) (*Message, error) {
+	msg, err := c.createMessage(channelID, out, &discordgo.MessageReference{
 		MessageID: messageID,
 		ChannelID: channelID,
 	})
@@ -224,21 +432,11 @@ func (c *Client) ReplyToMessage(channelID, messageID, content string) (*Message,
 		return nil, fmt.Errorf("failed to reply to message: %w", err)
 	}
 
-	return &Message{
-		ID:        msg.ID,
-		ChannelID: msg.ChannelID,
-		Author: Author{
-			ID:       msg.Author.ID,
-			Username: msg.Author.Username,
-			Bot:      msg.Author.Bot,
-		},
-		Content:   msg.Content,
-		Timestamp: msg.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+	return c.convertMessage(msg), nil
 }
 
 // SendDirectMessage sends a DM to a user
-func (c *Client) SendDirectMessage(userID, content string) (*Message, error) {
+func (c *Client) SendDirectMessage(userID string, out *OutgoingMessage) (*Message, error) {
 	// Create or get DM channel with user
 	channel, err := c.session.UserChannelCreate(userID)
 	if err != nil {
@@ -246,26 +444,31 @@ func (c *Client) SendDirectMessage(userID, content string) (*Message, error) {
 	}
 
 	// Send message to DM channel
-	msg, err := c.session.ChannelMessageSend(channel.ID, content)
+	msg, err := c.createMessage(channel.ID, out, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send DM: %w", err)
 	}
 
-	return &Message{
-		ID:        msg.ID,
-		ChannelID: msg.ChannelID,
-		Author: Author{
-			ID:       msg.Author.ID,
-			Username: msg.Author.Username,
-			Bot:      msg.Author.Bot,
-		},
-		Content:   msg.Content,
-		Timestamp: msg.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
+	result := newMessage(msg)
+	result.setGuild("")
+	return result, nil
+}
+
+// GetDMChannel returns the DM channel with a user, opening it if needed
+func (c *Client) GetDMChannel(userID string) (*Channel, error) {
+	ch, err := c.session.UserChannelCreate(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DM channel: %w", err)
+	}
+
+	return &Channel{
+		ID:   ch.ID,
+		Type: channelTypeToString(ch.Type),
 	}, nil
 }
 
 // GetDMHistory gets message history from DMs with a user
-func (c *Client) GetDMHistory(userID string, limit int) ([]*Message, error) {
+func (c *Client) GetDMHistory(userID string, opts HistoryOptions) (*MessagePage, error) {
 	// Create or get DM channel with user
 	channel, err := c.session.UserChannelCreate(userID)
 	if err != nil {
@@ -273,24 +476,49 @@ func (c *Client) GetDMHistory(userID string, limit int) ([]*Message, error) {
 	}
 
 	// Get messages
-	return c.GetMessages(channel.ID, limit)
+	return c.GetMessages(channel.ID, opts)
 }
 
-// DMChannel represents a DM conversation
+// DMChannel represents a DM conversation. Type is "dm" with the other
+// person in User, or "group_dm" with the group's name, icon and recipients
+// in Group.
 type DMChannel struct {
 	ChannelID   string   `json:"channel_id"`
-	User        Author   `json:"user"`
+	Type        string   `json:"type"`
+	User        *Author  `json:"user,omitempty"`
+	Group       *GroupDM `json:"group,omitempty"`
 	LastMessage *Message `json:"last_message,omitempty"`
 }
 
+// newDMChannel describes a DM or group DM channel
+func newDMChannel(ch *discordgo.Channel) *DMChannel {
+	dm := &DMChannel{
+		ChannelID: ch.ID,
+		Type:      channelTypeToString(ch.Type),
+	}
+	if ch.Type == discordgo.ChannelTypeGroupDM {
+		dm.Group = newGroupDM(ch)
+	} else if len(ch.Recipients) > 0 {
+		author := newAuthor(ch.Recipients[0])
+		dm.User = &author
+	}
+	return dm
+}
+
+// isDM reports whether a channel is a DM or group DM
+func isDM(ch *discordgo.Channel) bool {
+	return ch.Type == discordgo.ChannelTypeDM || ch.Type == discordgo.ChannelTypeGroupDM
+}
+
 // ActivityMessage represents a message with full context
 type ActivityMessage struct {
 	Message
-	Type        string  `json:"type"` // "dm" or "server"
-	ServerName  string  `json:"server_name,omitempty"`
-	ServerID    string  `json:"server_id,omitempty"`
-	ChannelName string  `json:"channel_name,omitempty"`
-	DMUser      *Author `json:"dm_user,omitempty"`
+	Source      string   `json:"source"` // "dm" or "server"
+	ServerName  string   `json:"server_name,omitempty"`
+	ServerID    string   `json:"server_id,omitempty"`
+	ChannelName string   `json:"channel_name,omitempty"`
+	DMUser      *Author  `json:"dm_user,omitempty"`
+	DMGroup     *GroupDM `json:"dm_group,omitempty"`
 }
 
 // EditMessage edits a message
@@ -300,17 +528,7 @@ func (c *Client) EditMessage(channelID, messageID, newContent string) (*Message,
 		return nil, fmt.Errorf("failed to edit message: %w", err)
 	}
 
-	return &Message{
-		ID:        msg.ID,
-		ChannelID: msg.ChannelID,
-		Author: Author{
-			ID:       msg.Author.ID,
-			Username: msg.Author.Username,
-			Bot:      msg.Author.Bot,
-		},
-		Content:   msg.Content,
-		Timestamp: msg.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+	return c.convertMessage(msg), nil
 }
 
 // DeleteMessage deletes a message
@@ -329,44 +547,58 @@ func (c *Client) GetMessage(channelID, messageID string) (*Message, error) {
 		return nil, fmt.Errorf("failed to get message: %w", err)
 	}
 
-	return &Message{
-		ID:        msg.ID,
-		ChannelID: msg.ChannelID,
-		Author: Author{
-			ID:       msg.Author.ID,
-			Username: msg.Author.Username,
-			Bot:      msg.Author.Bot,
-		},
-		Content:   msg.Content,
-		Timestamp: msg.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+	return c.convertMessage(msg), nil
 }
 
-// AddReaction adds a reaction to a message
+// AddReaction adds a reaction to a message. emoji is resolved as by
+// ResolveEmoji.
 func (c *Client) AddReaction(channelID, messageID, emoji string) error {
-	err := c.session.MessageReactionAdd(channelID, messageID, emoji)
+	emoji, err := c.ResolveEmoji(channelID, emoji)
+	if err != nil {
+		return err
+	}
+	err = c.session.MessageReactionAdd(channelID, messageID, emoji)
 	if err != nil {
 		return fmt.Errorf("failed to add reaction: %w", err)
 	}
 	return nil
 }
 
-// RemoveReaction removes your reaction from a message
+// RemoveReaction removes your reaction from a message. emoji is resolved
+// as by ResolveEmoji.
 func (c *Client) RemoveReaction(channelID, messageID, emoji string) error {
-	err := c.session.MessageReactionRemove(channelID, messageID, emoji, "@me")
+	emoji, err := c.ResolveEmoji(channelID, emoji)
+	if err != nil {
+		return err
+	}
+	err = c.session.MessageReactionRemove(channelID, messageID, emoji, "@me")
 	if err != nil {
 		return fmt.Errorf("failed to remove reaction: %w", err)
 	}
 	return nil
 }
 
+// ActivityOptions configures GetRecentActivity
+type ActivityOptions struct {
+	Limit int
+	// Type is "all", "dm" or "server"
+	Type string
+	// Since and Until restrict activity to messages sent in [Since, Until)
+	Since time.Time
+	Until time.Time
+}
+
 // GetRecentActivity gets recent messages across all DMs and servers
-func (c *Client) GetRecentActivity(limit int, filterType string) ([]*ActivityMessage, error) {
+func (c *Client) GetRecentActivity(opts ActivityOptions) ([]*ActivityMessage, error) {
 	var allMessages []*ActivityMessage
+	limit := opts.Limit
+	filterType := opts.Type
+
+	window := newIDWindow(opts.Since, opts.Until)
 
 	// Get DM messages if requested
 	if filterType == "all" || filterType == "dm" {
-		dmMessages, err := c.getRecentDMMessages(5) // Get recent from each DM
+		dmMessages, err := c.getRecentDMMessages(5, window) // Get recent from each DM
 		if err == nil {
 			allMessages = append(allMessages, dmMessages...)
 		}
@@ -374,7 +606,7 @@ func (c *Client) GetRecentActivity(limit int, filterType string) ([]*ActivityMes
 
 	// Get server messages if requested
 	if filterType == "all" || filterType == "server" {
-		serverMessages, err := c.getRecentServerMessages(5) // Get recent from each channel
+		serverMessages, err := c.getRecentServerMessages(5, window) // Get recent from each channel
 		if err == nil {
 			allMessages = append(allMessages, serverMessages...)
 		}
@@ -394,59 +626,49 @@ func (c *Client) GetRecentActivity(limit int, filterType string) ([]*ActivityMes
 		allMessages = allMessages[:limit]
 	}
 
+	for _, m := range allMessages {
+		if m.Source == "server" {
+			c.applyNicks(m.ServerID, []*Message{&m.Message})
+		}
+	}
+
 	return allMessages, nil
 }
 
-func (c *Client) getRecentDMMessages(perChannel int) ([]*ActivityMessage, error) {
+func (c *Client) getRecentDMMessages(perChannel int, window idWindow) ([]*ActivityMessage, error) {
 	var messages []*ActivityMessage
 
-	// Get DM channels
-	var channels []*discordgo.Channel
-	body, err := c.session.RequestWithBucketID("GET", discordgo.EndpointUserChannels("@me"), nil, discordgo.EndpointUserChannels(""))
+	// Get DM and group DM channels
+	channels, err := c.dmChannels()
 	if err != nil {
 		return nil, err
 	}
 
-	if err = discordgo.Unmarshal(body, &channels); err != nil {
-		return nil, err
-	}
-
 	// Get messages from each DM
 	for _, ch := range channels {
-		if ch.Type != discordgo.ChannelTypeDM {
+		if !isDM(ch) || window.skipChannel(ch.LastMessageID) {
 			continue
 		}
 
-		msgs, err := c.session.ChannelMessages(ch.ID, perChannel, "", "", "")
+		msgs, err := c.session.ChannelMessages(ch.ID, perChannel, window.untilID, "", "")
 		if err != nil || len(msgs) == 0 {
 			continue
 		}
 
-		// Get DM user
-		var dmUser *Author
-		if len(ch.Recipients) > 0 {
-			dmUser = &Author{
-				ID:       ch.Recipients[0].ID,
-				Username: ch.Recipients[0].Username,
-				Bot:      ch.Recipients[0].Bot,
-			}
-		}
+		// Get DM user, or the group for group DMs
+		dm := newDMChannel(ch)
 
 		for _, msg := range msgs {
+			if !window.contains(msg.ID) {
+				continue
+			}
+			m := newMessage(msg)
+			m.setGuild("")
 			messages = append(messages, &ActivityMessage{
-				Message: Message{
-					ID:        msg.ID,
-					ChannelID: msg.ChannelID,
-					Author: Author{
-						ID:       msg.Author.ID,
-						Username: msg.Author.Username,
-						Bot:      msg.Author.Bot,
-					},
-					Content:   msg.Content,
-					Timestamp: msg.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
-				},
-				Type:   "dm",
-				DMUser: dmUser,
+				Message: *m,
+				Source:  "dm",
+				DMUser:  dm.User,
+				DMGroup: dm.Group,
 			})
 		}
 	}
@@ -454,59 +676,13 @@ func (c *Client) getRecentDMMessages(perChannel int) ([]*ActivityMessage, error)
 	return messages, nil
 }
 
-// ListForumThreads lists threads in a forum channel (archived and active)
-func (c *Client) ListForumThreads(channelID string, limit int, activeOnly bool) ([]*ForumThread, error) {
-	// Try to get archived public threads (works with user tokens)
-	// This is a workaround since active threads endpoint is bot-only
-	endpoint := fmt.Sprintf("%s/channels/%s/threads/archived/public", discordgo.EndpointAPI, channelID)
-
-	type ThreadResponse struct {
-		Threads []*discordgo.Channel `json:"threads"`
-		HasMore bool                 `json:"has_more"`
-	}
-
-	var response ThreadResponse
-	body, err := c.session.RequestWithBucketID("GET", endpoint, nil, endpoint)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get forum threads (note: user tokens can only see archived public threads): %w", err)
-	}
-
-	if err = discordgo.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse forum threads: %w", err)
-	}
-
-	result := make([]*ForumThread, 0)
-	for _, thread := range response.Threads {
-		// Skip archived if activeOnly
-		if activeOnly && thread.ThreadMetadata != nil && thread.ThreadMetadata.Archived {
-			continue
-		}
-
-		forumThread := &ForumThread{
-			ID:            thread.ID,
-			Name:          thread.Name,
-			MessageCount:  thread.MessageCount,
-			LastMessageID: thread.LastMessageID,
-			Archived:      thread.ThreadMetadata != nil && thread.ThreadMetadata.Archived,
-		}
-
-		result = append(result, forumThread)
-
-		if limit > 0 && len(result) >= limit {
-			break
-		}
-	}
-
-	return result, nil
-}
-
 // GetThreadMessages gets messages from a specific thread
-func (c *Client) GetThreadMessages(threadID string, limit int) ([]*Message, error) {
+func (c *Client) GetThreadMessages(threadID string, opts HistoryOptions) (*MessagePage, error) {
 	// Threads are just channels, so we can use the regular GetMessages
-	return c.GetMessages(threadID, limit)
+	return c.GetMessages(threadID, opts)
 }
 
-func (c *Client) getRecentServerMessages(perChannel int) ([]*ActivityMessage, error) {
+func (c *Client) getRecentServerMessages(perChannel int, window idWindow) ([]*ActivityMessage, error) {
 	var messages []*ActivityMessage
 
 	// Get guilds
@@ -533,25 +709,24 @@ func (c *Client) getRecentServerMessages(perChannel int) ([]*ActivityMessage, er
 			}
 			checked++
 
-			msgs, err := c.session.ChannelMessages(ch.ID, perChannel, "", "", "")
+			if window.skipChannel(ch.LastMessageID) {
+				continue
+			}
+
+			msgs, err := c.session.ChannelMessages(ch.ID, perChannel, window.untilID, "", "")
 			if err != nil {
 				continue
 			}
 
 			for _, msg := range msgs {
+				if !window.contains(msg.ID) {
+					continue
+				}
+				m := newMessage(msg)
+				m.setGuild(guild.ID)
 				messages = append(messages, &ActivityMessage{
-					Message: Message{
-						ID:        msg.ID,
-						ChannelID: msg.ChannelID,
-						Author: Author{
-							ID:       msg.Author.ID,
-							Username: msg.Author.Username,
-							Bot:      msg.Author.Bot,
-						},
-						Content:   msg.Content,
-						Timestamp: msg.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
-					},
-					Type:        "server",
+					Message:     *m,
+					Source:      "server",
 					ServerName:  guild.Name,
 					ServerID:    guild.ID,
 					ChannelName: ch.Name,
@@ -563,24 +738,21 @@ func (c *Client) getRecentServerMessages(perChannel int) ([]*ActivityMessage, er
 	return messages, nil
 }
 
-// ListDMChannels returns all DM channels sorted by recent activity
+// ListDMChannels returns all DM and group DM channels sorted by recent
+// activity
 func (c *Client) ListDMChannels(limit int, activeOnly bool) ([]*DMChannel, error) {
 	// Get user's DM channels
-	var channels []*discordgo.Channel
-	body, err := c.session.RequestWithBucketID("GET", discordgo.EndpointUserChannels("@me"), nil, discordgo.EndpointUserChannels(""))
+	channels, err := c.dmChannels()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get DM channels: %w", err)
-	}
-
-	if err = discordgo.Unmarshal(body, &channels); err != nil {
-		return nil, fmt.Errorf("failed to parse DM channels: %w", err)
+		return nil, err
 	}
 
 	result := make([]*DMChannel, 0)
 	for _, ch := range channels {
-		if ch.Type != discordgo.ChannelTypeDM {
+		if !isDM(ch) {
 			continue
 		}
+		dmChannel := newDMChannel(ch)
 
 		// Get last message
 		msgs, err := c.session.ChannelMessages(ch.ID, 1, "", "", "")
@@ -589,44 +761,12 @@ func (c *Client) ListDMChannels(limit int, activeOnly bool) ([]*DMChannel, error
 				continue // Skip DMs with no messages if activeOnly
 			}
 			// Include DM with no last message
-			if len(ch.Recipients) > 0 {
-				result = append(result, &DMChannel{
-					ChannelID: ch.ID,
-					User: Author{
-						ID:       ch.Recipients[0].ID,
-						Username: ch.Recipients[0].Username,
-						Bot:      ch.Recipients[0].Bot,
-					},
-				})
-			}
+			result = append(result, dmChannel)
 			continue
 		}
 
-		lastMsg := msgs[0]
-		dmChannel := &DMChannel{
-			ChannelID: ch.ID,
-			LastMessage: &Message{
-				ID:        lastMsg.ID,
-				ChannelID: lastMsg.ChannelID,
-				Author: Author{
-					ID:       lastMsg.Author.ID,
-					Username: lastMsg.Author.Username,
-					Bot:      lastMsg.Author.Bot,
-				},
-				Content:   lastMsg.Content,
-				Timestamp: lastMsg.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
-			},
-		}
-
-		// Set user (recipient)
-		if len(ch.Recipients) > 0 {
-			dmChannel.User = Author{
-				ID:       ch.Recipients[0].ID,
-				Username: ch.Recipients[0].Username,
-				Bot:      ch.Recipients[0].Bot,
-			}
-		}
-
+		dmChannel.LastMessage = newMessage(msgs[0])
+		dmChannel.LastMessage.setGuild("")
 		result = append(result, dmChannel)
 	}
 
@@ -656,92 +796,6 @@ func (c *Client) ListDMChannels(limit int, activeOnly bool) ([]*DMChannel, error
 	return result, nil
 }
 
-// FindUserByUsername searches for a user by username across DMs and guilds
-func (c *Client) FindUserByUsername(username string) (*Author, error) {
-	// First check DM channels (most likely location)
-	fmt.Printf("   Searching DM channels...\n")
-
-	// Get user's DM channels using REST API
-	var dmChannels []*discordgo.Channel
-	body, err := c.session.RequestWithBucketID("GET", discordgo.EndpointUserChannels("@me"), nil, discordgo.EndpointUserChannels(""))
-	if err == nil {
-		err = discordgo.Unmarshal(body, &dmChannels)
-		if err == nil {
-			for _, channel := range dmChannels {
-				if channel.Type != discordgo.ChannelTypeDM {
-					continue
-				}
-
-				// Get recent messages from DM
-				msgs, err := c.session.ChannelMessages(channel.ID, 20, "", "", "")
-				if err != nil {
-					continue
-				}
-
-				// Look for username match
-				for _, msg := range msgs {
-					if strings.EqualFold(msg.Author.Username, username) {
-						return &Author{
-							ID:       msg.Author.ID,
-							Username: msg.Author.Username,
-							Bot:      msg.Author.Bot,
-						}, nil
-					}
-				}
-			}
-		}
-	}
-
-	// If not found in DMs, search guilds
-	guilds, err := c.session.UserGuilds(100, "", "", false)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list guilds: %w", err)
-	}
-
-	fmt.Printf("   Searching %d servers...\n", len(guilds))
-
-	// Search through each guild's channels for messages from this user
-	for i, guild := range guilds {
-		fmt.Printf("   [%d/%d] %s\n", i+1, len(guilds), guild.Name)
-
-		channels, err := c.session.GuildChannels(guild.ID)
-		if err != nil {
-			continue // Skip inaccessible guilds
-		}
-
-		// Check text channels only, limit to first 5 channels per guild for speed
-		checked := 0
-		for _, channel := range channels {
-			if channel.Type != discordgo.ChannelTypeGuildText {
-				continue
-			}
-			if checked >= 5 {
-				break // Limit search depth
-			}
-			checked++
-
-			// Get recent messages
-			msgs, err := c.session.ChannelMessages(channel.ID, 20, "", "", "")
-			if err != nil {
-				continue // Skip inaccessible channels
-			}
-
-			// Look for username match
-			for _, msg := range msgs {
-				if strings.EqualFold(msg.Author.Username, username) {
-					return &Author{
-						ID:       msg.Author.ID,
-						Username: msg.Author.Username,
-						Bot:      msg.Author.Bot,
-					}, nil
-				}
-			}
-		}
-	}
-
-	return nil, fmt.Errorf("user %q not found in recent messages", username)
-}
-
 // SearchOptions configures a Discord message search query
 type SearchOptions struct {
 	Content   string
@@ -751,6 +805,11 @@ type SearchOptions struct {
 	Offset    int
 	SortBy    string
 	SortOrder string
+	// Pinned restricts hits to pinned messages
+	Pinned bool
+	// Since and Until restrict hits to [Since, Until) via min_id/max_id
+	Since time.Time
+	Until time.Time
 }
 
 // SearchResult holds the parsed search response
@@ -761,11 +820,7 @@ type SearchResult struct {
 
 // SearchMessage represents a single search hit
 type SearchMessage struct {
-	ID        string `json:"id"`
-	ChannelID string `json:"channel_id"`
-	Author    Author `json:"author"`
-	Content   string `json:"content"`
-	Timestamp string `json:"timestamp"`
+	Message
 }
 
 // SearchGuildMessages searches for messages in a guild using Discord's search API
@@ -779,7 +834,15 @@ func (c *Client) SearchGuildMessages(guildID string, opts SearchOptions) (*Searc
 		return nil, fmt.Errorf("failed to search guild messages: %w", err)
 	}
 
-	return parseSearchResponse(body)
+	result, err := parseSearchResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range result.Messages {
+		m.setGuild(guildID)
+		c.applyNicks(guildID, []*Message{&m.Message})
+	}
+	return result, nil
 }
 
 // buildSearchParams constructs URL query parameters from SearchOptions
@@ -804,6 +867,16 @@ func buildSearchParams(opts SearchOptions) url.Values {
 	if opts.SortOrder != "" {
 		params.Set("sort_order", opts.SortOrder)
 	}
+	if opts.Pinned {
+		params.Set("pinned", "true")
+	}
+	// min_id is exclusive, max_id is the exclusive upper bound we want
+	if !opts.Since.IsZero() {
+		params.Set("min_id", snowflake.Prev(snowflake.FromTime(opts.Since).String()))
+	}
+	if !opts.Until.IsZero() {
+		params.Set("max_id", snowflake.FromTime(opts.Until).String())
+	}
 	return params
 }
 
@@ -827,34 +900,17 @@ func parseSearchResponse(body []byte) (*SearchResult, error) {
 
 	for _, group := range raw.Messages {
 		for _, msgRaw := range group {
-			var msg struct {
-				ID        string `json:"id"`
-				ChannelID string `json:"channel_id"`
-				Content   string `json:"content"`
-				Timestamp string `json:"timestamp"`
-				Hit       bool   `json:"hit"`
-				Author    struct {
-					ID       string `json:"id"`
-					Username string `json:"username"`
-					Bot      bool   `json:"bot"`
-				} `json:"author"`
+			var hit struct {
+				Hit bool `json:"hit"`
 			}
-			if err := json.Unmarshal(msgRaw, &msg); err != nil {
+			if err := json.Unmarshal(msgRaw, &hit); err != nil || !hit.Hit {
 				continue
 			}
-			if msg.Hit {
-				result.Messages = append(result.Messages, &SearchMessage{
-					ID:        msg.ID,
-					ChannelID: msg.ChannelID,
-					Author: Author{
-						ID:       msg.Author.ID,
-						Username: msg.Author.Username,
-						Bot:      msg.Author.Bot,
-					},
-					Content:   msg.Content,
-					Timestamp: msg.Timestamp,
-				})
+			var msg discordgo.Message
+			if err := json.Unmarshal(msgRaw, &msg); err != nil {
+				continue
 			}
+			result.Messages = append(result.Messages, &SearchMessage{Message: *newMessage(&msg)})
 		}
 	}
 
@@ -0,0 +1,177 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord's embed limits, counted in characters
+const (
+	MaxEmbeds               = 10
+	MaxEmbedTitle           = 256
+	MaxEmbedDescription     = 4096
+	MaxEmbedFields          = 25
+	MaxEmbedFieldName       = 256
+	MaxEmbedFieldValue      = 1024
+	MaxEmbedFooter          = 2048
+	MaxEmbedAuthorName      = 256
+	MaxEmbedCharactersTotal = 6000
+)
+
+// ParseEmbedJSON reads embeds in Discord's API format. It accepts a single
+// embed object, an array of them, or a message payload with an "embeds"
+// array, as produced by most embed builders. The embeds are sent as parsed;
+// the returned view only serves validation and previews.
+func ParseEmbedJSON(data []byte) ([]*Embed, error) {
+	data = bytes.TrimSpace(data)
+
+	var raw []*discordgo.MessageEmbed
+	switch {
+	case len(data) > 0 && data[0] == '[':
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("invalid embed JSON: %w", err)
+		}
+	default:
+		var probe struct {
+			Embeds []*discordgo.MessageEmbed `json:"embeds"`
+		}
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, fmt.Errorf("invalid embed JSON: %w", err)
+		}
+		if probe.Embeds != nil {
+			raw = probe.Embeds
+			break
+		}
+		var embed discordgo.MessageEmbed
+		if err := json.Unmarshal(data, &embed); err != nil {
+			return nil, fmt.Errorf("invalid embed JSON: %w", err)
+		}
+		raw = []*discordgo.MessageEmbed{&embed}
+	}
+
+	embeds := make([]*Embed, 0, len(raw))
+	for _, e := range raw {
+		if e == nil {
+			return nil, fmt.Errorf("invalid embed JSON: null embed")
+		}
+		embed := newEmbed(e)
+		embed.payload = e
+		embeds = append(embeds, embed)
+	}
+	return embeds, nil
+}
+
+// ValidateEmbeds checks embeds against Discord's limits so an oversized
+// embed is rejected before anything is sent
+func ValidateEmbeds(embeds []*Embed) error {
+	if len(embeds) > MaxEmbeds {
+		return fmt.Errorf("at most %d embeds can be sent, got %d", MaxEmbeds, len(embeds))
+	}
+
+	total := 0
+	for i, e := range embeds {
+		n, err := e.validate()
+		if err != nil {
+			if len(embeds) > 1 {
+				return fmt.Errorf("embed %d: %w", i+1, err)
+			}
+			return fmt.Errorf("embed: %w", err)
+		}
+		total += n
+	}
+	if total > MaxEmbedCharactersTotal {
+		return fmt.Errorf("embeds have %d characters in total, the limit is %d", total, MaxEmbedCharactersTotal)
+	}
+	return nil
+}
+
+// validate checks a single embed and returns its character count
+func (e *Embed) validate() (int, error) {
+	if e.Title == "" && e.Description == "" && len(e.Fields) == 0 && e.AuthorName == "" &&
+		e.Footer == "" && e.ImageURL == "" && e.ThumbnailURL == "" {
+		return 0, fmt.Errorf("no title, description, fields, author, footer or image")
+	}
+	if e.Color < 0 || e.Color > 0xFFFFFF {
+		return 0, fmt.Errorf("color %d is out of range", e.Color)
+	}
+	if e.Timestamp != "" {
+		if _, err := time.Parse(time.RFC3339, e.Timestamp); err != nil {
+			return 0, fmt.Errorf("timestamp %q is not RFC 3339", e.Timestamp)
+		}
+	}
+
+	total := 0
+	check := func(what, s string, limit int) error {
+		n := utf8.RuneCountInString(s)
+		if n > limit {
+			return fmt.Errorf("%s is %d characters, the limit is %d", what, n, limit)
+		}
+		total += n
+		return nil
+	}
+
+	if err := check("title", e.Title, MaxEmbedTitle); err != nil {
+		return 0, err
+	}
+	if err := check("description", e.Description, MaxEmbedDescription); err != nil {
+		return 0, err
+	}
+	if err := check("footer", e.Footer, MaxEmbedFooter); err != nil {
+		return 0, err
+	}
+	if err := check("author name", e.AuthorName, MaxEmbedAuthorName); err != nil {
+		return 0, err
+	}
+	if len(e.Fields) > MaxEmbedFields {
+		return 0, fmt.Errorf("at most %d fields are allowed, got %d", MaxEmbedFields, len(e.Fields))
+	}
+	for i, f := range e.Fields {
+		if f.Name == "" || f.Value == "" {
+			return 0, fmt.Errorf("field %d needs both a name and a value", i+1)
+		}
+		if err := check(fmt.Sprintf("field %d name", i+1), f.Name, MaxEmbedFieldName); err != nil {
+			return 0, err
+		}
+		if err := check(fmt.Sprintf("field %d value", i+1), f.Value, MaxEmbedFieldValue); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// messageEmbed converts an embed back into Discord's format for sending.
+// Parsed embeds are sent as they were given.
+func (e *Embed) messageEmbed() *discordgo.MessageEmbed {
+	if e.payload != nil {
+		return e.payload
+	}
+	embed := &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       e.Title,
+		Description: e.Description,
+		URL:         e.URL,
+		Color:       e.Color,
+		Timestamp:   e.Timestamp,
+	}
+	if e.AuthorName != "" {
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: e.AuthorName}
+	}
+	for _, f := range e.Fields {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: f.Name, Value: f.Value, Inline: f.Inline})
+	}
+	if e.Footer != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: e.Footer}
+	}
+	if e.ImageURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: e.ImageURL}
+	}
+	if e.ThumbnailURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: e.ThumbnailURL}
+	}
+	return embed
+}
@@ -0,0 +1,92 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEmbedJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{"object", `{"title": "Deploy", "fields": [{"name": "env", "value": "prod"}]}`, 1},
+		{"array", `[{"title": "one"}, {"title": "two"}]`, 2},
+		{"payload", `{"content": "hi", "embeds": [{"title": "one"}]}`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embeds, err := ParseEmbedJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(embeds) != tt.want {
+				t.Fatalf("expected %d embeds, got %d", tt.want, len(embeds))
+			}
+		})
+	}
+
+	embeds, err := ParseEmbedJSON([]byte(`{"title": "Deploy", "color": 5793266, "footer": {"text": "ci"}, "author": {"name": "bot"}, "timestamp": "2026-02-24T10:00:00Z"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := embeds[0]
+	if e.Title != "Deploy" || e.Color != 5793266 || e.Footer != "ci" || e.AuthorName != "bot" || e.Timestamp != "2026-02-24T10:00:00Z" {
+		t.Errorf("unexpected embed: %+v", e)
+	}
+
+	if _, err := ParseEmbedJSON([]byte(`{"title": `)); err == nil {
+		t.Error("expected malformed JSON to fail")
+	}
+}
+
+func TestValidateEmbeds(t *testing.T) {
+	many := func(n int) []*Embed {
+		embeds := make([]*Embed, n)
+		for i := range embeds {
+			embeds[i] = &Embed{Title: "t"}
+		}
+		return embeds
+	}
+	fields := func(n int) []*EmbedField {
+		f := make([]*EmbedField, n)
+		for i := range f {
+			f[i] = &EmbedField{Name: "n", Value: "v"}
+		}
+		return f
+	}
+
+	tests := []struct {
+		name    string
+		embeds  []*Embed
+		wantErr string
+	}{
+		{"ok", []*Embed{{Title: "Deploy", Fields: fields(25)}}, ""},
+		{"empty", []*Embed{{}}, "embed: no title"},
+		{"title", []*Embed{{Title: strings.Repeat("é", 257)}}, "title is 257 characters"},
+		{"description", []*Embed{{Description: strings.Repeat("x", 4097)}}, "description"},
+		{"fields", []*Embed{{Fields: fields(26)}}, "at most 25 fields"},
+		{"field value", []*Embed{{Fields: []*EmbedField{{Name: "n", Value: strings.Repeat("x", 1025)}}}}, "field 1 value"},
+		{"field name missing", []*Embed{{Fields: []*EmbedField{{Value: "v"}}}}, "needs both"},
+		{"color", []*Embed{{Title: "t", Color: 0x1000000}}, "color"},
+		{"timestamp", []*Embed{{Title: "t", Timestamp: "yesterday"}}, "RFC 3339"},
+		{"count", many(11), "at most 10 embeds"},
+		{"total", []*Embed{{Description: strings.Repeat("x", 4000)}, {Description: strings.Repeat("x", 2001)}}, "6001 characters in total"},
+		{"numbered", []*Embed{{Title: "t"}, {}}, "embed 2: no title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEmbeds(tt.embeds)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
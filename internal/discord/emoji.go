@@ -0,0 +1,234 @@
+package discord
+
+// emojiShortcodes maps the shortcodes Discord's emoji picker accepts for the
+// most common standard emoji to their unicode form
+var emojiShortcodes = map[string]string{
+	// Hands and gestures
+	"thumbsup":        "👍",
+	"+1":              "👍",
+	"thumbup":         "👍",
+	"thumbsdown":      "👎",
+	"-1":              "👎",
+	"thumbdown":       "👎",
+	"ok_hand":         "👌",
+	"clap":            "👏",
+	"wave":            "👋",
+	"raised_hands":    "🙌",
+	"pray":            "🙏",
+	"muscle":          "💪",
+	"point_up":        "☝️",
+	"point_down":      "👇",
+	"point_left":      "👈",
+	"point_right":     "👉",
+	"v":               "✌️",
+	"crossed_fingers": "🤞",
+	"handshake":       "🤝",
+	"raised_hand":     "✋",
+	"fist":            "✊",
+	"punch":           "👊",
+	"writing_hand":    "✍️",
+	"eyes":            "👀",
+	"brain":           "🧠",
+	"saluting_face":   "🫡",
+
+	// Faces
+	"smile":                 "😄",
+	"smiley":                "😃",
+	"grinning":              "😀",
+	"grin":                  "😁",
+	"laughing":              "😆",
+	"satisfied":             "😆",
+	"sweat_smile":           "😅",
+	"joy":                   "😂",
+	"rofl":                  "🤣",
+	"slight_smile":          "🙂",
+	"upside_down":           "🙃",
+	"wink":                  "😉",
+	"blush":                 "😊",
+	"innocent":              "😇",
+	"heart_eyes":            "😍",
+	"star_struck":           "🤩",
+	"kissing_heart":         "😘",
+	"yum":                   "😋",
+	"stuck_out_tongue":      "😛",
+	"thinking":              "🤔",
+	"thinking_face":         "🤔",
+	"zipper_mouth":          "🤐",
+	"raised_eyebrow":        "🤨",
+	"neutral_face":          "😐",
+	"expressionless":        "😑",
+	"no_mouth":              "😶",
+	"smirk":                 "😏",
+	"unamused":              "😒",
+	"rolling_eyes":          "🙄",
+	"grimacing":             "😬",
+	"relieved":              "😌",
+	"pensive":               "😔",
+	"sleepy":                "😪",
+	"sleeping":              "😴",
+	"mask":                  "😷",
+	"nerd":                  "🤓",
+	"sunglasses":            "😎",
+	"confused":              "😕",
+	"worried":               "😟",
+	"slight_frown":          "🙁",
+	"frowning2":             "☹️",
+	"open_mouth":            "😮",
+	"astonished":            "😲",
+	"flushed":               "😳",
+	"pleading_face":         "🥺",
+	"cry":                   "😢",
+	"sob":                   "😭",
+	"scream":                "😱",
+	"weary":                 "😩",
+	"tired_face":            "😫",
+	"triumph":               "😤",
+	"rage":                  "😡",
+	"angry":                 "😠",
+	"skull":                 "💀",
+	"poop":                  "💩",
+	"clown":                 "🤡",
+	"ghost":                 "👻",
+	"alien":                 "👽",
+	"robot":                 "🤖",
+	"partying_face":         "🥳",
+	"exploding_head":        "🤯",
+	"face_palm":             "🤦",
+	"facepalm":              "🤦",
+	"shrug":                 "🤷",
+	"melting_face":          "🫠",
+	"face_with_spiral_eyes": "😵‍💫",
+
+	// Hearts and symbols
+	"heart":                       "❤️",
+	"orange_heart":                "🧡",
+	"yellow_heart":                "💛",
+	"green_heart":                 "💚",
+	"blue_heart":                  "💙",
+	"purple_heart":                "💜",
+	"black_heart":                 "🖤",
+	"white_heart":                 "🤍",
+	"broken_heart":                "💔",
+	"sparkling_heart":             "💖",
+	"100":                         "💯",
+	"white_check_mark":            "✅",
+	"heavy_check_mark":            "✔️",
+	"ballot_box_with_check":       "☑️",
+	"x":                           "❌",
+	"negative_squared_cross_mark": "❎",
+	"heavy_plus_sign":             "➕",
+	"heavy_minus_sign":            "➖",
+	"question":                    "❓",
+	"grey_question":               "❔",
+	"exclamation":                 "❗",
+	"bangbang":                    "‼️",
+	"warning":                     "⚠️",
+	"no_entry":                    "⛔",
+	"no_entry_sign":               "🚫",
+	"stop_sign":                   "🛑",
+	"red_circle":                  "🔴",
+	"green_circle":                "🟢",
+	"yellow_circle":               "🟡",
+	"blue_circle":                 "🔵",
+	"arrow_up":                    "⬆️",
+	"arrow_down":                  "⬇️",
+	"arrow_left":                  "⬅️",
+	"arrow_right":                 "➡️",
+	"repeat":                      "🔁",
+	"recycle":                     "♻️",
+	"pushpin":                     "📌",
+	"link":                        "🔗",
+	"lock":                        "🔒",
+	"unlock":                      "🔓",
+	"key":                         "🔑",
+	"bell":                        "🔔",
+	"no_bell":                     "🔕",
+	"speech_balloon":              "💬",
+	"zzz":                         "💤",
+	"one":                         "1️⃣",
+	"two":                         "2️⃣",
+	"three":                       "3️⃣",
+	"four":                        "4️⃣",
+	"five":                        "5️⃣",
+
+	// Objects, nature and celebrations
+	"tada":                     "🎉",
+	"confetti_ball":            "🎊",
+	"balloon":                  "🎈",
+	"gift":                     "🎁",
+	"trophy":                   "🏆",
+	"medal":                    "🏅",
+	"first_place":              "🥇",
+	"fire":                     "🔥",
+	"sparkles":                 "✨",
+	"star":                     "⭐",
+	"star2":                    "🌟",
+	"zap":                      "⚡",
+	"boom":                     "💥",
+	"rainbow":                  "🌈",
+	"sunny":                    "☀️",
+	"cloud":                    "☁️",
+	"snowflake":                "❄️",
+	"rocket":                   "🚀",
+	"bulb":                     "💡",
+	"gear":                     "⚙️",
+	"wrench":                   "🔧",
+	"hammer":                   "🔨",
+	"tools":                    "🛠️",
+	"bug":                      "🐛",
+	"memo":                     "📝",
+	"pencil":                   "📝",
+	"pencil2":                  "✏️",
+	"calendar":                 "📆",
+	"hourglass":                "⌛",
+	"stopwatch":                "⏱️",
+	"alarm_clock":              "⏰",
+	"package":                  "📦",
+	"mag":                      "🔍",
+	"chart_with_upwards_trend": "📈",
+	"money_with_wings":         "💸",
+	"moneybag":                 "💰",
+	"coffee":                   "☕",
+	"beer":                     "🍺",
+	"beers":                    "🍻",
+	"pizza":                    "🍕",
+	"cake":                     "🍰",
+	"cookie":                   "🍪",
+	"popcorn":                  "🍿",
+	"apple":                    "🍎",
+	"seedling":                 "🌱",
+	"sunflower":                "🌻",
+	"rose":                     "🌹",
+	"dog":                      "🐶",
+	"cat":                      "🐱",
+	"fox":                      "🦊",
+	"unicorn":                  "🦄",
+	"bee":                      "🐝",
+	"turtle":                   "🐢",
+	"snake":                    "🐍",
+	"crab":                     "🦀",
+	"goat":                     "🐐",
+	"salute":                   "🫡",
+	"checkered_flag":           "🏁",
+	"triangular_flag_on_post":  "🚩",
+	"rotating_light":           "🚨",
+	"construction":             "🚧",
+	"ship":                     "🚢",
+	"globe_with_meridians":     "🌐",
+	"computer":                 "💻",
+	"keyboard":                 "⌨️",
+	"floppy_disk":              "💾",
+	"books":                    "📚",
+	"newspaper":                "📰",
+	"email":                    "📧",
+	"inbox_tray":               "📥",
+	"outbox_tray":              "📤",
+	"mega":                     "📣",
+	"loudspeaker":              "📢",
+	"musical_note":             "🎵",
+	"video_game":               "🎮",
+	"dart":                     "🎯",
+	"game_die":                 "🎲",
+	"crown":                    "👑",
+	"gem":                      "💎",
+}
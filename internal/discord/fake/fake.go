@@ -0,0 +1,480 @@
+// Package fake provides an in-process stand-in for the Discord REST API.
+//
+// A Server serves the subset of endpoints dca uses from in-memory state
+// seeded with a small, fixed set of guilds, channels, DMs and messages, so
+// commands can be exercised end to end without a real token.
+package fake
+
+import (
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/snowflake"
+)
+
+// Token is the user token the fake server accepts
+const Token = "fake-user-token"
+
+// Seeded fixture IDs
+const (
+	UserMe    = "100"
+	UserAlice = "101"
+	UserBob   = "102"
+
+	GuildID        = "200"
+	RoleModerators = "500"
+
+	ChannelGeneral  = "300"
+	ChannelRandom   = "301"
+	ChannelForum    = "302"
+	ThreadActive    = "303"
+	ThreadArchived  = "304"
+	ChannelDMAlice  = "400"
+	ChannelCategory = "305"
+
+	TagQuestion = "600"
+	TagBug      = "601"
+	TagSolved   = "602"
+
+	EmojiPartyParrot = "700"
+)
+
+// Relationship types
+const (
+	RelationshipFriend          = 1
+	RelationshipBlocked         = 2
+	RelationshipPendingIncoming = 3
+	RelationshipPendingOutgoing = 4
+)
+
+// SeedTime is the timestamp of the oldest seeded message. Later seeded
+// messages follow at one minute intervals.
+var SeedTime = time.Date(2026, 2, 24, 10, 0, 0, 0, time.UTC)
+
+// relationship is an entry of the current user's relationships list
+type relationship struct {
+	Type     int
+	Nickname string
+	Since    time.Time
+}
+
+// Server is a fake Discord REST API backed by httptest.Server
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	me       *discordgo.User
+	users    map[string]*discordgo.User
+	guilds   []*discordgo.Guild
+	channels []*discordgo.Channel
+	messages map[string][]*discordgo.Message
+	files    map[string][]byte                            // attachment ID -> content
+	nonces   map[string]*discordgo.Message                // channel/nonce -> message
+	mentions map[string]*discordgo.MessageAllowedMentions // message ID -> allowed_mentions
+	joined   map[string]bool                              // thread ID -> current user is a member
+	reactors map[string][]string                          // message ID/emoji -> reacting user IDs
+	pins     map[string][]string                          // channel ID -> pinned message IDs, oldest pin first
+	friends  map[string]*relationship                     // user ID -> relationship
+	hidden   map[string]bool                              // channel ID -> the current user cannot view it
+	requests map[string]int                               // "METHOD /path" -> requests served
+	lastID   snowflake.ID
+
+	// botOnlyActiveThreads makes the guild active-threads endpoint refuse
+	// user tokens, as Discord may
+	botOnlyActiveThreads bool
+
+	// failMessage counts down the message posts until one fails; 0 for
+	// none
+	failMessage int
+}
+
+// New starts a fake server seeded with the default fixture. The server is
+// closed when the test finishes.
+func New(t testing.TB) *Server {
+	s := &Server{
+		users:    make(map[string]*discordgo.User),
+		messages: make(map[string][]*discordgo.Message),
+		files:    make(map[string][]byte),
+		nonces:   make(map[string]*discordgo.Message),
+		mentions: make(map[string]*discordgo.MessageAllowedMentions),
+		joined:   make(map[string]bool),
+		reactors: make(map[string][]string),
+		pins:     make(map[string][]string),
+		friends:  make(map[string]*relationship),
+		hidden:   make(map[string]bool),
+		requests: make(map[string]int),
+	}
+	s.seed()
+	s.Server = httptest.NewServer(s.routes())
+	t.Cleanup(s.Close)
+	return s
+}
+
+// APIURL returns the base URL to use in place of https://discord.com/api/v9/
+func (s *Server) APIURL() string {
+	return s.URL + apiPrefix + "/"
+}
+
+func (s *Server) seed() {
+	s.me = s.AddUser(&discordgo.User{ID: UserMe, Username: "me"})
+	alice := s.AddUser(&discordgo.User{ID: UserAlice, Username: "alice"})
+	bob := s.AddUser(&discordgo.User{ID: UserBob, Username: "bob", GlobalName: "Bobby"})
+
+	s.AddGuild(&discordgo.Guild{
+		ID:                     GuildID,
+		Name:                   "Test Guild",
+		Description:            "A guild for tests",
+		OwnerID:                UserAlice,
+		ApproximateMemberCount: 3,
+		Roles: []*discordgo.Role{
+			{ID: GuildID, Name: "@everyone"},
+			{ID: RoleModerators, Name: "moderators", Position: 1},
+		},
+		Emojis: []*discordgo.Emoji{
+			{ID: EmojiPartyParrot, Name: "partyparrot", Animated: true},
+		},
+		Members: []*discordgo.Member{
+			{GuildID: GuildID, User: s.me},
+			{GuildID: GuildID, User: alice, Nick: "Alice (ops)", Roles: []string{RoleModerators}},
+			{GuildID: GuildID, User: bob},
+		},
+	})
+
+	s.AddRelationship(UserAlice, RelationshipFriend)
+
+	s.AddChannel(&discordgo.Channel{ID: ChannelCategory, GuildID: GuildID, Name: "Text Channels", Type: discordgo.ChannelTypeGuildCategory})
+	s.AddChannel(&discordgo.Channel{ID: ChannelGeneral, GuildID: GuildID, Name: "general", Type: discordgo.ChannelTypeGuildText, Topic: "General chat", ParentID: ChannelCategory})
+	s.AddChannel(&discordgo.Channel{ID: ChannelRandom, GuildID: GuildID, Name: "random", Type: discordgo.ChannelTypeGuildText, ParentID: ChannelCategory})
+	s.AddChannel(&discordgo.Channel{
+		ID: ChannelForum, GuildID: GuildID, Name: "help", Type: discordgo.ChannelTypeGuildForum,
+		AvailableTags: []discordgo.ForumTag{
+			{ID: TagQuestion, Name: "question", EmojiName: "❓"},
+			{ID: TagBug, Name: "bug"},
+			{ID: TagSolved, Name: "solved", Moderated: true},
+		},
+	})
+	s.AddChannel(&discordgo.Channel{
+		ID: ThreadActive, GuildID: GuildID, ParentID: ChannelForum, OwnerID: UserAlice,
+		Name: "How do I deploy?", Type: discordgo.ChannelTypeGuildPublicThread,
+		MessageCount: 2, ThreadMetadata: &discordgo.ThreadMetadata{}, AppliedTags: []string{TagQuestion},
+	})
+	s.AddChannel(&discordgo.Channel{
+		ID: ThreadArchived, GuildID: GuildID, ParentID: ChannelForum, OwnerID: UserBob,
+		Name: "Build is broken", Type: discordgo.ChannelTypeGuildPublicThread,
+		MessageCount: 1, ThreadMetadata: &discordgo.ThreadMetadata{Archived: true, ArchiveTimestamp: SeedTime.Add(30 * time.Minute)}, AppliedTags: []string{TagBug},
+	})
+	s.AddChannel(&discordgo.Channel{ID: ChannelDMAlice, Type: discordgo.ChannelTypeDM, Recipients: []*discordgo.User{alice}})
+
+	seeded := []struct {
+		channel string
+		author  *discordgo.User
+		content string
+	}{
+		{ChannelGeneral, alice, "good morning everyone"},
+		{ChannelGeneral, bob, "the deploy is done"},
+		{ChannelRandom, bob, "anyone seen the headless build flag?"},
+		{ThreadActive, alice, "How do I deploy to staging?"},
+		{ThreadArchived, bob, "Build fails on main"},
+		{ChannelDMAlice, alice, "hey, are you around?"},
+		{ThreadActive, bob, "Run make deploy-staging"},
+		{ChannelGeneral, s.me, "thanks bob"},
+		{ChannelDMAlice, s.me, "yes, what's up?"},
+	}
+	for i, m := range seeded {
+		s.AddMessage(&discordgo.Message{
+			ChannelID: m.channel,
+			Author:    m.author,
+			Content:   m.content,
+			Timestamp: SeedTime.Add(time.Duration(i) * time.Minute),
+		})
+	}
+}
+
+// Me returns the user the token belongs to
+func (s *Server) Me() *discordgo.User {
+	return s.me
+}
+
+// AddUser registers a user
+func (s *Server) AddUser(u *discordgo.User) *discordgo.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[u.ID] = u
+	return u
+}
+
+// AddGuild registers a guild the current user is a member of
+func (s *Server) AddGuild(g *discordgo.Guild) *discordgo.Guild {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.guilds = append(s.guilds, g)
+	return g
+}
+
+// AddMember adds a member to a guild
+func (s *Server) AddMember(guildID string, m *discordgo.Member) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if g := s.guildLocked(guildID); g != nil {
+		m.GuildID = guildID
+		g.Members = append(g.Members, m)
+	}
+}
+
+// AddRelationship puts a user on the current user's friends list with one
+// of the Relationship types
+func (s *Server) AddRelationship(userID string, typ int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.friends[userID] = &relationship{Type: typ, Since: s.clock()}
+}
+
+// SetFriendNickname gives a user on the relationships list a private
+// nickname
+func (s *Server) SetFriendNickname(userID, nickname string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rel := s.friends[userID]; rel != nil {
+		rel.Nickname = nickname
+	}
+}
+
+// Relationship returns the current user's relationship type with a user,
+// 0 if there is none
+func (s *Server) Relationship(userID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rel := s.friends[userID]; rel != nil {
+		return rel.Type
+	}
+	return 0
+}
+
+// AddChannel registers a guild channel, thread or DM channel
+func (s *Server) AddChannel(ch *discordgo.Channel) *discordgo.Channel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.channels = append(s.channels, ch)
+	return ch
+}
+
+// AddMessage stores a message in its channel. A missing ID is derived from
+// the timestamp, and a missing timestamp defaults to now.
+func (s *Server) AddMessage(m *discordgo.Message) *discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addMessageLocked(m)
+}
+
+func (s *Server) addMessageLocked(m *discordgo.Message) *discordgo.Message {
+	if m.Timestamp.IsZero() {
+		m.Timestamp = time.Now().UTC()
+	}
+	if m.ID == "" {
+		m.ID = s.nextIDLocked(m.Timestamp)
+	}
+	if ch := s.channelLocked(m.ChannelID); ch != nil {
+		m.GuildID = ch.GuildID
+		ch.LastMessageID = m.ID
+	}
+
+	msgs := append(s.messages[m.ChannelID], m)
+	sort.Slice(msgs, func(i, j int) bool { return snowflake.Less(msgs[i].ID, msgs[j].ID) })
+	s.messages[m.ChannelID] = msgs
+	return m
+}
+
+// nextIDLocked returns a snowflake for t that is unique within the server
+func (s *Server) nextIDLocked(t time.Time) string {
+	id := snowflake.FromTime(t)
+	if id <= s.lastID {
+		id = s.lastID + 1
+	}
+	s.lastID = id
+	return id.String()
+}
+
+// FileData returns the uploaded content of an attachment
+func (s *Server) FileData(attachmentID string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.files[attachmentID]
+	return data, ok
+}
+
+// AllowedMentions returns the allowed_mentions a message was created with,
+// nil if the request had none
+func (s *Server) AllowedMentions(messageID string) *discordgo.MessageAllowedMentions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.mentions[messageID]
+}
+
+// React adds a user's reaction to a message. emoji is the unicode
+// character or name:id for custom emoji.
+func (s *Server) React(channelID, messageID, userID, emoji string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m := s.messageLocked(channelID, messageID); m != nil {
+		s.reactLocked(m, userID, emoji)
+	}
+}
+
+// Pin pins a message, as if someone else had pinned it
+func (s *Server) Pin(channelID, messageID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m := s.messageLocked(channelID, messageID); m != nil && !m.Pinned {
+		m.Pinned = true
+		s.pins[channelID] = append(s.pins[channelID], messageID)
+	}
+}
+
+// Reactors returns the IDs of the users who reacted to a message with an
+// emoji, in the order they reacted
+func (s *Server) Reactors(messageID, emoji string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.reactors[messageID+"/"+emoji]...)
+}
+
+// BotOnlyActiveThreads makes the guild active-threads endpoint answer 403,
+// so callers have to fall back to thread search
+func (s *Server) BotOnlyActiveThreads() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.botOnlyActiveThreads = true
+}
+
+// Requests returns how often a request, e.g. "GET /channels/300", was made
+func (s *Server) Requests(request string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[request]
+}
+
+func (s *Server) countRequest(request string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[request]++
+}
+
+// HideChannel makes thread search in a channel answer 403, as it does for
+// channels the current user cannot view
+func (s *Server) HideChannel(channelID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hidden[channelID] = true
+}
+
+// FailMessage makes the nth message posted from now on fail with a server
+// error, as a send that breaks off partway would
+func (s *Server) FailMessage(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failMessage = n
+}
+
+// ForgetNonces drops the nonces of earlier posts, as Discord does once its
+// nonce window has passed
+func (s *Server) ForgetNonces() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nonces = make(map[string]*discordgo.Message)
+}
+
+// Joined reports whether the current user is a member of a thread
+func (s *Server) Joined(threadID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.joined[threadID]
+}
+
+// Channel returns a stored channel or thread, or nil if it does not exist
+func (s *Server) Channel(channelID string) *discordgo.Channel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.channelLocked(channelID)
+}
+
+// Messages returns the messages stored in a channel, oldest first
+func (s *Server) Messages(channelID string) []*discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*discordgo.Message(nil), s.messages[channelID]...)
+}
+
+// LastMessage returns the newest message in a channel, or nil
+func (s *Server) LastMessage(channelID string) *discordgo.Message {
+	msgs := s.Messages(channelID)
+	if len(msgs) == 0 {
+		return nil
+	}
+	return msgs[len(msgs)-1]
+}
+
+// Message returns a stored message, or nil if it does not exist
+func (s *Server) Message(channelID, messageID string) *discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.messageLocked(channelID, messageID)
+}
+
+func (s *Server) messageLocked(channelID, messageID string) *discordgo.Message {
+	for _, m := range s.messages[channelID] {
+		if m.ID == messageID {
+			return m
+		}
+	}
+	return nil
+}
+
+func (s *Server) channelLocked(channelID string) *discordgo.Channel {
+	for _, ch := range s.channels {
+		if ch.ID == channelID {
+			return ch
+		}
+	}
+	return nil
+}
+
+func (s *Server) guildLocked(guildID string) *discordgo.Guild {
+	for _, g := range s.guilds {
+		if g.ID == guildID {
+			return g
+		}
+	}
+	return nil
+}
+
+// clock returns the timestamp given to messages created through the API
+func (s *Server) clock() time.Time {
+	return time.Now().UTC()
+}
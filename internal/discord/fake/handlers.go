@@ -0,0 +1,1452 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/snowflake"
+)
+
+// apiPrefix is the path prefix of discordgo.EndpointAPI
+var apiPrefix = "/api/v" + discordgo.APIVersion
+
+// searchPageSize is the number of hits Discord returns per search page
+const searchPageSize = 25
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, h http.HandlerFunc) {
+		method, p, _ := strings.Cut(pattern, " ")
+		mux.HandleFunc(method+" "+apiPrefix+p, h)
+	}
+
+	handle("GET /users/@me", s.handleGetMe)
+	handle("GET /users/@me/guilds", s.handleListGuilds)
+	handle("GET /users/@me/channels", s.handleListDMChannels)
+	handle("POST /users/@me/channels", s.handleCreateDMChannel)
+	handle("GET /users/@me/relationships", s.handleListRelationships)
+	handle("POST /users/@me/relationships", s.handleSendFriendRequest)
+	handle("PUT /users/@me/relationships/{user}", s.handlePutRelationship)
+	handle("DELETE /users/@me/relationships/{user}", s.handleDeleteRelationship)
+	handle("GET /users/{user}", s.handleGetUser)
+
+	handle("GET /guilds/{guild}", s.handleGetGuild)
+	handle("GET /guilds/{guild}/channels", s.handleListGuildChannels)
+	handle("GET /guilds/{guild}/roles", s.handleListRoles)
+	handle("GET /guilds/{guild}/emojis", s.handleGuildEmojis)
+	handle("GET /guilds/{guild}/members/search", s.handleSearchMembers)
+	handle("GET /guilds/{guild}/members/{user}", s.handleGetMember)
+	handle("GET /guilds/{guild}/messages/search", s.handleSearch)
+	handle("GET /guilds/{guild}/threads/active", s.handleActiveThreads)
+
+	handle("GET /channels/{channel}", s.handleGetChannel)
+	handle("PATCH /channels/{channel}", s.handleEditChannel)
+	handle("GET /channels/{channel}/messages", s.handleListMessages)
+	handle("POST /channels/{channel}/messages", s.handleCreateMessage)
+	handle("GET /channels/{channel}/messages/{message}", s.handleGetMessage)
+	handle("PATCH /channels/{channel}/messages/{message}", s.handleEditMessage)
+	handle("DELETE /channels/{channel}/messages/{message}", s.handleDeleteMessage)
+	handle("PUT /channels/{channel}/messages/{message}/reactions/{emoji}/@me", s.handleAddReaction)
+	handle("DELETE /channels/{channel}/messages/{message}/reactions/{emoji}/@me", s.handleRemoveReaction)
+	handle("GET /channels/{channel}/messages/{message}/reactions/{emoji}", s.handleReactionUsers)
+	handle("GET /channels/{channel}/pins", s.handleListPins)
+	handle("PUT /channels/{channel}/pins/{message}", s.handlePin)
+	handle("DELETE /channels/{channel}/pins/{message}", s.handleUnpin)
+	handle("GET /channels/{channel}/threads/archived/public", s.handleArchivedThreads)
+	handle("GET /channels/{channel}/threads/search", s.handleSearchThreads)
+	handle("POST /channels/{channel}/threads", s.handleStartThread)
+	handle("POST /channels/{channel}/messages/{message}/threads", s.handleStartThread)
+	handle("PUT /channels/{channel}/thread-members/@me", s.handleJoinThread)
+	handle("PUT /channels/{channel}/recipients/{user}", s.handleAddRecipient)
+	handle("DELETE /channels/{channel}/recipients/{user}", s.handleRemoveRecipient)
+	handle("DELETE /channels/{channel}/thread-members/@me", s.handleLeaveThread)
+
+	// Attachments are served like Discord's CDN, without authentication
+	mux.HandleFunc("GET /attachments/{channel}/{attachment}/{name}", s.handleGetAttachment)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/attachments/") && r.Header.Get("Authorization") != Token {
+			writeError(w, http.StatusUnauthorized, 0, "401: Unauthorized")
+			return
+		}
+		// Some callers build endpoints with a doubled slash; Discord
+		// tolerates that, so clean the path instead of redirecting.
+		if strings.Contains(r.URL.Path, "//") {
+			r.URL.Path = path.Clean(r.URL.Path)
+			r.URL.RawPath = ""
+		}
+		s.countRequest(r.Method + " " + strings.TrimPrefix(r.URL.Path, apiPrefix))
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"code":    code,
+		"message": message,
+	})
+}
+
+func notFound(w http.ResponseWriter, what string) {
+	codes := map[string]int{"Guild": 10004, "Channel": 10003, "Message": 10008, "User": 10013, "Member": 10007}
+	writeError(w, http.StatusNotFound, codes[what], "Unknown "+what)
+}
+
+func queryInt(r *http.Request, key string, def int) int {
+	v, err := strconv.Atoi(r.URL.Query().Get(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.me)
+}
+
+func (s *Server) handleListGuilds(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	guilds := make([]*discordgo.UserGuild, 0, len(s.guilds))
+	for _, g := range s.guilds {
+		guilds = append(guilds, &discordgo.UserGuild{
+			ID:    g.ID,
+			Name:  g.Name,
+			Owner: g.OwnerID == s.me.ID,
+		})
+	}
+	writeJSON(w, http.StatusOK, guilds)
+}
+
+func (s *Server) handleGetGuild(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.guildLocked(r.PathValue("guild"))
+	if g == nil {
+		notFound(w, "Guild")
+		return
+	}
+	writeJSON(w, http.StatusOK, g)
+}
+
+func (s *Server) handleListRoles(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.guildLocked(r.PathValue("guild"))
+	if g == nil {
+		notFound(w, "Guild")
+		return
+	}
+	roles := g.Roles
+	if roles == nil {
+		roles = []*discordgo.Role{}
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+func (s *Server) handleGetMember(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.guildLocked(r.PathValue("guild"))
+	if g == nil {
+		notFound(w, "Guild")
+		return
+	}
+	for _, m := range g.Members {
+		if m.User != nil && m.User.ID == r.PathValue("user") {
+			writeJSON(w, http.StatusOK, m)
+			return
+		}
+	}
+	notFound(w, "Member")
+}
+
+func (s *Server) handleSearchMembers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.guildLocked(r.PathValue("guild"))
+	if g == nil {
+		notFound(w, "Guild")
+		return
+	}
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+		return
+	}
+	limit := queryInt(r, "limit", 1)
+
+	// Discord matches the start of usernames, display names and nicknames
+	members := make([]*discordgo.Member, 0)
+	for _, m := range g.Members {
+		if m.User == nil || len(members) == limit {
+			continue
+		}
+		for _, name := range []string{m.User.Username, m.User.GlobalName, m.Nick} {
+			if name != "" && strings.HasPrefix(strings.ToLower(name), query) {
+				members = append(members, m)
+				break
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, members)
+}
+
+func (s *Server) handleGetAttachment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data, ok := s.files[r.PathValue("attachment")]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	_, _ = w.Write(data)
+}
+
+func (s *Server) handleGetChannel(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := s.channelLocked(r.PathValue("channel"))
+	if ch == nil {
+		notFound(w, "Channel")
+		return
+	}
+	writeJSON(w, http.StatusOK, ch)
+}
+
+func (s *Server) handleListRelationships(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.friends))
+	for id := range s.friends {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	rels := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		rel := s.friends[id]
+		entry := map[string]interface{}{
+			"id":    id,
+			"type":  rel.Type,
+			"user":  s.users[id],
+			"since": rel.Since.Format(time.RFC3339),
+		}
+		if rel.Nickname != "" {
+			entry["nickname"] = rel.Nickname
+		}
+		rels = append(rels, entry)
+	}
+	writeJSON(w, http.StatusOK, rels)
+}
+
+func (s *Server) handleSendFriendRequest(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil || data.Username == "" {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if strings.EqualFold(u.Username, data.Username) {
+			s.befriendLocked(w, u.ID)
+			return
+		}
+	}
+	writeError(w, http.StatusBadRequest, 80004, "No users with DiscordTag exist")
+}
+
+func (s *Server) handlePutRelationship(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Type int `json:"type"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userID := r.PathValue("user")
+	if _, ok := s.users[userID]; !ok {
+		notFound(w, "User")
+		return
+	}
+	if data.Type == RelationshipBlocked {
+		s.friends[userID] = &relationship{Type: RelationshipBlocked, Since: s.clock()}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	s.befriendLocked(w, userID)
+}
+
+// befriendLocked sends a friend request, or accepts the user's own
+func (s *Server) befriendLocked(w http.ResponseWriter, userID string) {
+	if userID == s.me.ID {
+		writeError(w, http.StatusBadRequest, 80003, "Cannot send friend request to self")
+		return
+	}
+	rel := s.friends[userID]
+	switch {
+	case rel == nil || rel.Type == RelationshipBlocked:
+		s.friends[userID] = &relationship{Type: RelationshipPendingOutgoing, Since: s.clock()}
+	case rel.Type == RelationshipPendingIncoming:
+		rel.Type = RelationshipFriend
+		rel.Since = s.clock()
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleDeleteRelationship(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.friends, r.PathValue("user"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[r.PathValue("user")]
+	if !ok {
+		notFound(w, "User")
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) handleListGuildChannels(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	guildID := r.PathValue("guild")
+	if s.guildLocked(guildID) == nil {
+		notFound(w, "Guild")
+		return
+	}
+
+	channels := make([]*discordgo.Channel, 0)
+	for _, ch := range s.channels {
+		if ch.GuildID == guildID && !ch.IsThread() {
+			channels = append(channels, ch)
+		}
+	}
+	writeJSON(w, http.StatusOK, channels)
+}
+
+func (s *Server) handleListDMChannels(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channels := make([]*discordgo.Channel, 0)
+	for _, ch := range s.channels {
+		if ch.Type == discordgo.ChannelTypeDM || ch.Type == discordgo.ChannelTypeGroupDM {
+			channels = append(channels, ch)
+		}
+	}
+	writeJSON(w, http.StatusOK, channels)
+}
+
+// handleCreateDMChannel opens the DM with recipient_id, or starts a new
+// group DM when recipients is given instead
+func (s *Server) handleCreateDMChannel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RecipientID string   `json:"recipient_id"`
+		Recipients  []string `json:"recipients"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Recipients != nil {
+		ch := &discordgo.Channel{
+			ID:         s.nextIDLocked(s.clock()),
+			Type:       discordgo.ChannelTypeGroupDM,
+			OwnerID:    s.me.ID,
+			Recipients: make([]*discordgo.User, 0, len(req.Recipients)),
+		}
+		for _, id := range req.Recipients {
+			user, ok := s.users[id]
+			if !ok {
+				notFound(w, "User")
+				return
+			}
+			ch.Recipients = append(ch.Recipients, user)
+		}
+		s.channels = append(s.channels, ch)
+		writeJSON(w, http.StatusOK, ch)
+		return
+	}
+
+	user, ok := s.users[req.RecipientID]
+	if !ok {
+		notFound(w, "User")
+		return
+	}
+
+	for _, ch := range s.channels {
+		if ch.Type == discordgo.ChannelTypeDM && len(ch.Recipients) == 1 && ch.Recipients[0].ID == user.ID {
+			writeJSON(w, http.StatusOK, ch)
+			return
+		}
+	}
+
+	ch := &discordgo.Channel{
+		ID:         s.nextIDLocked(s.clock()),
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{user},
+	}
+	s.channels = append(s.channels, ch)
+	writeJSON(w, http.StatusOK, ch)
+}
+
+// handleListMessages mirrors Discord's paging rules: results are newest
+// first, limit defaults to 50 and is capped at 100, and before, after and
+// around select the window relative to a message ID.
+func (s *Server) handleListMessages(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channelID := r.PathValue("channel")
+	if s.channelLocked(channelID) == nil {
+		notFound(w, "Channel")
+		return
+	}
+
+	limit := queryInt(r, "limit", 50)
+	if limit < 1 || limit > 100 {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+		return
+	}
+
+	q := r.URL.Query()
+	msgs := s.messages[channelID]
+	var window []*discordgo.Message
+	switch {
+	case q.Get("around") != "":
+		around := q.Get("around")
+		idx := sort.Search(len(msgs), func(i int) bool { return !snowflake.Less(msgs[i].ID, around) })
+		start := idx - limit/2
+		if start < 0 {
+			start = 0
+		}
+		end := start + limit
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+		window = msgs[start:end]
+	case q.Get("after") != "":
+		after := q.Get("after")
+		idx := sort.Search(len(msgs), func(i int) bool { return snowflake.Less(after, msgs[i].ID) })
+		end := idx + limit
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+		window = msgs[idx:end]
+	default:
+		end := len(msgs)
+		if before := q.Get("before"); before != "" {
+			end = sort.Search(len(msgs), func(i int) bool { return !snowflake.Less(msgs[i].ID, before) })
+		}
+		start := end - limit
+		if start < 0 {
+			start = 0
+		}
+		window = msgs[start:end]
+	}
+
+	result := make([]*discordgo.Message, 0, len(window))
+	for i := len(window) - 1; i >= 0; i-- {
+		result = append(result, window[i])
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleGetMessage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.messageLocked(r.PathValue("channel"), r.PathValue("message"))
+	if m == nil {
+		notFound(w, "Message")
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// messageCreate is a create message request. discordgo's MessageSend has
+// no nonce fields, so they are decoded alongside it.
+type messageCreate struct {
+	discordgo.MessageSend
+	Nonce        string `json:"nonce"`
+	EnforceNonce bool   `json:"enforce_nonce"`
+}
+
+// upload is a file sent with a message
+type upload struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+// decodeMessageSend reads a create message request
+func decodeMessageSend(r *http.Request) (*messageCreate, []*upload, error) {
+	var data messageCreate
+	uploads, err := decodeWithFiles(r, &data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &data, uploads, nil
+}
+
+// decodeWithFiles reads a request body into v. discordgo sends JSON, or
+// multipart/form-data with a payload_json part when files are attached.
+func decodeWithFiles(r *http.Request, v interface{}) ([]*upload, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return nil, json.NewDecoder(r.Body).Decode(v)
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(r.FormValue("payload_json")), v); err != nil {
+		return nil, err
+	}
+	var uploads []*upload
+	for i := 0; ; i++ {
+		headers := r.MultipartForm.File[fmt.Sprintf("files[%d]", i)]
+		if len(headers) == 0 {
+			break
+		}
+		f, err := headers[0].Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, &upload{
+			name:        headers[0].Filename,
+			contentType: headers[0].Header.Get("Content-Type"),
+			data:        content,
+		})
+	}
+	return uploads, nil
+}
+
+// validMessage reports whether a new message has something to show and
+// fits Discord's limits
+func validMessage(data *messageCreate, uploads []*upload) (status, code int, message string, ok bool) {
+	if data.Content == "" && len(uploads) == 0 && len(data.Embeds) == 0 {
+		return http.StatusBadRequest, 50006, "Cannot send an empty message", false
+	}
+	if len([]rune(data.Content)) > 2000 || len(data.Embeds) > 10 {
+		return http.StatusBadRequest, 50035, "Invalid Form Body", false
+	}
+	return 0, 0, "", true
+}
+
+func (s *Server) handleCreateMessage(w http.ResponseWriter, r *http.Request) {
+	data, uploads, err := decodeMessageSend(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channelID := r.PathValue("channel")
+	ch := s.channelLocked(channelID)
+	if ch == nil {
+		notFound(w, "Channel")
+		return
+	}
+	// Posting in an archived thread reopens it, unless it is locked
+	if md := ch.ThreadMetadata; md != nil && md.Archived {
+		if md.Locked {
+			writeError(w, http.StatusBadRequest, 50083, "Thread is archived")
+			return
+		}
+		md.Archived = false
+	}
+	// Like Discord, an enforced nonce that was seen before returns the
+	// message it created instead of posting again
+	nonceKey := channelID + "/" + data.Nonce
+	if data.EnforceNonce && data.Nonce != "" {
+		if len(data.Nonce) > 25 {
+			writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+			return
+		}
+		if m, ok := s.nonces[nonceKey]; ok {
+			writeJSON(w, http.StatusOK, m)
+			return
+		}
+	}
+	if status, code, message, ok := validMessage(data, uploads); !ok {
+		writeError(w, status, code, message)
+		return
+	}
+	if s.failMessage > 0 {
+		s.failMessage--
+		if s.failMessage == 0 {
+			writeError(w, http.StatusInternalServerError, 0, "Internal Server Error")
+			return
+		}
+	}
+
+	m := s.newMessageLocked(ch, data)
+	if data.Reference != nil {
+		ref := s.messageLocked(data.Reference.ChannelID, data.Reference.MessageID)
+		if ref == nil {
+			writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+			return
+		}
+		m.Type = discordgo.MessageTypeReply
+		m.MessageReference = data.Reference
+		m.ReferencedMessage = ref
+	}
+	m = s.postMessageLocked(ch, m, data, uploads)
+	if data.Nonce != "" {
+		s.nonces[nonceKey] = m
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// newMessageLocked builds a message by the current user from a request
+func (s *Server) newMessageLocked(ch *discordgo.Channel, data *messageCreate) *discordgo.Message {
+	return &discordgo.Message{
+		ChannelID: ch.ID,
+		Author:    s.me,
+		Content:   data.Content,
+		Embeds:    data.Embeds,
+		Flags:     data.Flags & (discordgo.MessageFlagsSuppressEmbeds | discordgo.MessageFlagsSuppressNotifications),
+		Timestamp: s.clock(),
+		Type:      discordgo.MessageTypeDefault,
+	}
+}
+
+// postMessageLocked stores the uploads and the message built from a
+// request. Posting in a thread counts towards it and joins it.
+func (s *Server) postMessageLocked(ch *discordgo.Channel, m *discordgo.Message, data *messageCreate, uploads []*upload) *discordgo.Message {
+	for _, u := range uploads {
+		id := s.nextIDLocked(m.Timestamp)
+		s.files[id] = u.data
+		m.Attachments = append(m.Attachments, &discordgo.MessageAttachment{
+			ID:          id,
+			Filename:    u.name,
+			ContentType: u.contentType,
+			Size:        len(u.data),
+			URL:         fmt.Sprintf("%s/attachments/%s/%s/%s", s.URL, ch.ID, id, url.PathEscape(u.name)),
+		})
+	}
+	m = s.addMessageLocked(m)
+	s.mentions[m.ID] = data.AllowedMentions
+	if ch.IsThread() {
+		ch.MessageCount++
+		s.joined[ch.ID] = true
+	}
+	return m
+}
+
+func (s *Server) handleEditMessage(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Content *string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.messageLocked(r.PathValue("channel"), r.PathValue("message"))
+	if m == nil {
+		notFound(w, "Message")
+		return
+	}
+	if m.Author == nil || m.Author.ID != s.me.ID {
+		writeError(w, http.StatusForbidden, 50005, "Cannot edit a message authored by another user")
+		return
+	}
+	if data.Content != nil {
+		m.Content = *data.Content
+	}
+	edited := s.clock()
+	m.EditedTimestamp = &edited
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) handleDeleteMessage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channelID := r.PathValue("channel")
+	messageID := r.PathValue("message")
+	msgs := s.messages[channelID]
+	for i, m := range msgs {
+		if m.ID == messageID {
+			s.messages[channelID] = append(msgs[:i:i], msgs[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	notFound(w, "Message")
+}
+
+func (s *Server) handleAddReaction(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.messageLocked(r.PathValue("channel"), r.PathValue("message"))
+	if m == nil {
+		notFound(w, "Message")
+		return
+	}
+	emoji := r.PathValue("emoji")
+	if _, id, ok := strings.Cut(emoji, ":"); ok && s.emojiLocked(id) == nil {
+		writeError(w, http.StatusBadRequest, 10014, "Unknown Emoji")
+		return
+	}
+
+	s.reactLocked(m, s.me.ID, emoji)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reactLocked records a user's reaction, once per user and emoji
+func (s *Server) reactLocked(m *discordgo.Message, userID, emoji string) {
+	key := m.ID + "/" + emoji
+	for _, id := range s.reactors[key] {
+		if id == userID {
+			return
+		}
+	}
+	s.reactors[key] = append(s.reactors[key], userID)
+
+	me := userID == s.me.ID
+	for _, reaction := range m.Reactions {
+		if reactionKey(reaction.Emoji) == emoji {
+			reaction.Count++
+			reaction.Me = reaction.Me || me
+			return
+		}
+	}
+	m.Reactions = append(m.Reactions, &discordgo.MessageReactions{
+		Count: 1,
+		Me:    me,
+		Emoji: parseReactionKey(emoji),
+	})
+}
+
+func (s *Server) handleRemoveReaction(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.messageLocked(r.PathValue("channel"), r.PathValue("message"))
+	if m == nil {
+		notFound(w, "Message")
+		return
+	}
+
+	emoji := r.PathValue("emoji")
+	key := m.ID + "/" + emoji
+	for i, id := range s.reactors[key] {
+		if id == s.me.ID {
+			s.reactors[key] = append(s.reactors[key][:i:i], s.reactors[key][i+1:]...)
+			break
+		}
+	}
+	for i, reaction := range m.Reactions {
+		if reactionKey(reaction.Emoji) == emoji && reaction.Me {
+			reaction.Me = false
+			reaction.Count--
+			if reaction.Count == 0 {
+				m.Reactions = append(m.Reactions[:i:i], m.Reactions[i+1:]...)
+			}
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleReactionUsers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.messageLocked(r.PathValue("channel"), r.PathValue("message"))
+	if m == nil {
+		notFound(w, "Message")
+		return
+	}
+
+	// Users are listed by ID, as Discord does
+	ids := append([]string(nil), s.reactors[m.ID+"/"+r.PathValue("emoji")]...)
+	sort.Slice(ids, func(i, j int) bool { return snowflake.Less(ids[i], ids[j]) })
+
+	after := r.URL.Query().Get("after")
+	limit := queryInt(r, "limit", 25)
+	if limit > 100 {
+		limit = 100
+	}
+	users := make([]*discordgo.User, 0)
+	for _, id := range ids {
+		if after != "" && !snowflake.Less(after, id) {
+			continue
+		}
+		if len(users) == limit {
+			break
+		}
+		if u := s.users[id]; u != nil {
+			users = append(users, u)
+		}
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+// emojiLocked finds a custom emoji in any guild
+func (s *Server) emojiLocked(emojiID string) *discordgo.Emoji {
+	for _, g := range s.guilds {
+		for _, e := range g.Emojis {
+			if e.ID == emojiID {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleGuildEmojis(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.guildLocked(r.PathValue("guild"))
+	if g == nil {
+		notFound(w, "Guild")
+		return
+	}
+	emojis := g.Emojis
+	if emojis == nil {
+		emojis = []*discordgo.Emoji{}
+	}
+	writeJSON(w, http.StatusOK, emojis)
+}
+
+// reactionKey returns the path form of an emoji: the unicode character for
+// standard emoji and name:id for custom ones
+func reactionKey(e *discordgo.Emoji) string {
+	if e.ID != "" {
+		return e.Name + ":" + e.ID
+	}
+	return e.Name
+}
+
+func parseReactionKey(key string) *discordgo.Emoji {
+	if name, id, ok := strings.Cut(key, ":"); ok {
+		return &discordgo.Emoji{Name: name, ID: id}
+	}
+	return &discordgo.Emoji{Name: key}
+}
+
+// maxPins is the most messages a channel can have pinned
+const maxPins = 50
+
+func (s *Server) handleListPins(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channelID := r.PathValue("channel")
+	if s.channelLocked(channelID) == nil {
+		notFound(w, "Channel")
+		return
+	}
+
+	ids := s.pins[channelID]
+	pinned := make([]*discordgo.Message, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		if m := s.messageLocked(channelID, ids[i]); m != nil {
+			pinned = append(pinned, m)
+		}
+	}
+	writeJSON(w, http.StatusOK, pinned)
+}
+
+func (s *Server) handlePin(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channelID := r.PathValue("channel")
+	m := s.messageLocked(channelID, r.PathValue("message"))
+	if m == nil {
+		notFound(w, "Message")
+		return
+	}
+	if !m.Pinned {
+		if len(s.pins[channelID]) >= maxPins {
+			writeError(w, http.StatusBadRequest, 30003, "Maximum number of pins reached (50)")
+			return
+		}
+		m.Pinned = true
+		s.pins[channelID] = append(s.pins[channelID], m.ID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleUnpin(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channelID := r.PathValue("channel")
+	m := s.messageLocked(channelID, r.PathValue("message"))
+	if m == nil {
+		notFound(w, "Message")
+		return
+	}
+	m.Pinned = false
+	ids := s.pins[channelID]
+	for i, id := range ids {
+		if id == m.ID {
+			s.pins[channelID] = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleArchivedThreads(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channelID := r.PathValue("channel")
+	if s.channelLocked(channelID) == nil {
+		notFound(w, "Channel")
+		return
+	}
+
+	var before time.Time
+	if v := r.URL.Query().Get("before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+			return
+		}
+		before = t
+	}
+
+	threads := make([]*discordgo.Channel, 0)
+	for _, ch := range s.channels {
+		if ch.ParentID != channelID || !ch.IsThread() || ch.ThreadMetadata == nil || !ch.ThreadMetadata.Archived {
+			continue
+		}
+		if !before.IsZero() && !ch.ThreadMetadata.ArchiveTimestamp.Before(before) {
+			continue
+		}
+		threads = append(threads, ch)
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		return threads[i].ThreadMetadata.ArchiveTimestamp.After(threads[j].ThreadMetadata.ArchiveTimestamp)
+	})
+
+	limit := queryInt(r, "limit", 50)
+	if limit > 100 {
+		limit = 100
+	}
+	hasMore := len(threads) > limit
+	if hasMore {
+		threads = threads[:limit]
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"threads":  threads,
+		"members":  s.threadMembersLocked(threads),
+		"has_more": hasMore,
+	})
+}
+
+// threadMembersLocked lists the current user's membership of threads
+func (s *Server) threadMembersLocked(threads []*discordgo.Channel) []*discordgo.ThreadMember {
+	members := make([]*discordgo.ThreadMember, 0)
+	for _, ch := range threads {
+		if s.joined[ch.ID] {
+			members = append(members, &discordgo.ThreadMember{ID: ch.ID, UserID: s.me.ID})
+		}
+	}
+	return members
+}
+
+func (s *Server) handleActiveThreads(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	guildID := r.PathValue("guild")
+	if s.guildLocked(guildID) == nil {
+		notFound(w, "Guild")
+		return
+	}
+	if s.botOnlyActiveThreads {
+		writeError(w, http.StatusForbidden, 20002, "Only bots can use this endpoint")
+		return
+	}
+
+	threads := make([]*discordgo.Channel, 0)
+	for _, ch := range s.channels {
+		if ch.GuildID == guildID && ch.IsThread() && ch.ThreadMetadata != nil && !ch.ThreadMetadata.Archived {
+			threads = append(threads, ch)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"threads": threads,
+		"members": s.threadMembersLocked(threads),
+	})
+}
+
+// hasAnyTag reports whether applied contains any of want
+func hasAnyTag(applied, want []string) bool {
+	for _, id := range applied {
+		for _, w := range want {
+			if id == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Server) handleSearchThreads(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channelID := r.PathValue("channel")
+	if s.channelLocked(channelID) == nil {
+		notFound(w, "Channel")
+		return
+	}
+	if s.hidden[channelID] {
+		writeError(w, http.StatusForbidden, 50001, "Missing Access")
+		return
+	}
+
+	q := r.URL.Query()
+	var threads []*discordgo.Channel
+	for _, ch := range s.channels {
+		if ch.ParentID != channelID || !ch.IsThread() || ch.ThreadMetadata == nil {
+			continue
+		}
+		if archived := q.Get("archived"); archived != "" && strconv.FormatBool(ch.ThreadMetadata.Archived) != archived {
+			continue
+		}
+		if tags := q.Get("tag"); tags != "" && !hasAnyTag(ch.AppliedTags, strings.Split(tags, ",")) {
+			continue
+		}
+		threads = append(threads, ch)
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		return snowflake.Less(lastActivity(threads[j]), lastActivity(threads[i]))
+	})
+
+	total := len(threads)
+	offset := queryInt(r, "offset", 0)
+	if offset > len(threads) {
+		offset = len(threads)
+	}
+	threads = threads[offset:]
+	limit := queryInt(r, "limit", 25)
+	if limit > 25 {
+		limit = 25
+	}
+	hasMore := len(threads) > limit
+	if hasMore {
+		threads = threads[:limit]
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"threads":       threads,
+		"members":       s.threadMembersLocked(threads),
+		"has_more":      hasMore,
+		"total_results": total,
+	})
+}
+
+// lastActivity is the ID of a thread's newest message, or of the thread
+// itself while it has none
+func lastActivity(ch *discordgo.Channel) string {
+	if ch.LastMessageID != "" {
+		return ch.LastMessageID
+	}
+	return ch.ID
+}
+
+// threadCreate is a start thread request. Forum posts carry their starter
+// message along.
+type threadCreate struct {
+	discordgo.ThreadStart
+	Message *messageCreate `json:"message"`
+}
+
+func (s *Server) handleStartThread(w http.ResponseWriter, r *http.Request) {
+	var data threadCreate
+	uploads, err := decodeWithFiles(r, &data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parent := s.channelLocked(r.PathValue("channel"))
+	if parent == nil {
+		notFound(w, "Channel")
+		return
+	}
+	if parent.Type == discordgo.ChannelTypeGuildForum && r.PathValue("message") == "" {
+		s.startForumPostLocked(w, parent, &data, uploads)
+		return
+	}
+	if parent.Type != discordgo.ChannelTypeGuildText && parent.Type != discordgo.ChannelTypeGuildNews {
+		writeError(w, http.StatusBadRequest, 50024, "Cannot execute action on this channel type")
+		return
+	}
+	if n := len([]rune(data.Name)); n == 0 || n > 100 {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+		return
+	}
+
+	autoArchive := data.AutoArchiveDuration
+	if autoArchive == 0 {
+		autoArchive = 1440
+	}
+	thread := &discordgo.Channel{
+		GuildID:        parent.GuildID,
+		ParentID:       parent.ID,
+		OwnerID:        s.me.ID,
+		Name:           data.Name,
+		Type:           discordgo.ChannelTypeGuildPublicThread,
+		MemberCount:    1,
+		ThreadMetadata: &discordgo.ThreadMetadata{AutoArchiveDuration: autoArchive, Invitable: data.Invitable},
+	}
+	if parent.Type == discordgo.ChannelTypeGuildNews {
+		thread.Type = discordgo.ChannelTypeGuildNewsThread
+	}
+
+	// A thread started from a message shares the message's ID
+	if messageID := r.PathValue("message"); messageID != "" {
+		m := s.messageLocked(parent.ID, messageID)
+		if m == nil {
+			notFound(w, "Message")
+			return
+		}
+		if m.Thread != nil {
+			writeError(w, http.StatusBadRequest, 160004, "A thread has already been created for this message")
+			return
+		}
+		thread.ID = m.ID
+		m.Thread = thread
+	} else {
+		if data.Type == discordgo.ChannelTypeGuildPrivateThread {
+			thread.Type = discordgo.ChannelTypeGuildPrivateThread
+		}
+		thread.ID = s.nextIDLocked(s.clock())
+	}
+
+	s.channels = append(s.channels, thread)
+	s.joined[thread.ID] = true
+	writeJSON(w, http.StatusCreated, thread)
+}
+
+// startForumPostLocked creates a forum thread with its starter message,
+// which shares the thread's ID
+func (s *Server) startForumPostLocked(w http.ResponseWriter, forum *discordgo.Channel, data *threadCreate, uploads []*upload) {
+	if n := len([]rune(data.Name)); n == 0 || n > 100 || data.Message == nil || len(data.AppliedTags) > 5 {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+		return
+	}
+	if status, code, message, ok := validMessage(data.Message, uploads); !ok {
+		writeError(w, status, code, message)
+		return
+	}
+	for _, id := range data.AppliedTags {
+		known := false
+		for _, t := range forum.AvailableTags {
+			known = known || t.ID == id
+		}
+		if !known {
+			writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+			return
+		}
+	}
+	if forum.Flags&discordgo.ChannelFlagRequireTag != 0 && len(data.AppliedTags) == 0 {
+		writeError(w, http.StatusBadRequest, 40067, "A tag is required to create a forum post in this channel")
+		return
+	}
+
+	autoArchive := data.AutoArchiveDuration
+	if autoArchive == 0 {
+		autoArchive = 4320
+	}
+	thread := &discordgo.Channel{
+		ID:             s.nextIDLocked(s.clock()),
+		GuildID:        forum.GuildID,
+		ParentID:       forum.ID,
+		OwnerID:        s.me.ID,
+		Name:           data.Name,
+		Type:           discordgo.ChannelTypeGuildPublicThread,
+		AppliedTags:    data.AppliedTags,
+		ThreadMetadata: &discordgo.ThreadMetadata{AutoArchiveDuration: autoArchive},
+	}
+	s.channels = append(s.channels, thread)
+
+	m := s.newMessageLocked(thread, data.Message)
+	m.ID = thread.ID
+	m = s.postMessageLocked(thread, m, data.Message, uploads)
+	thread.MemberCount = 1
+
+	writeJSON(w, http.StatusCreated, struct {
+		*discordgo.Channel
+		Message *discordgo.Message `json:"message"`
+	}{thread, m})
+}
+
+func (s *Server) handleEditChannel(w http.ResponseWriter, r *http.Request) {
+	var data discordgo.ChannelEdit
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := s.channelLocked(r.PathValue("channel"))
+	if ch == nil {
+		notFound(w, "Channel")
+		return
+	}
+	if data.Name != "" {
+		ch.Name = data.Name
+	}
+	if ch.ThreadMetadata != nil {
+		if data.Archived != nil {
+			if *data.Archived && !ch.ThreadMetadata.Archived {
+				ch.ThreadMetadata.ArchiveTimestamp = s.clock()
+			}
+			ch.ThreadMetadata.Archived = *data.Archived
+		}
+		if data.Locked != nil {
+			ch.ThreadMetadata.Locked = *data.Locked
+		}
+	}
+	writeJSON(w, http.StatusOK, ch)
+}
+
+func (s *Server) handleAddRecipient(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, user := s.groupRecipientLocked(w, r)
+	if ch == nil {
+		return
+	}
+	for _, u := range ch.Recipients {
+		if u.ID == user.ID {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	ch.Recipients = append(ch.Recipients, user)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRemoveRecipient removes a user from a group DM; only the owner may
+// remove anyone else
+func (s *Server) handleRemoveRecipient(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, user := s.groupRecipientLocked(w, r)
+	if ch == nil {
+		return
+	}
+	if ch.OwnerID != s.me.ID {
+		writeError(w, http.StatusForbidden, 50013, "Missing Permissions")
+		return
+	}
+	for i, u := range ch.Recipients {
+		if u.ID == user.ID {
+			ch.Recipients = append(ch.Recipients[:i], ch.Recipients[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// groupRecipientLocked looks up the group DM and user of a recipients
+// request, writing the error response when either is missing
+func (s *Server) groupRecipientLocked(w http.ResponseWriter, r *http.Request) (*discordgo.Channel, *discordgo.User) {
+	ch := s.channelLocked(r.PathValue("channel"))
+	if ch == nil {
+		notFound(w, "Channel")
+		return nil, nil
+	}
+	if ch.Type != discordgo.ChannelTypeGroupDM {
+		writeError(w, http.StatusBadRequest, 50024, "Cannot execute action on this channel type")
+		return nil, nil
+	}
+	user, ok := s.users[r.PathValue("user")]
+	if !ok {
+		notFound(w, "User")
+		return nil, nil
+	}
+	return ch, user
+}
+
+func (s *Server) handleJoinThread(w http.ResponseWriter, r *http.Request) {
+	s.setThreadMember(w, r, true)
+}
+
+func (s *Server) handleLeaveThread(w http.ResponseWriter, r *http.Request) {
+	s.setThreadMember(w, r, false)
+}
+
+func (s *Server) setThreadMember(w http.ResponseWriter, r *http.Request, joined bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := s.channelLocked(r.PathValue("channel"))
+	if ch == nil {
+		notFound(w, "Channel")
+		return
+	}
+	if !ch.IsThread() {
+		writeError(w, http.StatusBadRequest, 50024, "Cannot execute action on this channel type")
+		return
+	}
+	if ch.ThreadMetadata != nil && ch.ThreadMetadata.Archived {
+		writeError(w, http.StatusBadRequest, 50083, "Thread is archived")
+		return
+	}
+	if s.joined[ch.ID] != joined {
+		if joined {
+			ch.MemberCount++
+		} else {
+			ch.MemberCount--
+		}
+	}
+	s.joined[ch.ID] = joined
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// searchHit is a message as returned inside a search result group
+type searchHit struct {
+	*discordgo.Message
+	Hit bool `json:"hit"`
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	guildID := r.PathValue("guild")
+	if s.guildLocked(guildID) == nil {
+		notFound(w, "Guild")
+		return
+	}
+
+	q := r.URL.Query()
+	content := strings.ToLower(q.Get("content"))
+	var hits []*discordgo.Message
+	for _, ch := range s.channels {
+		if ch.GuildID != guildID {
+			continue
+		}
+		if channelID := q.Get("channel_id"); channelID != "" && ch.ID != channelID {
+			continue
+		}
+		for _, m := range s.messages[ch.ID] {
+			if content != "" && !strings.Contains(strings.ToLower(m.Content), content) {
+				continue
+			}
+			if authorID := q.Get("author_id"); authorID != "" && (m.Author == nil || m.Author.ID != authorID) {
+				continue
+			}
+			if minID := q.Get("min_id"); minID != "" && !snowflake.Less(minID, m.ID) {
+				continue
+			}
+			if maxID := q.Get("max_id"); maxID != "" && !snowflake.Less(m.ID, maxID) {
+				continue
+			}
+			if pinned := q.Get("pinned"); pinned != "" && strconv.FormatBool(m.Pinned) != pinned {
+				continue
+			}
+			hits = append(hits, m)
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if q.Get("sort_order") == "asc" {
+			return snowflake.Less(hits[i].ID, hits[j].ID)
+		}
+		return snowflake.Less(hits[j].ID, hits[i].ID)
+	})
+
+	total := len(hits)
+	offset := queryInt(r, "offset", 0)
+	if offset > len(hits) {
+		offset = len(hits)
+	}
+	hits = hits[offset:]
+	if len(hits) > searchPageSize {
+		hits = hits[:searchPageSize]
+	}
+
+	groups := make([][]searchHit, 0, len(hits))
+	for _, m := range hits {
+		groups = append(groups, []searchHit{{Message: m, Hit: true}})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"total_results": total,
+		"messages":      groups,
+	})
+}
@@ -0,0 +1,294 @@
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/snowflake"
+)
+
+// archivedPageSize is the most archived threads Discord returns per page
+const archivedPageSize = 100
+
+// MaxAppliedTags is the most tags a forum post can carry
+const MaxAppliedTags = 5
+
+// Forum is a forum channel with the tags posts can be filed under.
+// RequireTag is set when every post needs at least one tag.
+type Forum struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	GuildID    string      `json:"guild_id,omitempty"`
+	Guidelines string      `json:"guidelines,omitempty"`
+	Tags       []*ForumTag `json:"tags"`
+	RequireTag bool        `json:"require_tag"`
+}
+
+// ForumTag is a tag of a forum channel. Moderated tags can only be applied
+// by moderators.
+type ForumTag struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Emoji     string `json:"emoji,omitempty"`
+	Moderated bool   `json:"moderated,omitempty"`
+}
+
+// ForumPost is a new forum thread: a title, the tags to file it under and
+// its starter message
+type ForumPost struct {
+	Title               string
+	TagIDs              []string
+	AutoArchiveDuration int
+	Message             *OutgoingMessage
+}
+
+// ForumThreadOptions filters forum thread listings. Threads match if they
+// carry any of TagIDs. A zero Limit lists every thread. Forum is the forum
+// listed, if the caller already fetched it.
+type ForumThreadOptions struct {
+	Limit      int
+	ActiveOnly bool
+	TagIDs     []string
+	Forum      *Forum
+}
+
+func newForum(ch *discordgo.Channel) *Forum {
+	f := &Forum{
+		ID:         ch.ID,
+		Name:       ch.Name,
+		GuildID:    ch.GuildID,
+		Guidelines: ch.Topic,
+		Tags:       make([]*ForumTag, 0, len(ch.AvailableTags)),
+		RequireTag: ch.Flags&discordgo.ChannelFlagRequireTag != 0,
+	}
+	for _, t := range ch.AvailableTags {
+		tag := &ForumTag{ID: t.ID, Name: t.Name, Moderated: t.Moderated}
+		switch {
+		case t.EmojiName != "" && t.EmojiID != "":
+			tag.Emoji = ":" + t.EmojiName + ":"
+		case t.EmojiName != "":
+			tag.Emoji = t.EmojiName
+		}
+		f.Tags = append(f.Tags, tag)
+	}
+	return f
+}
+
+// GetForum fetches a forum channel and its tags
+func (c *Client) GetForum(channelID string) (*Forum, error) {
+	ch, err := c.session.Channel(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forum: %w", err)
+	}
+	if ch.Type != discordgo.ChannelTypeGuildForum && ch.Type != discordgo.ChannelTypeGuildMedia {
+		return nil, fmt.Errorf("channel %s is not a forum", channelID)
+	}
+	return newForum(ch), nil
+}
+
+// Tag finds a tag by ID or case-insensitive name
+func (f *Forum) Tag(ref string) (*ForumTag, error) {
+	for _, t := range f.Tags {
+		if t.ID == ref || strings.EqualFold(t.Name, ref) {
+			return t, nil
+		}
+	}
+	names := make([]string, 0, len(f.Tags))
+	for _, t := range f.Tags {
+		names = append(names, t.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("forum %s has no tags", f.Name)
+	}
+	return nil, fmt.Errorf("tag %q not found in %s, available: %s", ref, f.Name, strings.Join(names, ", "))
+}
+
+// tagNames maps applied tag IDs to their names, keeping unknown IDs as is
+func (f *Forum) tagNames(ids []string) []string {
+	var names []string
+	for _, id := range ids {
+		name := id
+		for _, t := range f.Tags {
+			if t.ID == id {
+				name = t.Name
+				break
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// hasAnyTag reports whether applied contains any of want
+func hasAnyTag(applied, want []string) bool {
+	for _, id := range applied {
+		for _, w := range want {
+			if id == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ListForumThreads lists a forum's threads, most recently active first.
+// Active threads come from the forum's thread search and archived ones
+// from the archived threads listing, paged back until Limit threads are
+// found or the forum's history is exhausted.
+func (c *Client) ListForumThreads(channelID string, opts ForumThreadOptions) ([]*ForumThread, error) {
+	forum := opts.Forum
+	if forum == nil {
+		var err error
+		if forum, err = c.GetForum(channelID); err != nil {
+			return nil, err
+		}
+	}
+
+	query := url.Values{"archived": {"false"}}
+	if len(opts.TagIDs) > 0 {
+		query.Set("tag", strings.Join(opts.TagIDs, ","))
+		query.Set("tag_setting", "match_some")
+	}
+	threads, err := c.searchThreads(channelID, query, opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.ActiveOnly {
+		archived, err := c.archivedThreads(channelID, opts)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, archived...)
+	}
+
+	// A thread archived while paging can show up twice
+	seen := make(map[string]bool, len(threads))
+	unique := threads[:0]
+	for _, t := range threads {
+		if seen[t.ID] || (len(opts.TagIDs) > 0 && !hasAnyTag(t.AppliedTags, opts.TagIDs)) {
+			continue
+		}
+		seen[t.ID] = true
+		unique = append(unique, t)
+	}
+	threads = unique
+
+	sort.SliceStable(threads, func(i, j int) bool {
+		return snowflake.Less(threads[j].LastActivityID(), threads[i].LastActivityID())
+	})
+	if opts.Limit > 0 && len(threads) > opts.Limit {
+		threads = threads[:opts.Limit]
+	}
+
+	result := make([]*ForumThread, 0, len(threads))
+	for _, t := range threads {
+		ft := &ForumThread{
+			ID:             t.ID,
+			Name:           t.Name,
+			MessageCount:   t.MessageCount,
+			CreatedAt:      t.CreatedAt,
+			LastMessageID:  t.LastMessageID,
+			Archived:       t.Archived,
+			Tags:           forum.tagNames(t.AppliedTags),
+			LastActivityAt: snowflake.Timestamp(t.LastActivityID()),
+		}
+		if t.OwnerID != "" {
+			ft.Author = c.memberAuthor(forum.GuildID, t.OwnerID)
+		}
+		result = append(result, ft)
+	}
+	return result, nil
+}
+
+// archivedThreads pages back through a forum's archived public threads,
+// newest archived first, until opts.Limit matching threads are found
+func (c *Client) archivedThreads(channelID string, opts ForumThreadOptions) ([]*Thread, error) {
+	var result []*Thread
+	var before *time.Time
+	for {
+		list, err := c.session.ThreadsArchived(channelID, before, archivedPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get archived threads: %w", err)
+		}
+
+		for _, t := range newThreads(list.Threads, list.Members) {
+			if len(opts.TagIDs) > 0 && !hasAnyTag(t.AppliedTags, opts.TagIDs) {
+				continue
+			}
+			result = append(result, t)
+			if opts.Limit > 0 && len(result) >= opts.Limit {
+				return result, nil
+			}
+		}
+
+		if !list.HasMore || len(list.Threads) == 0 {
+			return result, nil
+		}
+		last := list.Threads[len(list.Threads)-1].ThreadMetadata
+		if last == nil || (before != nil && !last.ArchiveTimestamp.Before(*before)) {
+			return result, nil
+		}
+		cursor := last.ArchiveTimestamp
+		before = &cursor
+	}
+}
+
+// forumThreadCreate is the request body for a forum post
+type forumThreadCreate struct {
+	Name                string         `json:"name"`
+	AutoArchiveDuration int            `json:"auto_archive_duration,omitempty"`
+	AppliedTags         []string       `json:"applied_tags,omitempty"`
+	Message             *messageCreate `json:"message"`
+}
+
+// CreateForumPost starts a thread in a forum channel and returns it with
+// its starter message
+func (c *Client) CreateForumPost(channelID string, post *ForumPost) (*Thread, *Message, error) {
+	send := post.Message.messageSend()
+	data := &forumThreadCreate{
+		Name:                post.Title,
+		AutoArchiveDuration: post.AutoArchiveDuration,
+		AppliedTags:         post.TagIDs,
+		Message:             &messageCreate{MessageSend: send},
+	}
+
+	endpoint := discordgo.EndpointChannelThreads(channelID)
+	var response []byte
+	var err error
+	if len(send.Files) > 0 {
+		contentType, body, encodeErr := discordgo.MultipartBodyWithJSON(data, send.Files)
+		if encodeErr != nil {
+			return nil, nil, encodeErr
+		}
+		response, err = c.session.RequestRaw("POST", endpoint, contentType, body, endpoint, 0)
+	} else {
+		response, err = c.session.RequestWithBucketID("POST", endpoint, data, endpoint)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create forum post: %w", err)
+	}
+
+	var created struct {
+		discordgo.Channel
+		Message *discordgo.Message `json:"message"`
+	}
+	if err := json.Unmarshal(response, &created); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse forum post: %w", err)
+	}
+
+	// The starter message shares the thread's ID
+	starter := created.Message
+	if starter == nil {
+		if starter, err = c.session.ChannelMessage(created.ID, created.ID); err != nil {
+			return nil, nil, fmt.Errorf("forum post %s was created, but its message could not be fetched: %w", created.ID, err)
+		}
+	}
+	msg := newMessage(starter)
+	msg.setGuild(created.GuildID)
+	return newThread(&created.Channel), msg, nil
+}
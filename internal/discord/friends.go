@@ -0,0 +1,145 @@
+package discord
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Relationship types as Discord reports them
+const (
+	relationshipFriend          = 1
+	relationshipBlocked         = 2
+	relationshipPendingIncoming = 3
+	relationshipPendingOutgoing = 4
+)
+
+// Relationship names in the output
+const (
+	RelationshipFriend          = "friend"
+	RelationshipBlocked         = "blocked"
+	RelationshipPendingIncoming = "pending_incoming"
+	RelationshipPendingOutgoing = "pending_outgoing"
+)
+
+// relationship is an entry of the current user's relationships list
+type relationship struct {
+	ID       string          `json:"id"`
+	Type     int             `json:"type"`
+	User     *discordgo.User `json:"user"`
+	Nickname string          `json:"nickname"`
+	Since    string          `json:"since"`
+}
+
+// relationshipName names a relationship type in the output
+func relationshipName(t int) string {
+	switch t {
+	case relationshipFriend:
+		return RelationshipFriend
+	case relationshipBlocked:
+		return RelationshipBlocked
+	case relationshipPendingIncoming:
+		return RelationshipPendingIncoming
+	case relationshipPendingOutgoing:
+		return RelationshipPendingOutgoing
+	default:
+		return ""
+	}
+}
+
+// Friend is a user on the current user's relationships list: a friend, a
+// blocked user or a pending friend request. Nickname is the private
+// nickname given to a friend.
+type Friend struct {
+	Author
+	Relationship string `json:"relationship"`
+	Nickname     string `json:"nickname,omitempty"`
+	Since        string `json:"since,omitempty"`
+}
+
+// relationshipsEndpoint is the current user's relationships, or one of
+// them when userID is set
+func relationshipsEndpoint(userID string) string {
+	endpoint := discordgo.EndpointUser("@me") + "/relationships"
+	if userID != "" {
+		endpoint += "/" + userID
+	}
+	return endpoint
+}
+
+// relationships fetches the current user's friends, blocked users and
+// pending friend requests
+func (c *Client) relationships() ([]*relationship, error) {
+	endpoint := relationshipsEndpoint("")
+	body, err := c.session.RequestWithBucketID("GET", endpoint, nil, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationships: %w", err)
+	}
+	var rels []*relationship
+	if err := discordgo.Unmarshal(body, &rels); err != nil {
+		return nil, fmt.Errorf("failed to parse relationships: %w", err)
+	}
+	return rels, nil
+}
+
+// ListRelationships lists the current user's friends, blocked users and
+// pending friend requests
+func (c *Client) ListRelationships() ([]*Friend, error) {
+	rels, err := c.relationships()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Friend, 0, len(rels))
+	for _, r := range rels {
+		if r.User == nil {
+			continue
+		}
+		result = append(result, &Friend{
+			Author:       newAuthor(r.User),
+			Relationship: relationshipName(r.Type),
+			Nickname:     r.Nickname,
+			Since:        r.Since,
+		})
+	}
+	return result, nil
+}
+
+// SendFriendRequest sends a friend request to a username
+func (c *Client) SendFriendRequest(username string) error {
+	endpoint := relationshipsEndpoint("")
+	data := map[string]interface{}{"username": username, "discriminator": nil}
+	if _, err := c.session.RequestWithBucketID("POST", endpoint, data, endpoint); err != nil {
+		return fmt.Errorf("failed to send friend request: %w", err)
+	}
+	return nil
+}
+
+// AddFriend sends a friend request to a user, or accepts theirs when they
+// sent one
+func (c *Client) AddFriend(userID string) error {
+	endpoint := relationshipsEndpoint(userID)
+	if _, err := c.session.RequestWithBucketID("PUT", endpoint, struct{}{}, relationshipsEndpoint("")); err != nil {
+		return fmt.Errorf("failed to add friend: %w", err)
+	}
+	return nil
+}
+
+// BlockUser blocks a user, ending any friendship or pending request
+func (c *Client) BlockUser(userID string) error {
+	endpoint := relationshipsEndpoint(userID)
+	data := map[string]int{"type": relationshipBlocked}
+	if _, err := c.session.RequestWithBucketID("PUT", endpoint, data, relationshipsEndpoint("")); err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+	return nil
+}
+
+// RemoveRelationship removes a friend, cancels or declines a friend
+// request, or unblocks a user
+func (c *Client) RemoveRelationship(userID string) error {
+	endpoint := relationshipsEndpoint(userID)
+	if _, err := c.session.RequestWithBucketID("DELETE", endpoint, nil, relationshipsEndpoint("")); err != nil {
+		return fmt.Errorf("failed to remove relationship: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package discord
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// GroupDM is a group conversation. Recipients are everyone in it besides
+// the current user; Icon is the URL of its picture, if it has one.
+type GroupDM struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name,omitempty"`
+	Icon       string    `json:"icon,omitempty"`
+	OwnerID    string    `json:"owner_id,omitempty"`
+	Recipients []*Author `json:"recipients"`
+}
+
+// newGroupDM converts a group DM channel
+func newGroupDM(ch *discordgo.Channel) *GroupDM {
+	group := &GroupDM{
+		ID:         ch.ID,
+		Name:       ch.Name,
+		OwnerID:    ch.OwnerID,
+		Recipients: make([]*Author, 0, len(ch.Recipients)),
+	}
+	if ch.Icon != "" {
+		group.Icon = discordgo.EndpointGroupIcon(ch.ID, ch.Icon)
+	}
+	for _, u := range ch.Recipients {
+		author := newAuthor(u)
+		group.Recipients = append(group.Recipients, &author)
+	}
+	return group
+}
+
+// HasRecipient reports whether a user is in the group
+func (g *GroupDM) HasRecipient(userID string) bool {
+	for _, r := range g.Recipients {
+		if r.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// ListGroupDMs lists the current user's group DMs
+func (c *Client) ListGroupDMs() ([]*GroupDM, error) {
+	channels, err := c.dmChannels()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*GroupDM, 0)
+	for _, ch := range channels {
+		if ch.Type == discordgo.ChannelTypeGroupDM {
+			result = append(result, newGroupDM(ch))
+		}
+	}
+	return result, nil
+}
+
+// GetGroupDM gets a group DM by channel ID
+func (c *Client) GetGroupDM(channelID string) (*GroupDM, error) {
+	ch, err := c.session.Channel(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group DM: %w", err)
+	}
+	if ch.Type != discordgo.ChannelTypeGroupDM {
+		return nil, fmt.Errorf("channel %s is not a group DM", channelID)
+	}
+	return newGroupDM(ch), nil
+}
+
+// CreateGroupDM starts a group DM with the given users
+func (c *Client) CreateGroupDM(userIDs []string) (*GroupDM, error) {
+	endpoint := discordgo.EndpointUserChannels("@me")
+	data := map[string][]string{"recipients": userIDs}
+	body, err := c.session.RequestWithBucketID("POST", endpoint, data, discordgo.EndpointUserChannels(""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group DM: %w", err)
+	}
+	var ch *discordgo.Channel
+	if err := discordgo.Unmarshal(body, &ch); err != nil {
+		return nil, fmt.Errorf("failed to parse group DM: %w", err)
+	}
+	return newGroupDM(ch), nil
+}
+
+// AddGroupRecipient adds a user to a group DM
+func (c *Client) AddGroupRecipient(channelID, userID string) error {
+	endpoint := discordgo.EndpointChannel(channelID) + "/recipients/" + userID
+	if _, err := c.session.RequestWithBucketID("PUT", endpoint, nil, discordgo.EndpointChannel(channelID)+"/recipients"); err != nil {
+		return fmt.Errorf("failed to add user to group DM: %w", err)
+	}
+	return nil
+}
+
+// RemoveGroupRecipient removes a user from a group DM. Only the group's
+// owner may remove others.
+func (c *Client) RemoveGroupRecipient(channelID, userID string) error {
+	endpoint := discordgo.EndpointChannel(channelID) + "/recipients/" + userID
+	if _, err := c.session.RequestWithBucketID("DELETE", endpoint, nil, discordgo.EndpointChannel(channelID)+"/recipients"); err != nil {
+		return fmt.Errorf("failed to remove user from group DM: %w", err)
+	}
+	return nil
+}
+
+// RenameGroupDM renames a group DM
+func (c *Client) RenameGroupDM(channelID, name string) (*GroupDM, error) {
+	ch, err := c.session.ChannelEdit(channelID, &discordgo.ChannelEdit{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename group DM: %w", err)
+	}
+	return newGroupDM(ch), nil
+}
@@ -0,0 +1,102 @@
+package discord
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// memberNick returns a user's nickname in a guild. Lookups are cached for
+// the client's lifetime; members that cannot be fetched, e.g. because they
+// left the guild, have no nickname.
+func (c *Client) memberNick(guildID, userID string) string {
+	key := guildID + "/" + userID
+	if nick, ok := c.nicks[key]; ok {
+		return nick
+	}
+
+	var nick string
+	if member, err := c.session.GuildMember(guildID, userID); err == nil {
+		nick = member.Nick
+	}
+	c.nicks[key] = nick
+	return nick
+}
+
+// memberAuthor returns a user as they appear in a guild, with their
+// nickname. Users who left the guild are looked up without one, and users
+// that cannot be found at all are returned with just their ID. Lookups are
+// cached for the client's lifetime.
+func (c *Client) memberAuthor(guildID, userID string) *Author {
+	key := guildID + "/" + userID
+	if a, ok := c.members[key]; ok {
+		return a
+	}
+
+	a := &Author{ID: userID}
+	if member, err := c.session.GuildMember(guildID, userID); err == nil && member.User != nil {
+		*a = newAuthor(member.User)
+		a.setNick(member.Nick)
+		c.nicks[key] = member.Nick
+	} else if u, err := c.session.User(userID); err == nil {
+		*a = newAuthor(u)
+	}
+	c.members[key] = a
+	return a
+}
+
+// GetMember gets a user as they appear in a guild, with their nickname.
+// Users who left the guild are returned without one.
+func (c *Client) GetMember(guildID, userID string) (*Author, error) {
+	a := *c.memberAuthor(guildID, userID)
+	if a.Username == "" {
+		return nil, fmt.Errorf("failed to get user %s", userID)
+	}
+	return &a, nil
+}
+
+// channelGuildID returns the guild a channel belongs to, "" for DMs. ok is
+// false when the channel could not be looked up.
+func (c *Client) channelGuildID(channelID string) (guildID string, ok bool) {
+	if guildID, ok := c.channelGuilds[channelID]; ok {
+		return guildID, true
+	}
+
+	ch, err := c.session.Channel(channelID)
+	if err != nil {
+		return "", false
+	}
+	c.channelGuilds[channelID] = ch.GuildID
+	return ch.GuildID, true
+}
+
+// applyNicks fills in the guild nickname of each message author that has one
+func (c *Client) applyNicks(guildID string, msgs []*Message) {
+	if guildID == "" {
+		return
+	}
+	for _, m := range msgs {
+		if m.Author.ID == "" || m.Author.Nick != "" {
+			continue
+		}
+		m.Author.setNick(c.memberNick(guildID, m.Author.ID))
+	}
+}
+
+// messageGuildID returns the guild of a discordgo message, looking it up
+// through the channel when the API left it out
+func (c *Client) messageGuildID(m *discordgo.Message) (string, bool) {
+	if m.GuildID != "" {
+		return m.GuildID, true
+	}
+	return c.channelGuildID(m.ChannelID)
+}
+
+// convertMessage converts a single message, filling in its guild and URL
+func (c *Client) convertMessage(m *discordgo.Message) *Message {
+	msg := newMessage(m)
+	if guildID, ok := c.messageGuildID(m); ok {
+		msg.setGuild(guildID)
+	}
+	return msg
+}
@@ -0,0 +1,109 @@
+package discord
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MentionPolicy controls who a sent message may notify. The zero value
+// notifies nobody.
+type MentionPolicy struct {
+	Users    bool
+	Roles    bool
+	Everyone bool // @everyone and @here
+	// ReplyPing notifies the author of the message being replied to
+	ReplyPing bool
+}
+
+// ParseMentionPolicy reads a comma-separated list of users, roles and
+// everyone, or none. ReplyPing is left for the caller to set.
+func ParseMentionPolicy(s string) (*MentionPolicy, error) {
+	p := &MentionPolicy{}
+	for _, kind := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case "users":
+			p.Users = true
+		case "roles":
+			p.Roles = true
+		case "everyone":
+			p.Everyone = true
+		case "none", "":
+		default:
+			return nil, fmt.Errorf("unknown mention type %q, expected users, roles, everyone or none", kind)
+		}
+	}
+	return p, nil
+}
+
+// allowedMentions converts the policy into Discord's allowed_mentions
+func (p *MentionPolicy) allowedMentions() *discordgo.MessageAllowedMentions {
+	am := &discordgo.MessageAllowedMentions{
+		Parse:       []discordgo.AllowedMentionType{},
+		RepliedUser: p.ReplyPing,
+	}
+	if p.Users {
+		am.Parse = append(am.Parse, discordgo.AllowedMentionTypeUsers)
+	}
+	if p.Roles {
+		am.Parse = append(am.Parse, discordgo.AllowedMentionTypeRoles)
+	}
+	if p.Everyone {
+		am.Parse = append(am.Parse, discordgo.AllowedMentionTypeEveryone)
+	}
+	return am
+}
+
+// Mention is someone a message mentions. Notified reports whether the
+// mention pings them under the message's MentionPolicy.
+type Mention struct {
+	Kind     string `json:"kind"` // user, role, everyone, here or reply
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Notified bool   `json:"notified"`
+}
+
+// mentionPattern matches user and role mentions, @everyone and @here
+var mentionPattern = regexp.MustCompile(`<@(!?|&)(\d+)>|@(everyone|here)\b`)
+
+// Mentions lists who content sent to channelID mentions, with names
+// resolved, and whether policy lets each of them be notified
+func (r *Renderer) Mentions(channelID, content string, policy *MentionPolicy) []*Mention {
+	var mentions []*Mention
+	seen := make(map[string]bool)
+	for _, sub := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		m := &Mention{ID: sub[2]}
+		switch {
+		case sub[3] != "":
+			m.Kind = sub[3]
+			m.Name = "@" + sub[3]
+			m.Notified = policy.Everyone
+		case sub[1] == "&":
+			m.Kind = "role"
+			m.Name = "<@&" + m.ID + ">"
+			if channelID != "" {
+				if ch := r.channel(channelID); ch != nil {
+					if name := r.role(ch.GuildID, m.ID); name != "" {
+						m.Name = "@" + name
+					}
+				}
+			}
+			m.Notified = policy.Roles
+		default:
+			m.Kind = "user"
+			m.Name = "<@" + m.ID + ">"
+			if name := r.user(r.guildOf(channelID), m.ID); name != "" {
+				m.Name = "@" + name
+			}
+			m.Notified = policy.Users
+		}
+
+		if key := m.Kind + m.ID; !seen[key] {
+			seen[key] = true
+			mentions = append(mentions, m)
+		}
+	}
+	return mentions
+}
@@ -0,0 +1,61 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestParseMentionPolicy(t *testing.T) {
+	tests := []struct {
+		in   string
+		want MentionPolicy
+	}{
+		{"users", MentionPolicy{Users: true}},
+		{"users, Roles,everyone", MentionPolicy{Users: true, Roles: true, Everyone: true}},
+		{"none", MentionPolicy{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseMentionPolicy(tt.in)
+		if err != nil || *got != tt.want {
+			t.Errorf("ParseMentionPolicy(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseMentionPolicy("users,here"); err == nil {
+		t.Error("expected an unknown mention type to fail")
+	}
+
+	am := (&MentionPolicy{Roles: true, ReplyPing: true}).allowedMentions()
+	if len(am.Parse) != 1 || am.Parse[0] != discordgo.AllowedMentionTypeRoles || !am.RepliedUser {
+		t.Errorf("unexpected allowed mentions: %+v", am)
+	}
+	if am := (&MentionPolicy{}).allowedMentions(); am.Parse == nil || len(am.Parse) != 0 {
+		t.Error("an empty policy must send an empty parse list, not omit it")
+	}
+}
+
+func TestRendererMentions(t *testing.T) {
+	r := NewRenderer(&lookupStub{calls: make(map[string]int)})
+	policy := &MentionPolicy{Users: true}
+
+	mentions := r.Mentions("20", "<@11> <@!11> <@&30> <@99> @everyone and @here", policy)
+	want := []Mention{
+		{Kind: "user", ID: "11", Name: "@alice", Notified: true},
+		{Kind: "role", ID: "30", Name: "@moderators"},
+		{Kind: "user", ID: "99", Name: "<@99>", Notified: true},
+		{Kind: "everyone", Name: "@everyone"},
+		{Kind: "here", Name: "@here"},
+	}
+	if len(mentions) != len(want) {
+		t.Fatalf("expected %d mentions, got %d: %+v", len(want), len(mentions), mentions)
+	}
+	for i, m := range mentions {
+		if *m != want[i] {
+			t.Errorf("mention %d = %+v, want %+v", i, *m, want[i])
+		}
+	}
+
+	if m := r.Mentions("20", "no pings here", policy); len(m) != 0 {
+		t.Errorf("expected no mentions, got %+v", m)
+	}
+}
@@ -0,0 +1,344 @@
+package discord
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Message represents a Discord message
+type Message struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+	Author    Author `json:"author"`
+	Content   string `json:"content"`
+	Timestamp string `json:"timestamp"`
+	// RawContent holds the unrendered content when Renderer changed it
+	RawContent string `json:"raw_content,omitempty"`
+
+	// Metadata below is dropped by Compact
+	GuildID         string            `json:"guild_id,omitempty"`
+	URL             string            `json:"url,omitempty"`
+	Type            string            `json:"type,omitempty"`
+	EditedTimestamp string            `json:"edited_timestamp,omitempty"`
+	Pinned          bool              `json:"pinned,omitempty"`
+	Attachments     []*Attachment     `json:"attachments,omitempty"`
+	Embeds          []*Embed          `json:"embeds,omitempty"`
+	Reactions       []*Reaction       `json:"reactions,omitempty"`
+	Reference       *MessageReference `json:"message_reference,omitempty"`
+	Mentions        []Author          `json:"mentions,omitempty"`
+	MentionRoles    []string          `json:"mention_roles,omitempty"`
+	MentionEveryone bool              `json:"mention_everyone,omitempty"`
+	Thread          *ThreadInfo       `json:"thread,omitempty"`
+	Flags           []string          `json:"flags,omitempty"`
+}
+
+// Author represents a message author. DisplayName is what Discord shows:
+// the guild nickname, else the global display name, else the username.
+type Author struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	GlobalName  string `json:"global_name,omitempty"`
+	Nick        string `json:"nick,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Bot         bool   `json:"bot"`
+}
+
+// setNick records a guild nickname, which takes precedence as display name
+func (a *Author) setNick(nick string) {
+	if nick == "" {
+		return
+	}
+	a.Nick = nick
+	a.DisplayName = nick
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int    `json:"size"`
+	URL         string `json:"url"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+}
+
+// Embed is a rich embed, either posted by a bot or generated from a link
+type Embed struct {
+	Type         string        `json:"type,omitempty"`
+	Title        string        `json:"title,omitempty"`
+	Description  string        `json:"description,omitempty"`
+	URL          string        `json:"url,omitempty"`
+	Color        int           `json:"color,omitempty"`
+	AuthorName   string        `json:"author_name,omitempty"`
+	Fields       []*EmbedField `json:"fields,omitempty"`
+	Footer       string        `json:"footer,omitempty"`
+	ImageURL     string        `json:"image_url,omitempty"`
+	ThumbnailURL string        `json:"thumbnail_url,omitempty"`
+	VideoURL     string        `json:"video_url,omitempty"`
+	Provider     string        `json:"provider,omitempty"`
+	Timestamp    string        `json:"timestamp,omitempty"`
+
+	// payload is the embed as parsed from --embed-json, sent unchanged
+	// since the fields above leave parts of it out
+	payload *discordgo.MessageEmbed
+}
+
+// EmbedField is a name/value pair inside an embed
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// Reaction summarises one emoji's reactions on a message. Emoji is the
+// unicode character or name:id for custom emoji, as accepted by reaction add.
+type Reaction struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+	Me    bool   `json:"me"`
+}
+
+// MessageReference points at the message a reply, crosspost or forward
+// refers to. Author is set when Discord includes the referenced message.
+type MessageReference struct {
+	MessageID string  `json:"message_id,omitempty"`
+	ChannelID string  `json:"channel_id,omitempty"`
+	GuildID   string  `json:"guild_id,omitempty"`
+	Author    *Author `json:"author,omitempty"`
+}
+
+// ThreadInfo describes the thread started from a message
+type ThreadInfo struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	MessageCount int    `json:"message_count"`
+	Archived     bool   `json:"archived"`
+}
+
+// Compact drops everything but the ID, channel, author, content and
+// timestamp, for callers that need to save tokens
+func (m *Message) Compact() {
+	*m = Message{
+		ID:         m.ID,
+		ChannelID:  m.ChannelID,
+		Author:     m.Author,
+		Content:    m.Content,
+		Timestamp:  m.Timestamp,
+		RawContent: m.RawContent,
+	}
+}
+
+// setGuild records the guild a message was posted in, "" for DMs, and
+// builds its jump URL
+func (m *Message) setGuild(guildID string) {
+	m.GuildID = guildID
+	if guildID == "" {
+		guildID = "@me"
+	}
+	m.URL = fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, m.ChannelID, m.ID)
+}
+
+// newAuthor converts a discordgo user
+func newAuthor(u *discordgo.User) Author {
+	if u == nil {
+		return Author{}
+	}
+	return Author{
+		ID:          u.ID,
+		Username:    u.Username,
+		GlobalName:  u.GlobalName,
+		DisplayName: u.DisplayName(),
+		Bot:         u.Bot,
+	}
+}
+
+// newMessage converts a discordgo message into the dca message model
+func newMessage(m *discordgo.Message) *Message {
+	msg := &Message{
+		ID:              m.ID,
+		ChannelID:       m.ChannelID,
+		Author:          newAuthor(m.Author),
+		Content:         m.Content,
+		Timestamp:       m.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
+		Type:            messageTypeToString(m.Type),
+		Pinned:          m.Pinned,
+		MentionRoles:    m.MentionRoles,
+		MentionEveryone: m.MentionEveryone,
+		Flags:           messageFlagNames(m.Flags),
+	}
+
+	if m.GuildID != "" {
+		msg.setGuild(m.GuildID)
+	}
+
+	if m.Member != nil {
+		msg.Author.setNick(m.Member.Nick)
+	}
+
+	if m.EditedTimestamp != nil {
+		msg.EditedTimestamp = m.EditedTimestamp.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	for _, a := range m.Attachments {
+		msg.Attachments = append(msg.Attachments, &Attachment{
+			ID:          a.ID,
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+			URL:         a.URL,
+			Width:       a.Width,
+			Height:      a.Height,
+		})
+	}
+
+	for _, e := range m.Embeds {
+		msg.Embeds = append(msg.Embeds, newEmbed(e))
+	}
+
+	for _, r := range m.Reactions {
+		if r.Emoji == nil {
+			continue
+		}
+		msg.Reactions = append(msg.Reactions, &Reaction{
+			Emoji: emojiAPIName(r.Emoji),
+			Count: r.Count,
+			Me:    r.Me,
+		})
+	}
+
+	if m.MessageReference != nil {
+		msg.Reference = &MessageReference{
+			MessageID: m.MessageReference.MessageID,
+			ChannelID: m.MessageReference.ChannelID,
+			GuildID:   m.MessageReference.GuildID,
+		}
+		if m.ReferencedMessage != nil && m.ReferencedMessage.Author != nil {
+			author := newAuthor(m.ReferencedMessage.Author)
+			msg.Reference.Author = &author
+		}
+	}
+
+	for _, u := range m.Mentions {
+		msg.Mentions = append(msg.Mentions, newAuthor(u))
+	}
+
+	if m.Thread != nil {
+		msg.Thread = &ThreadInfo{
+			ID:           m.Thread.ID,
+			Name:         m.Thread.Name,
+			MessageCount: m.Thread.MessageCount,
+			Archived:     m.Thread.ThreadMetadata != nil && m.Thread.ThreadMetadata.Archived,
+		}
+	}
+
+	return msg
+}
+
+func newEmbed(e *discordgo.MessageEmbed) *Embed {
+	embed := &Embed{
+		Type:        string(e.Type),
+		Title:       e.Title,
+		Description: e.Description,
+		URL:         e.URL,
+		Color:       e.Color,
+		Timestamp:   e.Timestamp,
+	}
+	if e.Author != nil {
+		embed.AuthorName = e.Author.Name
+	}
+	for _, f := range e.Fields {
+		embed.Fields = append(embed.Fields, &EmbedField{Name: f.Name, Value: f.Value, Inline: f.Inline})
+	}
+	if e.Footer != nil {
+		embed.Footer = e.Footer.Text
+	}
+	if e.Image != nil {
+		embed.ImageURL = e.Image.URL
+	}
+	if e.Thumbnail != nil {
+		embed.ThumbnailURL = e.Thumbnail.URL
+	}
+	if e.Video != nil {
+		embed.VideoURL = e.Video.URL
+	}
+	if e.Provider != nil {
+		embed.Provider = e.Provider.Name
+	}
+	return embed
+}
+
+// emojiAPIName returns the form of an emoji used in reaction endpoints
+func emojiAPIName(e *discordgo.Emoji) string {
+	if e.ID != "" {
+		return e.Name + ":" + e.ID
+	}
+	return e.Name
+}
+
+// messageFlags names the message flags worth reporting
+var messageFlags = []struct {
+	flag discordgo.MessageFlags
+	name string
+}{
+	{discordgo.MessageFlagsCrossPosted, "crossposted"},
+	{discordgo.MessageFlagsIsCrossPosted, "is_crosspost"},
+	{discordgo.MessageFlagsSuppressEmbeds, "suppress_embeds"},
+	{discordgo.MessageFlagsUrgent, "urgent"},
+	{discordgo.MessageFlagsHasThread, "has_thread"},
+	{discordgo.MessageFlagsSuppressNotifications, "silent"},
+	{discordgo.MessageFlagsIsVoiceMessage, "voice_message"},
+}
+
+// messageFlagNames lists the names of the flags set on a message
+func messageFlagNames(flags discordgo.MessageFlags) []string {
+	var names []string
+	for _, f := range messageFlags {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// messageTypeToString converts a MessageType to a readable string
+func messageTypeToString(t discordgo.MessageType) string {
+	switch t {
+	case discordgo.MessageTypeDefault:
+		return "default"
+	case discordgo.MessageTypeRecipientAdd:
+		return "recipient_add"
+	case discordgo.MessageTypeRecipientRemove:
+		return "recipient_remove"
+	case discordgo.MessageTypeCall:
+		return "call"
+	case discordgo.MessageTypeChannelNameChange:
+		return "channel_name_change"
+	case discordgo.MessageTypeChannelIconChange:
+		return "channel_icon_change"
+	case discordgo.MessageTypeChannelPinnedMessage:
+		return "pin"
+	case discordgo.MessageTypeGuildMemberJoin:
+		return "member_join"
+	case discordgo.MessageTypeUserPremiumGuildSubscription,
+		discordgo.MessageTypeUserPremiumGuildSubscriptionTierOne,
+		discordgo.MessageTypeUserPremiumGuildSubscriptionTierTwo,
+		discordgo.MessageTypeUserPremiumGuildSubscriptionTierThree:
+		return "boost"
+	case discordgo.MessageTypeChannelFollowAdd:
+		return "channel_follow_add"
+	case discordgo.MessageTypeThreadCreated:
+		return "thread_created"
+	case discordgo.MessageTypeReply:
+		return "reply"
+	case discordgo.MessageTypeChatInputCommand:
+		return "slash_command"
+	case discordgo.MessageTypeThreadStarterMessage:
+		return "thread_starter"
+	case discordgo.MessageTypeContextMenuCommand:
+		return "context_menu_command"
+	default:
+		return fmt.Sprintf("unknown_%d", t)
+	}
+}
@@ -0,0 +1,43 @@
+package discord
+
+import (
+	"fmt"
+)
+
+// ListPins lists a channel's pinned messages, most recently pinned first
+func (c *Client) ListPins(channelID string) ([]*Message, error) {
+	pinned, err := c.session.ChannelMessagesPinned(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pinned messages: %w", err)
+	}
+
+	result := make([]*Message, 0, len(pinned))
+	for _, m := range pinned {
+		result = append(result, newMessage(m))
+	}
+	if len(pinned) > 0 {
+		if guildID, ok := c.messageGuildID(pinned[0]); ok {
+			for _, m := range result {
+				m.setGuild(guildID)
+			}
+			c.applyNicks(guildID, result)
+		}
+	}
+	return result, nil
+}
+
+// PinMessage pins a message in its channel
+func (c *Client) PinMessage(channelID, messageID string) error {
+	if err := c.session.ChannelMessagePin(channelID, messageID); err != nil {
+		return fmt.Errorf("failed to pin message: %w", err)
+	}
+	return nil
+}
+
+// UnpinMessage unpins a message
+func (c *Client) UnpinMessage(channelID, messageID string) error {
+	if err := c.session.ChannelMessageUnpin(channelID, messageID); err != nil {
+		return fmt.Errorf("failed to unpin message: %w", err)
+	}
+	return nil
+}
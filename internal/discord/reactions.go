@@ -0,0 +1,164 @@
+package discord
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// reactionUsersPageSize is the most reacting users Discord returns per page
+const reactionUsersPageSize = 100
+
+var (
+	// customEmojiMarkup matches custom emoji as they appear in message
+	// content, <:name:id> or <a:name:id> when animated
+	customEmojiMarkup = regexp.MustCompile(`^<a?:(\w+):(\d+)>$`)
+
+	// customEmojiAPIName matches the name:id form reaction endpoints take
+	customEmojiAPIName = regexp.MustCompile(`^\w+:\d+$`)
+
+	// emojiShortcode matches :name: shortcodes, with or without the colons
+	emojiShortcode = regexp.MustCompile(`^:?([\w+-]+):?$`)
+)
+
+// ReactionUsers is one emoji's reactions on a message with the users who
+// reacted. HasMore is set when Users was cut short by the listing limit.
+type ReactionUsers struct {
+	Emoji   string    `json:"emoji"`
+	Count   int       `json:"count"`
+	Me      bool      `json:"me"`
+	Users   []*Author `json:"users"`
+	HasMore bool      `json:"has_more,omitempty"`
+}
+
+// ReactionListOptions narrows a reaction listing to one emoji and caps the
+// users fetched per emoji. A zero Limit fetches every user.
+type ReactionListOptions struct {
+	Emoji string
+	Limit int
+}
+
+// ResolveEmoji turns an emoji as users type it into the form reaction
+// endpoints take. Besides unicode emoji it accepts shortcodes such as
+// :thumbsup:, custom emoji markup and the names of the channel's guild
+// emoji (:partyparrot:).
+func (c *Client) ResolveEmoji(channelID, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if m := customEmojiMarkup.FindStringSubmatch(ref); m != nil {
+		return m[1] + ":" + m[2], nil
+	}
+	if customEmojiAPIName.MatchString(ref) {
+		return ref, nil
+	}
+
+	m := emojiShortcode.FindStringSubmatch(ref)
+	if m == nil {
+		// Anything else is taken to be a unicode emoji
+		return ref, nil
+	}
+	name := m[1]
+	if emoji, ok := emojiShortcodes[strings.ToLower(name)]; ok {
+		return emoji, nil
+	}
+
+	guildID, _ := c.channelGuildID(channelID)
+	if guildID == "" {
+		return "", fmt.Errorf("unknown emoji :%s:", name)
+	}
+	emojis, err := c.session.GuildEmojis(guildID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get server emoji: %w", err)
+	}
+	var match *discordgo.Emoji
+	for _, e := range emojis {
+		if e.Name == name {
+			match = e
+			break
+		}
+		if match == nil && strings.EqualFold(e.Name, name) {
+			match = e
+		}
+	}
+	if match == nil {
+		return "", fmt.Errorf("unknown emoji :%s:, not a standard shortcode or an emoji of this server", name)
+	}
+	return emojiAPIName(match), nil
+}
+
+// ListReactions lists a message's reactions with the users behind each,
+// paging through the users in the order they reacted
+func (c *Client) ListReactions(channelID, messageID string, opts ReactionListOptions) ([]*ReactionUsers, error) {
+	msg, err := c.session.ChannelMessage(channelID, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	emoji := ""
+	if opts.Emoji != "" {
+		if emoji, err = c.ResolveEmoji(channelID, opts.Emoji); err != nil {
+			return nil, err
+		}
+	}
+
+	guildID, _ := c.channelGuildID(channelID)
+	result := make([]*ReactionUsers, 0, len(msg.Reactions))
+	for _, r := range msg.Reactions {
+		if r.Emoji == nil {
+			continue
+		}
+		name := emojiAPIName(r.Emoji)
+		if emoji != "" && name != emoji {
+			continue
+		}
+
+		users, err := c.reactionUsers(channelID, messageID, name, opts.Limit)
+		if err != nil {
+			return nil, err
+		}
+		reaction := &ReactionUsers{
+			Emoji:   name,
+			Count:   r.Count,
+			Me:      r.Me,
+			Users:   make([]*Author, 0, len(users)),
+			HasMore: len(users) < r.Count,
+		}
+		for _, u := range users {
+			a := newAuthor(u)
+			if guildID != "" {
+				a.setNick(c.memberNick(guildID, u.ID))
+			}
+			reaction.Users = append(reaction.Users, &a)
+		}
+		result = append(result, reaction)
+	}
+
+	if emoji != "" && len(result) == 0 {
+		return nil, fmt.Errorf("message %s has no %s reactions", messageID, emoji)
+	}
+	return result, nil
+}
+
+// reactionUsers pages through the users who reacted with an emoji until
+// limit users are found (0 for all)
+func (c *Client) reactionUsers(channelID, messageID, emoji string, limit int) ([]*discordgo.User, error) {
+	var result []*discordgo.User
+	after := ""
+	for {
+		pageSize := reactionUsersPageSize
+		if limit > 0 && limit-len(result) < pageSize {
+			pageSize = limit - len(result)
+		}
+		page, err := c.session.MessageReactions(channelID, messageID, emoji, pageSize, "", after)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s reactions: %w", emoji, err)
+		}
+		result = append(result, page...)
+
+		if len(page) < pageSize || (limit > 0 && len(result) >= limit) {
+			return result, nil
+		}
+		after = page[len(page)-1].ID
+	}
+}
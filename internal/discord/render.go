@@ -0,0 +1,157 @@
+package discord
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// markupPattern matches the Discord markup Renderer resolves: user, role and
+// channel mentions, custom emoji and timestamps
+var markupPattern = regexp.MustCompile(`<(@!?|@&|#)(\d+)>|<a?:(\w+):\d+>|<t:(-?\d+)(?::[tTdDfFR])?>`)
+
+// Renderer turns mention and emoji markup in message content into readable
+// text. Lookups are cached, so one Renderer should be shared by all
+// messages of a command.
+type Renderer struct {
+	api      API
+	users    map[string]string // guild ID/user ID -> display name
+	channels map[string]*Channel
+	roles    map[string]map[string]string // guild ID -> role ID -> name
+}
+
+// NewRenderer creates a Renderer that resolves names through api
+func NewRenderer(api API) *Renderer {
+	return &Renderer{
+		api:      api,
+		users:    make(map[string]string),
+		channels: make(map[string]*Channel),
+		roles:    make(map[string]map[string]string),
+	}
+}
+
+// Render resolves the markup in m.Content. When anything changed, the
+// original content is kept in m.RawContent.
+func (r *Renderer) Render(m *Message) {
+	// Mentions carry the user but not their guild nickname, so they only
+	// spare lookups outside guilds
+	for _, u := range m.Mentions {
+		r.users["/"+u.ID] = authorName(&u)
+	}
+
+	rendered := r.renderText(m.ChannelID, m.Content)
+	if rendered != m.Content {
+		m.RawContent = m.Content
+		m.Content = rendered
+	}
+}
+
+// renderText resolves markup in text posted in channelID. Mentions that
+// cannot be resolved are left as they are.
+func (r *Renderer) renderText(channelID, text string) string {
+	return markupPattern.ReplaceAllStringFunc(text, func(token string) string {
+		sub := markupPattern.FindStringSubmatch(token)
+		switch {
+		case sub[3] != "":
+			return ":" + sub[3] + ":"
+		case sub[4] != "":
+			sec, err := strconv.ParseInt(sub[4], 10, 64)
+			if err != nil {
+				return token
+			}
+			return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+		}
+
+		var name string
+		switch sub[1] {
+		case "@", "@!":
+			name = r.user(r.guildOf(channelID), sub[2])
+		case "@&":
+			if ch := r.channel(channelID); ch != nil {
+				name = r.role(ch.GuildID, sub[2])
+			}
+		case "#":
+			if ch := r.channel(sub[2]); ch != nil {
+				name = ch.Name
+			}
+		}
+		if name == "" {
+			return token
+		}
+		if sub[1] == "#" {
+			return "#" + name
+		}
+		return "@" + name
+	})
+}
+
+// user returns the name a user goes by in a guild, or outside any guild
+// when guildID is empty
+func (r *Renderer) user(guildID, id string) string {
+	key := guildID + "/" + id
+	if name, ok := r.users[key]; ok {
+		return name
+	}
+	var u *Author
+	var err error
+	if guildID != "" {
+		u, err = r.api.GetMember(guildID, id)
+	} else {
+		u, err = r.api.GetUser(id)
+	}
+	var name string
+	if err == nil {
+		name = authorName(u)
+	}
+	r.users[key] = name
+	return name
+}
+
+// authorName is the name Discord shows for a user: their display name,
+// else their username
+func authorName(u *Author) string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Username
+}
+
+// guildOf returns the guild of a channel, "" for DMs and unknown channels
+func (r *Renderer) guildOf(channelID string) string {
+	if channelID == "" {
+		return ""
+	}
+	if ch := r.channel(channelID); ch != nil {
+		return ch.GuildID
+	}
+	return ""
+}
+
+func (r *Renderer) channel(id string) *Channel {
+	if ch, ok := r.channels[id]; ok {
+		return ch
+	}
+	ch, err := r.api.GetChannel(id)
+	if err != nil {
+		ch = nil
+	}
+	r.channels[id] = ch
+	return ch
+}
+
+func (r *Renderer) role(guildID, id string) string {
+	if guildID == "" {
+		return ""
+	}
+	roles, ok := r.roles[guildID]
+	if !ok {
+		roles = make(map[string]string)
+		if list, err := r.api.ListRoles(guildID); err == nil {
+			for _, role := range list {
+				roles[role.ID] = role.Name
+			}
+		}
+		r.roles[guildID] = roles
+	}
+	return roles[id]
+}
@@ -0,0 +1,106 @@
+package discord
+
+import (
+	"errors"
+	"testing"
+)
+
+// lookupStub answers the lookups Renderer makes and counts them. Other API
+// methods are left nil and panic if called.
+type lookupStub struct {
+	API
+	calls map[string]int
+}
+
+func (s *lookupStub) GetUser(userID string) (*Author, error) {
+	s.calls["user "+userID]++
+	if userID == "11" {
+		return &Author{ID: "11", Username: "alice"}, nil
+	}
+	return nil, errors.New("unknown user")
+}
+
+func (s *lookupStub) GetMember(guildID, userID string) (*Author, error) {
+	s.calls["member "+guildID+"/"+userID]++
+	switch userID {
+	case "11":
+		return &Author{ID: "11", Username: "alice"}, nil
+	case "12":
+		return &Author{ID: "12", Username: "bob", GlobalName: "Bobby", DisplayName: "Bobby"}, nil
+	case "13":
+		return &Author{ID: "13", Username: "jdoe_1987", Nick: "Jane", DisplayName: "Jane"}, nil
+	}
+	return nil, errors.New("unknown member")
+}
+
+func (s *lookupStub) GetChannel(channelID string) (*Channel, error) {
+	s.calls["channel "+channelID]++
+	switch channelID {
+	case "20":
+		return &Channel{ID: "20", Name: "general", GuildID: "1"}, nil
+	case "21":
+		return &Channel{ID: "21", Name: "deploys", GuildID: "1"}, nil
+	case "22":
+		return &Channel{ID: "22", Type: "dm"}, nil
+	}
+	return nil, errors.New("unknown channel")
+}
+
+func (s *lookupStub) ListRoles(guildID string) ([]*Role, error) {
+	s.calls["roles "+guildID]++
+	return []*Role{{ID: "30", Name: "moderators"}}, nil
+}
+
+func TestRenderer(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"user", "hi <@11> and <@!11>", "hi @alice and @alice"},
+		{"display name", "ping <@12>", "ping @Bobby"},
+		{"nickname", "thanks <@13>", "thanks @Jane"},
+		{"channel", "see <#21>", "see #deploys"},
+		{"role", "<@&30> please look", "@moderators please look"},
+		{"custom emoji", "nice <:party:777> <a:spin:778>", "nice :party: :spin:"},
+		{"timestamp", "at <t:1771927200:R>", "at 2026-02-24T10:00:00Z"},
+		{"unknown", "<@99> in <#99> for <@&99>", "<@99> in <#99> for <@&99>"},
+		{"plain", "nothing to do", "nothing to do"},
+	}
+
+	stub := &lookupStub{calls: make(map[string]int)}
+	r := NewRenderer(stub)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Message{
+				ChannelID: "20",
+				Content:   tt.content,
+				Mentions:  []Author{{ID: "12", Username: "bob"}},
+			}
+			r.Render(m)
+			if m.Content != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.content, m.Content, tt.want)
+			}
+			wantRaw := tt.content
+			if tt.want == tt.content {
+				wantRaw = ""
+			}
+			if m.RawContent != wantRaw {
+				t.Errorf("RawContent = %q, want %q", m.RawContent, wantRaw)
+			}
+		})
+	}
+
+	for _, key := range []string{"member 1/11", "member 1/13", "channel 20", "roles 1", "member 1/99"} {
+		if stub.calls[key] != 1 {
+			t.Errorf("expected one %s lookup, got %d", key, stub.calls[key])
+		}
+	}
+
+	// Outside guilds, the users a message mentions need no lookup
+	m := &Message{ChannelID: "22", Content: "hi <@12>", Mentions: []Author{{ID: "12", Username: "bob", DisplayName: "Bobby"}}}
+	r.Render(m)
+	if m.Content != "hi @Bobby" || stub.calls["user 12"] != 0 {
+		t.Errorf("expected the mentioned user's name without a lookup, got %q after %d lookups", m.Content, stub.calls["user 12"])
+	}
+}
@@ -3,6 +3,7 @@ package discord
 import (
 	"net/url"
 	"testing"
+	"time"
 )
 
 func TestParseSearchResponse(t *testing.T) {
@@ -99,7 +100,7 @@ func TestBuildSearchParams(t *testing.T) {
 			expected: map[string]string{
 				"content": "headless",
 			},
-			absent: []string{"author_id", "channel_id", "has", "offset", "sort_by", "sort_order"},
+			absent: []string{"author_id", "channel_id", "has", "offset", "sort_by", "sort_order", "pinned", "min_id", "max_id"},
 		},
 		{
 			name: "all options",
@@ -111,6 +112,7 @@ func TestBuildSearchParams(t *testing.T) {
 				Offset:    25,
 				SortBy:    "timestamp",
 				SortOrder: "desc",
+				Pinned:    true,
 			},
 			expected: map[string]string{
 				"content":    "test query",
@@ -120,6 +122,19 @@ func TestBuildSearchParams(t *testing.T) {
 				"offset":     "25",
 				"sort_by":    "timestamp",
 				"sort_order": "desc",
+				"pinned":     "true",
+			},
+		},
+		{
+			name: "time window",
+			opts: SearchOptions{
+				Content: "test",
+				Since:   time.Date(2015, 1, 1, 0, 0, 1, 0, time.UTC),
+				Until:   time.Date(2015, 1, 1, 0, 0, 2, 0, time.UTC),
+			},
+			expected: map[string]string{
+				"min_id": "4194303999",
+				"max_id": "8388608000",
 			},
 		},
 		{
@@ -0,0 +1,251 @@
+package discord
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MaxFiles is the most attachments Discord accepts on one message
+const MaxFiles = 10
+
+// OutgoingMessage is a message to send. Content may be empty when files
+// or embeds are attached. A Nonce makes Discord return the earlier message
+// instead of posting a duplicate when the same nonce is sent again shortly
+// after.
+//
+// Mentions limits who the message notifies; nil leaves it to Discord,
+// which pings everyone mentioned. Silent posts without push or desktop
+// notifications, and SuppressEmbeds hides link previews.
+type OutgoingMessage struct {
+	Content        string
+	Files          []*File
+	Embeds         []*Embed
+	Nonce          string
+	Mentions       *MentionPolicy
+	Silent         bool
+	SuppressEmbeds bool
+}
+
+// File is an attachment to upload with a message
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// MaxNonceLength is the longest nonce Discord accepts
+const MaxNonceLength = 25
+
+// Fingerprint identifies the message's content, files and embeds, for
+// spotting identical sends
+func (o *OutgoingMessage) Fingerprint() string {
+	type file struct {
+		Name string `json:"name"`
+		Hash string `json:"hash"`
+	}
+	v := struct {
+		Content string                    `json:"content"`
+		Files   []file                    `json:"files"`
+		Embeds  []*discordgo.MessageEmbed `json:"embeds"`
+	}{Content: o.Content}
+	for _, e := range o.Embeds {
+		v.Embeds = append(v.Embeds, e.messageEmbed())
+	}
+	for _, f := range o.Files {
+		sum := sha256.Sum256(f.Data)
+		v.Files = append(v.Files, file{Name: f.Name, Hash: hex.EncodeToString(sum[:])})
+	}
+
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// messageCreate is the request body for creating a message. discordgo's
+// MessageSend has no nonce fields, so they are added here.
+type messageCreate struct {
+	*discordgo.MessageSend
+	Nonce        string `json:"nonce,omitempty"`
+	EnforceNonce bool   `json:"enforce_nonce,omitempty"`
+}
+
+// createMessage posts a message, with a reference when it is a reply
+func (c *Client) createMessage(channelID string, out *OutgoingMessage, ref *discordgo.MessageReference) (*discordgo.Message, error) {
+	send := out.messageSend()
+	send.Reference = ref
+	data := &messageCreate{MessageSend: send, Nonce: out.Nonce, EnforceNonce: out.Nonce != ""}
+
+	endpoint := discordgo.EndpointChannelMessages(channelID)
+	var response []byte
+	var err error
+	if len(send.Files) > 0 {
+		contentType, body, encodeErr := discordgo.MultipartBodyWithJSON(data, send.Files)
+		if encodeErr != nil {
+			return nil, encodeErr
+		}
+		response, err = c.session.RequestRaw("POST", endpoint, contentType, body, endpoint, 0)
+	} else {
+		response, err = c.session.RequestWithBucketID("POST", endpoint, data, endpoint)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var msg discordgo.Message
+	if err := json.Unmarshal(response, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// messageSend builds the discordgo request for a message
+func (o *OutgoingMessage) messageSend() *discordgo.MessageSend {
+	send := &discordgo.MessageSend{Content: o.Content}
+	if o.Mentions != nil {
+		send.AllowedMentions = o.Mentions.allowedMentions()
+	}
+	if o.Silent {
+		send.Flags |= discordgo.MessageFlagsSuppressNotifications
+	}
+	if o.SuppressEmbeds {
+		send.Flags |= discordgo.MessageFlagsSuppressEmbeds
+	}
+	for _, f := range o.Files {
+		send.Files = append(send.Files, &discordgo.File{
+			Name:        f.Name,
+			ContentType: f.ContentType,
+			Reader:      bytes.NewReader(f.Data),
+		})
+	}
+	for _, e := range o.Embeds {
+		send.Embeds = append(send.Embeds, e.messageEmbed())
+	}
+	return send
+}
+
+// MaxContentLength is the most characters Discord accepts in one message
+const MaxContentLength = 2000
+
+// Split breaks a message whose content is too long for Discord into parts
+// that are sent in order. Files and embeds go with the last part.
+func (o *OutgoingMessage) Split() []*OutgoingMessage {
+	chunks := SplitContent(o.Content, MaxContentLength)
+	if len(chunks) <= 1 {
+		return []*OutgoingMessage{o}
+	}
+
+	parts := make([]*OutgoingMessage, len(chunks))
+	for i, chunk := range chunks {
+		parts[i] = &OutgoingMessage{
+			Content:        chunk,
+			Mentions:       o.Mentions,
+			Silent:         o.Silent,
+			SuppressEmbeds: o.SuppressEmbeds,
+		}
+	}
+	parts[len(parts)-1].Files = o.Files
+	parts[len(parts)-1].Embeds = o.Embeds
+	return parts
+}
+
+// codeFence is the marker that opens and closes a code block
+const codeFence = "```"
+
+// SplitContent breaks text into chunks of at most limit characters,
+// preferring paragraph, then line, then word boundaries. A code block cut
+// in two is closed at the end of one chunk and re-opened, with its language,
+// at the start of the next, unless its opening line is too long to repeat.
+func SplitContent(text string, limit int) []string {
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	var chunks []string
+	rest := []rune(text)
+	reopen := ""
+	for len(rest) > 0 {
+		prefix := []rune(reopen)
+		if len(prefix)+len(rest) <= limit {
+			chunks = append(chunks, string(prefix)+string(rest))
+			break
+		}
+
+		chunk, cut, fence := nextChunk(prefix, rest, limit-len(prefix))
+		if fence != "" {
+			// Make room for closing the code block that is open at the cut
+			chunk, cut, fence = nextChunk(prefix, rest, limit-len(prefix)-len("\n"+codeFence))
+		}
+		if fence != "" {
+			chunk += "\n" + codeFence
+		}
+		if reopen != "" && utf8.RuneCountInString(chunk) > limit {
+			// The re-opened code block left no room; continue it unfenced
+			reopen = ""
+			continue
+		}
+		rest = []rune(strings.TrimLeft(string(rest[cut:]), "\n"))
+
+		// Repeating a long opening line would crowd out the next chunk
+		reopen = ""
+		if fence != "" && utf8.RuneCountInString(fence) < limit/2 {
+			reopen = fence + "\n"
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// nextChunk cuts the next chunk of at most budget characters from text and
+// returns it with the opening line of a code block left open at the cut
+func nextChunk(prefix, text []rune, budget int) (chunk string, cut int, fence string) {
+	cut = splitPoint(text, budget)
+	chunk = strings.TrimRight(string(prefix)+string(text[:cut]), " \n")
+	fence, _ = openFence(chunk)
+	return chunk, cut, fence
+}
+
+// splitPoint picks where to cut text so the first part has at most budget
+// characters
+func splitPoint(text []rune, budget int) int {
+	if budget < 1 {
+		budget = 1
+	}
+	window := string(text[:budget])
+
+	// A boundary in the first half would leave a needlessly short chunk
+	for _, sep := range []string{"\n\n", "\n"} {
+		if i := strings.LastIndex(window, sep); i > 0 && utf8.RuneCountInString(window[:i]) > budget/2 {
+			return utf8.RuneCountInString(window[:i]) + len(sep)
+		}
+	}
+	for _, sep := range []string{"\n", " "} {
+		if i := strings.LastIndex(window, sep); i > 0 {
+			return utf8.RuneCountInString(window[:i]) + len(sep)
+		}
+	}
+	return budget
+}
+
+// openFence reports whether text ends inside a code block, and the line
+// that opened it
+func openFence(text string) (fence string, open bool) {
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		// ```inline``` on one line neither opens nor closes a block
+		if !strings.HasPrefix(trimmed, codeFence) || strings.Count(trimmed, codeFence) > 1 {
+			continue
+		}
+		if open {
+			fence, open = "", false
+		} else {
+			fence, open = trimmed, true
+		}
+	}
+	return fence, open
+}
@@ -0,0 +1,127 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitContent(t *testing.T) {
+	t.Run("short", func(t *testing.T) {
+		got := SplitContent("hello", 20)
+		if len(got) != 1 || got[0] != "hello" {
+			t.Errorf("got %q", got)
+		}
+	})
+
+	t.Run("paragraphs", func(t *testing.T) {
+		text := "first paragraph\n\nsecond paragraph"
+		got := SplitContent(text, 30)
+		want := []string{"first paragraph", "second paragraph"}
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("lines", func(t *testing.T) {
+		got := SplitContent("one line here\ntwo line here\nthree", 30)
+		want := []string{"one line here\ntwo line here", "three"}
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("words", func(t *testing.T) {
+		got := SplitContent("alpha beta gamma delta epsilon", 16)
+		for _, c := range got {
+			if utf8.RuneCountInString(c) > 16 || strings.HasPrefix(c, " ") || strings.HasSuffix(c, " ") {
+				t.Errorf("bad chunk %q in %q", c, got)
+			}
+		}
+		if strings.Join(got, " ") != "alpha beta gamma delta epsilon" {
+			t.Errorf("words lost: %q", got)
+		}
+	})
+
+	t.Run("hard cut", func(t *testing.T) {
+		got := SplitContent(strings.Repeat("é", 25), 10)
+		if len(got) != 3 || got[0] != strings.Repeat("é", 10) {
+			t.Errorf("got %q", got)
+		}
+	})
+
+	t.Run("code fence", func(t *testing.T) {
+		var b strings.Builder
+		b.WriteString("Logs:\n```go\n")
+		for i := 0; i < 40; i++ {
+			b.WriteString("fmt.Println(i)\n")
+		}
+		b.WriteString("```\ndone")
+
+		got := SplitContent(b.String(), 200)
+		if len(got) < 3 {
+			t.Fatalf("expected several chunks, got %d", len(got))
+		}
+		for i, c := range got {
+			if n := utf8.RuneCountInString(c); n > 200 {
+				t.Errorf("chunk %d is %d characters", i, n)
+			}
+			if strings.Count(c, "```")%2 != 0 {
+				t.Errorf("chunk %d has an unbalanced fence: %q", i, c)
+			}
+			if i > 0 && i < len(got)-1 && !strings.HasPrefix(c, "```go\n") {
+				t.Errorf("chunk %d does not re-open the fence: %q", i, c)
+			}
+		}
+		if !strings.HasSuffix(got[len(got)-1], "```\ndone") {
+			t.Errorf("unexpected last chunk %q", got[len(got)-1])
+		}
+	})
+
+	t.Run("long fence line", func(t *testing.T) {
+		var b strings.Builder
+		b.WriteString("```" + strings.Repeat("x", 1990) + "\n")
+		for i := 0; i < 300; i++ {
+			b.WriteString("fmt.Println(i)\n")
+		}
+		b.WriteString("```")
+
+		got := SplitContent(b.String(), MaxContentLength)
+		if len(got) < 3 {
+			t.Fatalf("expected several chunks, got %d", len(got))
+		}
+		lines := 0
+		for i, c := range got {
+			if n := utf8.RuneCountInString(c); n > MaxContentLength {
+				t.Errorf("chunk %d is %d characters", i, n)
+			}
+			lines += strings.Count(c, "fmt.Println(i)")
+		}
+		if lines != 300 {
+			t.Errorf("expected every line of code, got %d", lines)
+		}
+	})
+}
+
+func TestOutgoingMessageSplit(t *testing.T) {
+	out := &OutgoingMessage{
+		Content: strings.Repeat("word ", 900),
+		Files:   []*File{{Name: "a.txt"}},
+		Embeds:  []*Embed{{Title: "t"}},
+	}
+	parts := out.Split()
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got %d", len(parts))
+	}
+	for i, p := range parts {
+		last := i == len(parts)-1
+		if (len(p.Files) > 0) != last || (len(p.Embeds) > 0) != last {
+			t.Errorf("part %d: files and embeds belong on the last part only", i)
+		}
+	}
+
+	short := &OutgoingMessage{Content: "hi"}
+	if parts := short.Split(); len(parts) != 1 || parts[0] != short {
+		t.Error("short messages should not be split")
+	}
+}
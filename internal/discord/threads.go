@@ -0,0 +1,271 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/snowflake"
+)
+
+// threadSearchPageSize is the most threads Discord returns per search page
+const threadSearchPageSize = 25
+
+// Thread is a thread in a text, announcement or forum channel. Joined
+// reports whether the current user is a member of it.
+type Thread struct {
+	ID                  string   `json:"id"`
+	Name                string   `json:"name"`
+	Type                string   `json:"type"`
+	ParentID            string   `json:"parent_id"`
+	GuildID             string   `json:"guild_id,omitempty"`
+	OwnerID             string   `json:"owner_id,omitempty"`
+	MessageCount        int      `json:"message_count"`
+	MemberCount         int      `json:"member_count,omitempty"`
+	CreatedAt           string   `json:"created_at"`
+	LastMessageID       string   `json:"last_message_id,omitempty"`
+	Archived            bool     `json:"archived"`
+	Locked              bool     `json:"locked,omitempty"`
+	AutoArchiveDuration int      `json:"auto_archive_duration,omitempty"`
+	Joined              bool     `json:"joined"`
+	AppliedTags         []string `json:"applied_tags,omitempty"`
+}
+
+// ThreadOptions describes a thread to start. AutoArchiveDuration is in
+// minutes (60, 1440, 4320 or 10080); zero uses the channel default.
+// Private only applies to threads not started from a message.
+type ThreadOptions struct {
+	Name                string
+	AutoArchiveDuration int
+	Private             bool
+}
+
+// ThreadUpdate changes a thread's state; nil fields are left alone
+type ThreadUpdate struct {
+	Archived *bool
+	Locked   *bool
+}
+
+func newThread(ch *discordgo.Channel) *Thread {
+	t := &Thread{
+		ID:            ch.ID,
+		Name:          ch.Name,
+		Type:          channelTypeToString(ch.Type),
+		ParentID:      ch.ParentID,
+		GuildID:       ch.GuildID,
+		OwnerID:       ch.OwnerID,
+		MessageCount:  ch.MessageCount,
+		MemberCount:   ch.MemberCount,
+		CreatedAt:     snowflake.Timestamp(ch.ID),
+		LastMessageID: ch.LastMessageID,
+		Joined:        ch.Member != nil,
+		AppliedTags:   ch.AppliedTags,
+	}
+	if md := ch.ThreadMetadata; md != nil {
+		t.Archived = md.Archived
+		t.Locked = md.Locked
+		t.AutoArchiveDuration = md.AutoArchiveDuration
+	}
+	return t
+}
+
+// newThreads converts a thread listing, marking the threads the current
+// user is a member of
+func newThreads(channels []*discordgo.Channel, members []*discordgo.ThreadMember) []*Thread {
+	joined := make(map[string]bool, len(members))
+	for _, m := range members {
+		joined[m.ID] = true
+	}
+	threads := make([]*Thread, 0, len(channels))
+	for _, ch := range channels {
+		t := newThread(ch)
+		t.Joined = t.Joined || joined[ch.ID]
+		threads = append(threads, t)
+	}
+	return threads
+}
+
+// LastActivityID is the ID of a thread's newest message, or of the thread
+// itself while it has none
+func (t *Thread) LastActivityID() string {
+	if t.LastMessageID != "" {
+		return t.LastMessageID
+	}
+	return t.ID
+}
+
+// GetThread fetches a thread, failing if the channel is not one
+func (c *Client) GetThread(threadID string) (*Thread, error) {
+	ch, err := c.session.Channel(threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get thread: %w", err)
+	}
+	if !ch.IsThread() {
+		return nil, fmt.Errorf("channel %s is not a thread", threadID)
+	}
+	return newThread(ch), nil
+}
+
+// StartThread starts a thread from a message, or a standalone thread in
+// the channel when messageID is empty
+func (c *Client) StartThread(channelID, messageID string, opts ThreadOptions) (*Thread, error) {
+	data := &discordgo.ThreadStart{
+		Name:                opts.Name,
+		AutoArchiveDuration: opts.AutoArchiveDuration,
+	}
+
+	var ch *discordgo.Channel
+	var err error
+	if messageID != "" {
+		ch, err = c.session.MessageThreadStartComplex(channelID, messageID, data)
+	} else {
+		data.Type = discordgo.ChannelTypeGuildPublicThread
+		if opts.Private {
+			data.Type = discordgo.ChannelTypeGuildPrivateThread
+			data.Invitable = true
+		}
+		ch, err = c.session.ThreadStartComplex(channelID, data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to start thread: %w", err)
+	}
+	return newThread(ch), nil
+}
+
+// JoinThread adds the current user to a thread
+func (c *Client) JoinThread(threadID string) error {
+	if err := c.session.ThreadJoin(threadID); err != nil {
+		return fmt.Errorf("failed to join thread: %w", err)
+	}
+	return nil
+}
+
+// LeaveThread removes the current user from a thread
+func (c *Client) LeaveThread(threadID string) error {
+	if err := c.session.ThreadLeave(threadID); err != nil {
+		return fmt.Errorf("failed to leave thread: %w", err)
+	}
+	return nil
+}
+
+// UpdateThread archives, unarchives, locks or unlocks a thread
+func (c *Client) UpdateThread(threadID string, update ThreadUpdate) (*Thread, error) {
+	ch, err := c.session.ChannelEditComplex(threadID, &discordgo.ChannelEdit{
+		Archived: update.Archived,
+		Locked:   update.Locked,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update thread: %w", err)
+	}
+	return newThread(ch), nil
+}
+
+// ListActiveThreads lists the active threads of a guild, or of one of its
+// channels when channelID is set. The guild's active-threads endpoint is
+// tried first; when Discord refuses it for a user token, each channel's
+// thread search is used instead.
+func (c *Client) ListActiveThreads(guildID, channelID string) ([]*Thread, error) {
+	list, err := c.session.GuildThreadsActive(guildID)
+	if err == nil {
+		result := make([]*Thread, 0, len(list.Threads))
+		for _, t := range newThreads(list.Threads, list.Members) {
+			if channelID == "" || t.ParentID == channelID {
+				result = append(result, t)
+			}
+		}
+		return result, nil
+	}
+
+	if !accessDenied(err) {
+		return nil, fmt.Errorf("failed to list active threads: %w", err)
+	}
+
+	parents := []string{channelID}
+	if channelID == "" {
+		channels, err := c.session.GuildChannels(guildID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list channels: %w", err)
+		}
+		parents = parents[:0]
+		for _, ch := range channels {
+			switch ch.Type {
+			case discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews, discordgo.ChannelTypeGuildForum:
+				parents = append(parents, ch.ID)
+			}
+		}
+	}
+
+	result := make([]*Thread, 0)
+	for _, parentID := range parents {
+		threads, err := c.searchThreads(parentID, url.Values{"archived": {"false"}}, 0)
+		if err != nil {
+			// The guild's channel list includes channels the user cannot view
+			if channelID == "" && accessDenied(err) {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, threads...)
+	}
+	return result, nil
+}
+
+// accessDenied reports whether a request failed with 403 or 404, as it does
+// for endpoints and channels the user has no access to
+func accessDenied(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Response != nil &&
+		(restErr.Response.StatusCode == http.StatusForbidden || restErr.Response.StatusCode == http.StatusNotFound)
+}
+
+// threadSearchResponse is a page of the thread search used by Discord's
+// thread browser
+type threadSearchResponse struct {
+	Threads      []*discordgo.Channel      `json:"threads"`
+	Members      []*discordgo.ThreadMember `json:"members"`
+	HasMore      bool                      `json:"has_more"`
+	TotalResults int                       `json:"total_results"`
+}
+
+// searchThreads pages through a channel's thread search, newest activity
+// first, until limit threads are found (0 for all). query narrows the
+// search, e.g. archived=false.
+func (c *Client) searchThreads(channelID string, query url.Values, limit int) ([]*Thread, error) {
+	var result []*Thread
+	for offset := 0; ; {
+		q := url.Values{
+			"sort_by":    {"last_message_time"},
+			"sort_order": {"desc"},
+			"limit":      {strconv.Itoa(threadSearchPageSize)},
+			"offset":     {strconv.Itoa(offset)},
+		}
+		for k, v := range query {
+			q[k] = v
+		}
+		endpoint := discordgo.EndpointChannelThreads(channelID) + "/search?" + q.Encode()
+
+		body, err := c.session.RequestWithBucketID("GET", endpoint, nil, discordgo.EndpointChannelThreads(channelID)+"/search")
+		if err != nil {
+			return nil, fmt.Errorf("failed to search threads: %w", err)
+		}
+		var page threadSearchResponse
+		if err := discordgo.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse thread search: %w", err)
+		}
+
+		for _, t := range newThreads(page.Threads, page.Members) {
+			result = append(result, t)
+			if limit > 0 && len(result) >= limit {
+				return result, nil
+			}
+		}
+
+		if !page.HasMore || len(page.Threads) == 0 {
+			return result, nil
+		}
+		offset += len(page.Threads)
+	}
+}
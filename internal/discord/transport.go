@@ -0,0 +1,106 @@
+package discord
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultTimeout is the request timeout used when Options.Timeout is zero
+const DefaultTimeout = 20 * time.Second
+
+// Options configures how the client reaches the Discord API
+type Options struct {
+	// BaseURL replaces discordgo's https://discord.com/api/v9/ prefix, e.g.
+	// to point dca at a local mock or a recording proxy
+	BaseURL string
+	// ProxyURL is an HTTP(S) proxy for all requests. When empty the
+	// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables apply.
+	ProxyURL string
+	// CABundle is a PEM file of extra root certificates to trust
+	CABundle string
+	// Timeout bounds each HTTP request
+	Timeout time.Duration
+}
+
+// newHTTPClient builds the HTTP client used for every REST request, both
+// discordgo's own calls and the raw RequestWithBucketID ones
+func newHTTPClient(opts Options) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.ProxyURL != "" {
+		proxy, err := url.Parse(opts.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if opts.CABundle != "" {
+		pem, err := os.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", opts.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	client := &http.Client{Timeout: timeout, Transport: transport}
+	if opts.BaseURL != "" {
+		base, err := url.Parse(opts.BaseURL)
+		if err != nil || base.Scheme == "" || base.Host == "" {
+			return nil, fmt.Errorf("invalid API base URL %q", opts.BaseURL)
+		}
+		client.Transport = &baseURLTransport{
+			from: discordgo.EndpointAPI,
+			to:   strings.TrimSuffix(opts.BaseURL, "/") + "/",
+			next: transport,
+		}
+	}
+
+	return client, nil
+}
+
+// baseURLTransport rewrites requests for the Discord API prefix to another
+// base URL. Rewriting at the transport catches every endpoint discordgo
+// builds, not just the ones dca constructs itself.
+type baseURLTransport struct {
+	from string
+	to   string
+	next http.RoundTripper
+}
+
+func (t *baseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	orig := req.URL.String()
+	if !strings.HasPrefix(orig, t.from) {
+		return t.next.RoundTrip(req)
+	}
+
+	target, err := url.Parse(t.to + strings.TrimPrefix(orig, t.from))
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewrite %s: %w", orig, err)
+	}
+
+	r := req.Clone(req.Context())
+	r.URL = target
+	r.Host = ""
+	return t.next.RoundTrip(r)
+}
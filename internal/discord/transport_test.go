@@ -0,0 +1,146 @@
+package discord
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer answers every request with an empty guild list and
+// remembers the request URIs it saw
+type recordingServer struct {
+	mu   sync.Mutex
+	uris []string
+}
+
+func (rs *recordingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rs.mu.Lock()
+	rs.uris = append(rs.uris, r.RequestURI)
+	rs.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte("[]"))
+}
+
+func (rs *recordingServer) last() string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if len(rs.uris) == 0 {
+		return ""
+	}
+	return rs.uris[len(rs.uris)-1]
+}
+
+func TestBaseURLRewritesDiscordgoAndRawRequests(t *testing.T) {
+	rs := &recordingServer{}
+	srv := httptest.NewServer(rs)
+	defer srv.Close()
+
+	c, err := NewWithOptions("token", Options{BaseURL: srv.URL + "/mock/api"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := c.ListGuilds(); err != nil {
+		t.Fatalf("discordgo request failed: %v", err)
+	}
+	if got := rs.last(); got != "/mock/api/users/@me/guilds?limit=100" {
+		t.Errorf("unexpected discordgo request URI %q", got)
+	}
+
+	if _, err := c.ListDMChannels(0, true); err != nil {
+		t.Fatalf("raw request failed: %v", err)
+	}
+	if got := rs.last(); got != "/mock/api/users/@me/channels" {
+		t.Errorf("unexpected raw request URI %q", got)
+	}
+}
+
+func TestProxyURL(t *testing.T) {
+	rs := &recordingServer{}
+	proxy := httptest.NewServer(rs)
+	defer proxy.Close()
+
+	c, err := NewWithOptions("token", Options{
+		BaseURL:  "http://discord.invalid/api/v9/",
+		ProxyURL: proxy.URL,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := c.ListGuilds(); err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	// Proxies receive the absolute URL of the target
+	if got := rs.last(); got != "http://discord.invalid/api/v9/users/@me/guilds?limit=100" {
+		t.Errorf("unexpected proxied request %q", got)
+	}
+}
+
+func TestCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(&recordingServer{})
+	defer srv.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	untrusted, err := NewWithOptions("token", Options{BaseURL: srv.URL + "/api/v9/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := untrusted.ListGuilds(); err == nil {
+		t.Fatal("expected a certificate error without the CA bundle")
+	}
+
+	trusted, err := NewWithOptions("token", Options{BaseURL: srv.URL + "/api/v9/", CABundle: bundle})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := trusted.ListGuilds(); err != nil {
+		t.Fatalf("request with CA bundle failed: %v", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	c, err := NewWithOptions("token", Options{BaseURL: srv.URL, Timeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.ListGuilds(); err == nil || !strings.Contains(err.Error(), "Timeout") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"relative base URL", Options{BaseURL: "/api/v9"}},
+		{"proxy without host", Options{ProxyURL: "not a url"}},
+		{"missing CA bundle", Options{CABundle: filepath.Join(t.TempDir(), "missing.pem")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWithOptions("token", tt.opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package discord
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ulfschnabel/dca/internal/snowflake"
+)
+
+// memberSearchLimit is the most members asked for per guild member search
+const memberSearchLimit = 25
+
+// Lookup match kinds, best first
+const (
+	MatchID         = "id"
+	MatchUsername   = "username"
+	MatchGlobalName = "global_name"
+	MatchNick       = "nick"
+	MatchPrefix     = "prefix"
+	MatchPartial    = "partial"
+)
+
+// matchScores is the confidence each match kind starts from
+var matchScores = map[string]float64{
+	MatchID:         1,
+	MatchUsername:   1,
+	MatchGlobalName: 0.9,
+	MatchNick:       0.85,
+	MatchPrefix:     0.6,
+	MatchPartial:    0.4,
+}
+
+// MutualGuild is a server the current user shares with another user, with
+// their nickname there
+type MutualGuild struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Nick string `json:"nick,omitempty"`
+}
+
+// UserCandidate is a possible match of a user lookup. Match is the best
+// way the query matched (see the Match constants), Sources where the user
+// was found: friend, friend_request, dm, guild_member or id. Confidence
+// ranges from 0 to 1. FriendNickname is the private nickname given to a
+// friend, which matches like a server nickname.
+type UserCandidate struct {
+	Author
+	Match          string         `json:"match"`
+	Confidence     float64        `json:"confidence"`
+	Sources        []string       `json:"sources"`
+	Relationship   string         `json:"relationship,omitempty"`
+	FriendNickname string         `json:"friend_nickname,omitempty"`
+	MutualGuilds   []*MutualGuild `json:"mutual_guilds"`
+}
+
+// UserInfo describes a user and what the current user shares with them.
+// DMChannelID is set when a DM with them already exists.
+type UserInfo struct {
+	Author
+	CreatedAt    string         `json:"created_at"`
+	Relationship string         `json:"relationship,omitempty"`
+	DMChannelID  string         `json:"dm_channel_id,omitempty"`
+	MutualGuilds []*MutualGuild `json:"mutual_guilds"`
+}
+
+// dmChannels lists the current user's DM and group DM channels
+func (c *Client) dmChannels() ([]*discordgo.Channel, error) {
+	var channels []*discordgo.Channel
+	body, err := c.session.RequestWithBucketID("GET", discordgo.EndpointUserChannels("@me"), nil, discordgo.EndpointUserChannels(""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DM channels: %w", err)
+	}
+	if err = discordgo.Unmarshal(body, &channels); err != nil {
+		return nil, fmt.Errorf("failed to parse DM channels: %w", err)
+	}
+	return channels, nil
+}
+
+// LookupUsers finds the users a name may refer to. It checks the friends
+// list, DM recipients and a member search of every server, and fetches
+// snowflakes directly. Discord has no read-only way to look up arbitrary
+// users by username, so users sharing nothing with the current user are
+// only found by ID. Blocked users are only found through DMs, servers or
+// their ID. Candidates are returned best match first; an exact username
+// match has confidence 1.
+func (c *Client) LookupUsers(query string) ([]*UserCandidate, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	if query == "" {
+		return nil, fmt.Errorf("empty user query")
+	}
+
+	found := make(map[string]*UserCandidate)
+	add := func(u *discordgo.User, source string) *UserCandidate {
+		if u == nil || u.ID == "" {
+			return nil
+		}
+		cand, ok := found[u.ID]
+		if !ok {
+			cand = &UserCandidate{Author: newAuthor(u), MutualGuilds: make([]*MutualGuild, 0)}
+			found[u.ID] = cand
+		}
+		for _, s := range cand.Sources {
+			if s == source {
+				return cand
+			}
+		}
+		cand.Sources = append(cand.Sources, source)
+		return cand
+	}
+
+	if _, err := snowflake.Parse(query); err == nil {
+		if u, err := c.session.User(query); err == nil {
+			add(u, "id").Match = MatchID
+		}
+	}
+
+	// Relationships need a user token; without one they are skipped
+	rels, _ := c.relationships()
+	for _, r := range rels {
+		switch r.Type {
+		case relationshipFriend:
+			add(r.User, "friend")
+		case relationshipPendingIncoming, relationshipPendingOutgoing:
+			add(r.User, "friend_request")
+		}
+	}
+
+	dms, err := c.dmChannels()
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range dms {
+		for _, u := range ch.Recipients {
+			add(u, "dm")
+		}
+	}
+
+	guilds, err := c.session.UserGuilds(100, "", "", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list guilds: %w", err)
+	}
+	for _, g := range guilds {
+		members, err := c.session.GuildMembersSearch(g.ID, query, memberSearchLimit)
+		if err != nil {
+			continue // Skip servers that refuse member search
+		}
+		for _, m := range members {
+			if cand := add(m.User, "guild_member"); cand != nil {
+				cand.MutualGuilds = append(cand.MutualGuilds, &MutualGuild{ID: g.ID, Name: g.Name, Nick: m.Nick})
+				c.nicks[g.ID+"/"+m.User.ID] = m.Nick
+			}
+		}
+	}
+
+	for _, r := range rels {
+		if cand := found[r.ID]; cand != nil {
+			cand.Relationship = relationshipName(r.Type)
+			cand.FriendNickname = r.Nickname
+		}
+	}
+
+	result := make([]*UserCandidate, 0, len(found))
+	for _, cand := range found {
+		if cand.Match == "" {
+			cand.Match = matchCandidate(cand, query)
+		}
+		if cand.Match == "" {
+			continue
+		}
+		// The member search only finds people by the query, so add the
+		// servers a match shares with us under another name
+		cand.MutualGuilds = c.mutualGuilds(guilds, cand.ID, cand.MutualGuilds)
+		cand.Confidence = candidateConfidence(cand)
+		result = append(result, cand)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Confidence != result[j].Confidence {
+			return result[i].Confidence > result[j].Confidence
+		}
+		return result[i].Username < result[j].Username
+	})
+	return result, nil
+}
+
+// matchCandidate returns how a candidate's names match the query, "" if
+// they do not
+func matchCandidate(cand *UserCandidate, query string) string {
+	names := []struct{ match, name string }{
+		{MatchUsername, cand.Username},
+		{MatchGlobalName, cand.GlobalName},
+		{MatchNick, cand.FriendNickname},
+	}
+	for _, g := range cand.MutualGuilds {
+		names = append(names, struct{ match, name string }{MatchNick, g.Nick})
+	}
+
+	q := strings.ToLower(query)
+	best := ""
+	for _, n := range names {
+		name := strings.ToLower(n.name)
+		match := ""
+		switch {
+		case name == "":
+		case name == q:
+			match = n.match
+		case strings.HasPrefix(name, q):
+			match = MatchPrefix
+		case strings.Contains(name, q):
+			match = MatchPartial
+		}
+		if match != "" && (best == "" || matchScores[match] > matchScores[best]) {
+			best = match
+		}
+	}
+	return best
+}
+
+// candidateConfidence scores a candidate by how well it matched, with a
+// little extra for friends and people the current user has a DM with
+func candidateConfidence(cand *UserCandidate) float64 {
+	score := matchScores[cand.Match]
+	for _, s := range cand.Sources {
+		if s == "dm" || (s == "friend" && cand.Relationship == RelationshipFriend) {
+			score += 0.05
+		}
+	}
+	return math.Round(math.Min(score, 1)*100) / 100
+}
+
+// GetUserInfo fetches a user with their relationship to the current user,
+// any existing DM channel and the servers both are in
+func (c *Client) GetUserInfo(userID string) (*UserInfo, error) {
+	u, err := c.session.User(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	info := &UserInfo{
+		Author:       newAuthor(u),
+		CreatedAt:    snowflake.Timestamp(u.ID),
+		MutualGuilds: make([]*MutualGuild, 0),
+	}
+
+	if rels, err := c.relationships(); err == nil {
+		for _, r := range rels {
+			if r.User != nil && r.User.ID == userID {
+				info.Relationship = relationshipName(r.Type)
+			}
+		}
+	}
+
+	dms, err := c.dmChannels()
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range dms {
+		if ch.Type == discordgo.ChannelTypeDM && len(ch.Recipients) == 1 && ch.Recipients[0].ID == userID {
+			info.DMChannelID = ch.ID
+			break
+		}
+	}
+
+	guilds, err := c.session.UserGuilds(100, "", "", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list guilds: %w", err)
+	}
+	info.MutualGuilds = c.mutualGuilds(guilds, userID, info.MutualGuilds)
+	return info, nil
+}
+
+// mutualGuilds returns the servers in guilds that userID is a member of,
+// looking up each one not already in known
+func (c *Client) mutualGuilds(guilds []*discordgo.UserGuild, userID string, known []*MutualGuild) []*MutualGuild {
+	byID := make(map[string]*MutualGuild, len(known))
+	for _, g := range known {
+		byID[g.ID] = g
+	}
+	result := make([]*MutualGuild, 0, len(known))
+	for _, g := range guilds {
+		if mg, ok := byID[g.ID]; ok {
+			result = append(result, mg)
+			continue
+		}
+		member, err := c.session.GuildMember(g.ID, userID)
+		if err != nil {
+			continue // Not a member, or the server hides its members
+		}
+		result = append(result, &MutualGuild{ID: g.ID, Name: g.Name, Nick: member.Nick})
+		c.nicks[g.ID+"/"+userID] = member.Nick
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package discord
+
+import "testing"
+
+func TestMatchCandidate(t *testing.T) {
+	cand := &UserCandidate{
+		Author:       Author{Username: "alice", GlobalName: "Alice Liddell"},
+		MutualGuilds: []*MutualGuild{{Nick: "ops-alice"}, {Nick: ""}},
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"alice", MatchUsername},
+		{"ALICE", MatchUsername},
+		{"alice liddell", MatchGlobalName},
+		{"ops-alice", MatchNick},
+		{"ali", MatchPrefix},
+		{"liddell", MatchPartial},
+		{"bob", ""},
+	}
+	for _, tt := range tests {
+		if got := matchCandidate(cand, tt.query); got != tt.want {
+			t.Errorf("matchCandidate(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCandidateConfidence(t *testing.T) {
+	tests := []struct {
+		cand *UserCandidate
+		want float64
+	}{
+		{&UserCandidate{Match: MatchUsername, Sources: []string{"friend", "dm"}, Relationship: "friend"}, 1},
+		{&UserCandidate{Match: MatchGlobalName, Sources: []string{"guild_member"}}, 0.9},
+		{&UserCandidate{Match: MatchPrefix, Sources: []string{"friend", "dm"}, Relationship: "friend"}, 0.7},
+		// Blocked users get no friend bonus
+		{&UserCandidate{Match: MatchPrefix, Sources: []string{"friend"}, Relationship: "blocked"}, 0.6},
+	}
+	for _, tt := range tests {
+		if got := candidateConfidence(tt.cand); got != tt.want {
+			t.Errorf("candidateConfidence(%+v) = %v, want %v", tt.cand, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Package ledger keeps a local record of messages dca has sent.
+//
+// The record lets a retried send with the same idempotency key return the
+// original message instead of posting again, and lets dca warn when
+// identical content went to the same place shortly before. Entries are
+// kept for Retention and stored as JSON next to the config file.
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Retention is how long entries are kept
+const Retention = 7 * 24 * time.Hour
+
+// Entry records one send. Target is the channel ID, or dm:<user-id> for a
+// direct message; a split message is one entry with several MessageIDs.
+// Partial marks a split message whose send failed after MessageIDs, so a
+// retry only has the remaining parts left to send.
+type Entry struct {
+	Key         string    `json:"key,omitempty"`
+	Target      string    `json:"target"`
+	ChannelID   string    `json:"channel_id"`
+	Fingerprint string    `json:"fingerprint"`
+	MessageIDs  []string  `json:"message_ids"`
+	Partial     bool      `json:"partial,omitempty"`
+	SentAt      time.Time `json:"sent_at"`
+}
+
+// Ledger is the set of recorded sends
+type Ledger struct {
+	path    string
+	entries []*Entry
+}
+
+// DefaultPath returns the ledger path that belongs to a config file
+func DefaultPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "sent.json")
+}
+
+// Open reads the ledger at path. A missing file is an empty ledger.
+func Open(path string) (*Ledger, error) {
+	l := &Ledger{path: path}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// load reads the entries from disk, replacing those in memory
+func (l *Ledger) load() error {
+	l.entries = nil
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, &l.entries); err != nil {
+		return fmt.Errorf("failed to parse ledger %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// Lookup returns the entry recorded with an idempotency key, or nil
+func (l *Ledger) Lookup(key string) *Entry {
+	for _, e := range l.entries {
+		if e.Key == key {
+			return e
+		}
+	}
+	return nil
+}
+
+// Recent returns the latest send of the same content to target no longer
+// than window before now, or nil
+func (l *Ledger) Recent(target, fingerprint string, window time.Duration, now time.Time) *Entry {
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		e := l.entries[i]
+		if now.Sub(e.SentAt) > window {
+			continue
+		}
+		if e.Target == target && e.Fingerprint == fingerprint {
+			return e
+		}
+	}
+	return nil
+}
+
+// Record adds an entry, replacing any with the same idempotency key, drops
+// entries older than Retention and saves the ledger. The ledger is re-read
+// under a lock first, so concurrent sends do not lose each other's entries.
+func (l *Ledger) Record(e *Entry) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create ledger directory: %w", err)
+	}
+	unlock, err := lockFile(l.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := l.load(); err != nil {
+		return err
+	}
+
+	kept := l.entries[:0]
+	for _, old := range l.entries {
+		if e.Key != "" && old.Key == e.Key {
+			continue
+		}
+		if e.SentAt.Sub(old.SentAt) <= Retention {
+			kept = append(kept, old)
+		}
+	}
+	l.entries = append(kept, e)
+
+	data, err := json.MarshalIndent(l.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ledger: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a torn ledger
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	return nil
+}
+
+// lockFile takes an exclusive lock on path, creating the file if needed,
+// and returns the function that releases it
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ledger lock: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock ledger: %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,104 @@
+package ledger
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dca", "sent.json")
+	now := time.Date(2026, 2, 24, 10, 0, 0, 0, time.UTC)
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("opening a missing ledger: %v", err)
+	}
+
+	old := &Entry{Key: "old", Target: "1", Fingerprint: "f", MessageIDs: []string{"10"}, SentAt: now.Add(-8 * 24 * time.Hour)}
+	sent := &Entry{Key: "k", Target: "1", ChannelID: "1", Fingerprint: "f", MessageIDs: []string{"11", "12"}, SentAt: now}
+	for _, e := range []*Entry{old, sent} {
+		if err := l.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Lookup("old") != nil {
+		t.Error("entries past retention should be dropped")
+	}
+	e := l.Lookup("k")
+	if e == nil || len(e.MessageIDs) != 2 || e.ChannelID != "1" {
+		t.Fatalf("expected the recorded entry, got %+v", e)
+	}
+
+	if l.Recent("1", "f", time.Minute, now.Add(30*time.Second)) == nil {
+		t.Error("expected a recent duplicate inside the window")
+	}
+	if l.Recent("1", "f", time.Minute, now.Add(2*time.Minute)) != nil {
+		t.Error("sends outside the window are not duplicates")
+	}
+	if l.Recent("2", "f", time.Minute, now) != nil || l.Recent("1", "g", time.Minute, now) != nil {
+		t.Error("other targets and content are not duplicates")
+	}
+
+	// Finishing a partial send replaces its entry
+	partial := &Entry{Key: "split", Target: "1", ChannelID: "1", Fingerprint: "g", MessageIDs: []string{"13"}, Partial: true, SentAt: now}
+	done := &Entry{Key: "split", Target: "1", ChannelID: "1", Fingerprint: "g", MessageIDs: []string{"13", "14"}, SentAt: now.Add(time.Second)}
+	for _, e := range []*Entry{partial, done} {
+		if err := l.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if e := l.Lookup("split"); e == nil || e.Partial || len(e.MessageIDs) != 2 {
+		t.Errorf("expected the completed send, got %+v", e)
+	}
+	if l.Lookup("k") == nil {
+		t.Error("entries with other keys should be kept")
+	}
+}
+
+func TestLedgerConcurrentRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sent.json")
+	now := time.Date(2026, 2, 24, 10, 0, 0, 0, time.UTC)
+
+	// Every ledger is opened before any record is written, as with
+	// separate dca processes sending at the same time
+	const n = 20
+	ledgers := make([]*Ledger, n)
+	for i := range ledgers {
+		l, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ledgers[i] = l
+	}
+
+	var wg sync.WaitGroup
+	for i, l := range ledgers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e := &Entry{Key: fmt.Sprintf("k%d", i), Target: "1", Fingerprint: "f", MessageIDs: []string{"1"}, SentAt: now}
+			if err := l.Record(e); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range n {
+		if l.Lookup(fmt.Sprintf("k%d", i)) == nil {
+			t.Errorf("record k%d was lost", i)
+		}
+	}
+}
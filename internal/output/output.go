@@ -10,6 +10,8 @@ type Response struct {
 	OK    bool        `json:"ok"`
 	Data  interface{} `json:"data,omitempty"`
 	Error string      `json:"error,omitempty"`
+	// Warnings are notes about a successful command worth a second look
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // Success creates a successful response
@@ -52,6 +54,13 @@ func PrintSuccess(data interface{}, pretty bool) error {
 	return Print(Success(data), pretty)
 }
 
+// PrintSuccessWithWarnings prints a successful response with warnings
+func PrintSuccessWithWarnings(data interface{}, warnings []string, pretty bool) error {
+	resp := Success(data)
+	resp.Warnings = warnings
+	return Print(resp, pretty)
+}
+
 // PrintError prints an error response
 func PrintError(err error, pretty bool) error {
 	return Print(Error(err), pretty)
@@ -0,0 +1,134 @@
+// Package snowflake converts between Discord snowflake IDs and time.
+//
+// A snowflake is a 64-bit integer: the top 42 bits are milliseconds since
+// the Discord epoch, followed by a 5-bit internal worker ID, a 5-bit
+// internal process ID and a 12-bit per-process increment.
+package snowflake
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Epoch is the Discord epoch, the first millisecond of 2015, in Unix milliseconds
+const Epoch = 1420070400000
+
+const (
+	timestampShift = 22
+	workerShift    = 17
+	processShift   = 12
+	workerMask     = 0x1F << workerShift
+	processMask    = 0x1F << processShift
+	incrementMask  = 0xFFF
+)
+
+// ID is a Discord snowflake
+type ID uint64
+
+// Parse parses a decimal snowflake
+func Parse(s string) (ID, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid snowflake %q", s)
+	}
+	return ID(n), nil
+}
+
+// FromTime returns the smallest snowflake Discord can assign at t. Times
+// before the Discord epoch map to zero.
+func FromTime(t time.Time) ID {
+	ms := t.UnixMilli() - Epoch
+	if ms < 0 {
+		return 0
+	}
+	return ID(uint64(ms) << timestampShift)
+}
+
+// MaxAt returns the largest snowflake Discord can assign in the
+// millisecond of t
+func MaxAt(t time.Time) ID {
+	return FromTime(t) | (1<<timestampShift - 1)
+}
+
+// String returns the decimal form used by the Discord API
+func (id ID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// Time returns when the ID was generated, in UTC
+func (id ID) Time() time.Time {
+	return time.UnixMilli(int64(id>>timestampShift) + Epoch).UTC()
+}
+
+// WorkerID returns the internal worker that generated the ID
+func (id ID) WorkerID() int {
+	return int(id&workerMask) >> workerShift
+}
+
+// ProcessID returns the internal process that generated the ID
+func (id ID) ProcessID() int {
+	return int(id&processMask) >> processShift
+}
+
+// Increment returns the per-process counter value of the ID
+func (id ID) Increment() int {
+	return int(id & incrementMask)
+}
+
+// Timestamp returns the creation time of a snowflake string as RFC3339,
+// or "" if s is not a snowflake
+func Timestamp(s string) string {
+	id, err := Parse(s)
+	if err != nil {
+		return ""
+	}
+	return id.Time().Format(time.RFC3339)
+}
+
+// Less orders snowflake strings numerically. Both must be decimal
+// snowflakes without leading zeros.
+func Less(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// Prev returns the snowflake just below s, for turning an inclusive lower
+// bound into an exclusive one. Zero and invalid input are returned as is.
+func Prev(s string) string {
+	id, err := Parse(s)
+	if err != nil || id == 0 {
+		return s
+	}
+	return (id - 1).String()
+}
+
+// Decoded holds the fields of a snowflake
+type Decoded struct {
+	ID         string `json:"id"`
+	Timestamp  string `json:"timestamp"`
+	UnixMillis int64  `json:"unix_ms"`
+	WorkerID   int    `json:"worker_id"`
+	ProcessID  int    `json:"process_id"`
+	Increment  int    `json:"increment"`
+}
+
+// Decode splits a snowflake string into its fields
+func Decode(s string) (*Decoded, error) {
+	id, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	t := id.Time()
+	return &Decoded{
+		ID:         id.String(),
+		Timestamp:  t.Format("2006-01-02T15:04:05.000Z07:00"),
+		UnixMillis: t.UnixMilli(),
+		WorkerID:   id.WorkerID(),
+		ProcessID:  id.ProcessID(),
+		Increment:  id.Increment(),
+	}, nil
+}
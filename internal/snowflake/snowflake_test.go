@@ -0,0 +1,72 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	// Example from the Discord developer documentation
+	d, err := Decode("175928847299117063")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.UnixMillis != 1462015105796 {
+		t.Errorf("expected unix_ms=1462015105796, got %d", d.UnixMillis)
+	}
+	if d.Timestamp != "2016-04-30T11:18:25.796Z" {
+		t.Errorf("unexpected timestamp %s", d.Timestamp)
+	}
+	if d.WorkerID != 1 || d.ProcessID != 0 || d.Increment != 7 {
+		t.Errorf("expected worker=1 process=0 increment=7, got %d %d %d", d.WorkerID, d.ProcessID, d.Increment)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, s := range []string{"", "abc", "-1", "18446744073709551616"} {
+		if _, err := Decode(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestFromTimeRoundTrip(t *testing.T) {
+	at := time.Date(2026, 2, 24, 10, 0, 0, 123000000, time.UTC)
+
+	min := FromTime(at)
+	max := MaxAt(at)
+	if !min.Time().Equal(at) || !max.Time().Equal(at) {
+		t.Errorf("expected both bounds at %s, got %s and %s", at, min.Time(), max.Time())
+	}
+	if max.Increment() != 4095 || max.WorkerID() != 31 || max.ProcessID() != 31 {
+		t.Errorf("expected all low bits set in %d", max)
+	}
+	if FromTime(at.Add(time.Millisecond)) != max+1 {
+		t.Error("expected the next millisecond to start right after MaxAt")
+	}
+}
+
+func TestFromTimeBeforeEpoch(t *testing.T) {
+	if id := FromTime(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)); id != 0 {
+		t.Errorf("expected 0 before the epoch, got %d", id)
+	}
+}
+
+func TestLessAndPrev(t *testing.T) {
+	if !Less("99", "100") || Less("100", "99") || Less("5", "5") {
+		t.Error("Less must compare numerically")
+	}
+	if Prev("100") != "99" || Prev("0") != "0" || Prev("x") != "x" {
+		t.Error("unexpected Prev result")
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	if got := Timestamp("175928847299117063"); got != "2016-04-30T11:18:25Z" {
+		t.Errorf("unexpected timestamp %s", got)
+	}
+	if got := Timestamp("nope"); got != "" {
+		t.Errorf("expected empty timestamp for invalid input, got %s", got)
+	}
+}